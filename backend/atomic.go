@@ -0,0 +1,70 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// writeTempData writes data to the temporary file; tests replace it to simulate failed writes
+var writeTempData = func(file *os.File, data []byte) error {
+	_, err := file.Write(data)
+	return err
+}
+
+// WriteFileAtomic replaces filename with data without ever leaving a partially written file.
+// The data is written to a temporary file in the same directory, synced to disk and renamed
+// over the target. Existing file permissions are preserved; new files get defaultPerm.
+func WriteFileAtomic(filename string, data []byte, defaultPerm os.FileMode) error {
+	// Write through symlinks to the real file
+	target := filename
+	if resolved, err := filepath.EvalSymlinks(filename); err == nil {
+		target = resolved
+	}
+
+	perm := defaultPerm
+	if info, err := os.Stat(target); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(target)
+	tempFile, err := os.CreateTemp(dir, "."+filepath.Base(target)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error creating temporary file: %w", err)
+	}
+	tempName := tempFile.Name()
+
+	// Remove the temporary file unless it was renamed into place
+	committed := false
+	defer func() {
+		if !committed {
+			tempFile.Close()
+			os.Remove(tempName)
+		}
+	}()
+
+	if err := writeTempData(tempFile, data); err != nil {
+		return fmt.Errorf("error writing temporary file: %w", err)
+	}
+	if err := tempFile.Sync(); err != nil {
+		return fmt.Errorf("error syncing temporary file: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("error closing temporary file: %w", err)
+	}
+	if err := os.Chmod(tempName, perm); err != nil {
+		return fmt.Errorf("error setting file permissions: %w", err)
+	}
+	if err := os.Rename(tempName, target); err != nil {
+		return fmt.Errorf("error replacing file: %w", err)
+	}
+	committed = true
+
+	// Persist the rename itself; not every platform supports syncing directories
+	if dirHandle, err := os.Open(dir); err == nil {
+		dirHandle.Sync()
+		dirHandle.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package backend
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteFileAtomic_PartialWriteKeepsOriginal(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "todos.json")
+	storage := NewJSONFile(testFile)
+
+	if err := storage.Save([]byte(`[{"task":"Original task"}]`)); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Simulate a crash or full disk halfway through writing
+	oldWrite := writeTempData
+	defer func() { writeTempData = oldWrite }()
+	writeTempData = func(file *os.File, data []byte) error {
+		file.Write(data[:len(data)/2])
+		return errors.New("no space left on device")
+	}
+
+	if err := storage.Save([]byte(`[{"task":"Replacement task"}]`)); err == nil {
+		t.Fatalf("Save() with failing write should return error")
+	}
+
+	loaded, err := storage.Load()
+	if err != nil {
+		t.Fatalf("Load() after failed Save() error = %v", err)
+	}
+	if string(loaded) != `[{"task":"Original task"}]` {
+		t.Errorf("Load() after failed Save() = %s, want original list", loaded)
+	}
+
+	entries, _ := os.ReadDir(tempDir)
+	if len(entries) != 1 {
+		t.Errorf("Save() left %d files in directory, want 1", len(entries))
+	}
+}
+
+func TestWriteFileAtomic_Permissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not preserved on Windows")
+	}
+
+	tempDir := t.TempDir()
+
+	// New files get the default permissions
+	newFile := filepath.Join(tempDir, "new.json")
+	if err := WriteFileAtomic(newFile, []byte("[]"), 0644); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+	if info, _ := os.Stat(newFile); info.Mode().Perm() != 0644 {
+		t.Errorf("WriteFileAtomic() new file permissions = %v, want 0644", info.Mode().Perm())
+	}
+
+	// Existing files keep theirs
+	existingFile := filepath.Join(tempDir, "existing.json")
+	os.WriteFile(existingFile, []byte("[]"), 0600)
+	if err := WriteFileAtomic(existingFile, []byte("[{}]"), 0644); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+	if info, _ := os.Stat(existingFile); info.Mode().Perm() != 0600 {
+		t.Errorf("WriteFileAtomic() existing file permissions = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestWriteFileAtomic_FollowsSymlinks(t *testing.T) {
+	tempDir := t.TempDir()
+	realFile := filepath.Join(tempDir, "real.json")
+	linkFile := filepath.Join(tempDir, "link.json")
+
+	os.WriteFile(realFile, []byte("[]"), 0644)
+	if err := os.Symlink(realFile, linkFile); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := WriteFileAtomic(linkFile, []byte(`[{"task":"Linked"}]`), 0644); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+
+	if info, err := os.Lstat(linkFile); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("WriteFileAtomic() replaced the symlink instead of its target")
+	}
+	if content, _ := os.ReadFile(realFile); string(content) != `[{"task":"Linked"}]` {
+		t.Errorf("WriteFileAtomic() target content = %s", content)
+	}
+}
@@ -0,0 +1,93 @@
+// Package backend stores todo documents. A document is the JSON encoding of one
+// todo list or archive ({"version":3,"items":[...]}); backends decide how it is kept.
+package backend
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Backend kinds, selected with the backend setting or --backend
+const (
+	JSON  = "json"  // one JSON file, replaced atomically on every save (default)
+	JSONL = "jsonl" // append-only JSON Lines event log next to the todo file
+	DB    = "db"    // embedded database shared by the todo file and its archive
+)
+
+// Kinds lists the available backends. The memory backend is only built by tests,
+// with NewMemory.
+var Kinds = []string{JSON, JSONL, DB}
+
+// Backend loads and saves one todo document
+type Backend interface {
+	// Load returns the stored document, or nil if nothing has been stored yet
+	Load() ([]byte, error)
+	// Save replaces the stored document; an empty document clears it
+	Save(data []byte) error
+}
+
+// Updater is implemented by backends that can change a document from the state they
+// just read, rather than from a separate Load
+type Updater interface {
+	Update(fn func(current []byte) ([]byte, error)) error
+}
+
+// Update applies fn to the stored document, through the backend's own Update when
+// it has one and with Load and Save otherwise. Nothing is saved if fn fails.
+func Update(b Backend, fn func(current []byte) ([]byte, error)) error {
+	if updater, ok := b.(Updater); ok {
+		return updater.Update(fn)
+	}
+
+	current, err := b.Load()
+	if err != nil {
+		return err
+	}
+	updated, err := fn(current)
+	if err != nil {
+		return err
+	}
+	return b.Save(updated)
+}
+
+// Open returns the backend of the given kind for the todo file at path
+func Open(kind, path string) (Backend, error) {
+	switch kind {
+	case JSON, "":
+		return NewJSONFile(path), nil
+	case JSONL:
+		return newSeededEventLog(FilePath(JSONL, path), path), nil
+	case DB:
+		return NewDatabase(FilePath(DB, path), databaseCollection(path)), nil
+	default:
+		return nil, fmt.Errorf("unknown backend: %s. Available backends: %s", kind, strings.Join(Kinds, ", "))
+	}
+}
+
+// FilePath returns the file a backend keeps the todo file at path in (todos.json ->
+// todos.jsonl for the event log, todos.json and todos.archive.json -> todos.db for
+// the database).
+func FilePath(kind, path string) string {
+	switch kind {
+	case JSONL:
+		return strings.TrimSuffix(path, ".json") + ".jsonl"
+	case DB:
+		base := strings.TrimSuffix(path, ".archive.json")
+		return strings.TrimSuffix(base, ".json") + ".db"
+	default:
+		return path
+	}
+}
+
+// Exists reports whether anything has been stored for path with the given backend.
+// An event log that hasn't been written yet exists when its JSON file does.
+func Exists(kind, path string) bool {
+	switch kind {
+	case JSONL:
+		return fileExists(FilePath(kind, path)) || fileExists(path)
+	case DB:
+		return databaseExists(FilePath(kind, path), databaseCollection(path))
+	default:
+		return fileExists(path)
+	}
+}
@@ -0,0 +1,113 @@
+package backend
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// openAll opens every kind of backend for a fresh todo file
+func openAll(t *testing.T) map[string]Backend {
+	t.Helper()
+	backends := make(map[string]Backend)
+	for _, kind := range Kinds {
+		store, err := Open(kind, filepath.Join(t.TempDir(), "todos.json"))
+		if err != nil {
+			t.Fatalf("Open(%s) error = %v", kind, err)
+		}
+		backends[kind] = store
+	}
+	backends["memory"] = NewMemory(filepath.Join(t.TempDir(), "todos.json"))
+	return backends
+}
+
+func TestBackends_SaveLoad(t *testing.T) {
+	documents := []string{
+		`{"version":3,"items":[]}`,
+		`{"version":3,"items":[{"internal_id":"a1","task":"One"},{"internal_id":"b2","task":"Two"}]}`,
+		`{"version":3,"items":[{"internal_id":"b2","task":"Two, renamed"},{"internal_id":"c3","task":"Three"}]}`,
+		`{"version":3,"items":[{"internal_id":"c3","task":"Three"},{"internal_id":"b2","task":"Two, renamed"}]}`,
+		`[{"task":"Legacy"},{"task":"Legacy"}]`,
+	}
+
+	for kind, store := range openAll(t) {
+		t.Run(kind, func(t *testing.T) {
+			if loaded, err := store.Load(); err != nil || loaded != nil {
+				t.Fatalf("Load() before Save() = %s, %v; want nil", loaded, err)
+			}
+
+			for _, document := range documents {
+				if err := store.Save([]byte(document)); err != nil {
+					t.Fatalf("Save(%s) error = %v", document, err)
+				}
+				loaded, err := store.Load()
+				if err != nil {
+					t.Fatalf("Load() error = %v", err)
+				}
+				if string(loaded) != document {
+					t.Errorf("Load() = %s, want %s", loaded, document)
+				}
+			}
+		})
+	}
+}
+
+func TestBackends_Update(t *testing.T) {
+	for kind, store := range openAll(t) {
+		t.Run(kind, func(t *testing.T) {
+			original := `{"version":3,"items":[]}`
+			if err := store.Save([]byte(original)); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			// A failing update leaves the document alone
+			err := Update(store, func(current []byte) ([]byte, error) {
+				return nil, errors.New("changed elsewhere")
+			})
+			if err == nil {
+				t.Errorf("Update() with failing fn should return its error")
+			}
+
+			updated := `{"version":3,"items":[{"internal_id":"a1","task":"One"}]}`
+			err = Update(store, func(current []byte) ([]byte, error) {
+				if string(current) != original {
+					t.Errorf("Update() current = %s, want %s", current, original)
+				}
+				return []byte(updated), nil
+			})
+			if err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+
+			if loaded, _ := store.Load(); string(loaded) != updated {
+				t.Errorf("Load() after Update() = %s, want %s", loaded, updated)
+			}
+		})
+	}
+}
+
+func TestOpen_UnknownBackend(t *testing.T) {
+	// The memory backend saves nothing, so it can't be selected either
+	for _, kind := range []string{"sqlite", "memory"} {
+		if _, err := Open(kind, "todos.json"); err == nil {
+			t.Errorf("Open(%s) should return an error", kind)
+		}
+	}
+}
+
+func TestExists(t *testing.T) {
+	for _, kind := range Kinds {
+		t.Run(kind, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "todos.json")
+			store, _ := Open(kind, path)
+
+			if Exists(kind, path) {
+				t.Errorf("Exists() before Save() = true")
+			}
+			store.Save([]byte(`{"version":3,"items":[]}`))
+			if !Exists(kind, path) {
+				t.Errorf("Exists() after Save() = false")
+			}
+		})
+	}
+}
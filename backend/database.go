@@ -0,0 +1,548 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Buckets inside each collection of the database
+var (
+	bucketMeta      = []byte("meta")          // version
+	bucketItems     = []byte("items")         // row sequence -> item JSON, in document order
+	bucketKeys      = []byte("keys")          // internal ID -> row sequence
+	bucketCompleted = []byte("idx_completed") // "0"/"1" + row sequence
+	bucketTags      = []byte("idx_tags")      // tag + 0x00 + row sequence
+	bucketDue       = []byte("idx_due")       // due date (UTC RFC3339) + 0x00 + row sequence
+
+	metaVersion = []byte("version")
+)
+
+// databaseOpenTimeout is how long to wait for another process holding the database
+const databaseOpenTimeout = 5 * time.Second
+
+// Database keeps a todo document as rows in an embedded bbolt database. A todo file
+// and its archive share one database file (todos.json and todos.archive.json ->
+// todos.db), each in its own collection with indexes on completion, tags and due dates.
+type Database struct {
+	filename   string
+	collection []byte
+}
+
+// NewDatabase creates a backend for one collection of the database at filename
+func NewDatabase(filename, collection string) *Database {
+	return &Database{filename: filename, collection: []byte(collection)}
+}
+
+// databaseCollection returns the collection the todo file at path is kept in
+func databaseCollection(path string) string {
+	if strings.HasSuffix(path, ".archive.json") {
+		return "archive"
+	}
+	return "todos"
+}
+
+// Query selects rows by the indexed fields. Empty fields don't restrict the result,
+// and date bounds are inclusive, so callers still apply their exact filters.
+type Query struct {
+	IDs       []string  // internal IDs
+	Completed *bool     // completion status
+	Tags      []string  // tags every row must have
+	DueBefore time.Time // due on or before
+	DueAfter  time.Time // due on or after
+}
+
+// Row is one item of a document and its 1-based position in it
+type Row struct {
+	Position int
+	Item     json.RawMessage
+}
+
+// RowStore is implemented by backends that keep items as separate rows, so commands
+// can add, remove and find a few items without loading the whole document
+type RowStore interface {
+	Backend
+	// Append adds the items of a document at the end
+	Append(data []byte) error
+	// Remove deletes the items with the given internal IDs
+	Remove(ids []string) error
+	// Query returns the matching items in document order
+	Query(q Query) ([]Row, error)
+}
+
+// open opens the database file, waiting for other processes that hold it
+func (d *Database) open(readOnly bool) (*bolt.DB, error) {
+	db, err := bolt.Open(d.filename, 0644, &bolt.Options{Timeout: databaseOpenTimeout, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s: %w", d.filename, err)
+	}
+	return db, nil
+}
+
+// view runs fn on the collection in a read-only transaction. A database or
+// collection that doesn't exist yet is passed as nil.
+func (d *Database) view(fn func(collection *bolt.Bucket) error) error {
+	if !fileExists(d.filename) {
+		return fn(nil)
+	}
+
+	db, err := d.open(true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.View(func(tx *bolt.Tx) error {
+		return fn(tx.Bucket(d.collection))
+	})
+}
+
+// update runs fn in a read-write transaction, creating the database file if needed
+func (d *Database) update(fn func(tx *bolt.Tx) error) error {
+	db, err := d.open(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.Update(fn)
+}
+
+// Load returns the collection as a document, or nil if it doesn't exist
+func (d *Database) Load() ([]byte, error) {
+	var data []byte
+	err := d.view(func(collection *bolt.Bucket) error {
+		if collection == nil {
+			return nil
+		}
+		doc, err := readCollection(collection)
+		if err != nil {
+			return err
+		}
+		data, err = doc.encode()
+		return err
+	})
+	return data, err
+}
+
+// Save stores a document, writing only the rows that changed. An empty document
+// removes the collection.
+func (d *Database) Save(data []byte) error {
+	return d.update(func(tx *bolt.Tx) error {
+		return d.write(tx, data)
+	})
+}
+
+// Update applies fn to the collection within one transaction
+func (d *Database) Update(fn func(current []byte) ([]byte, error)) error {
+	return d.update(func(tx *bolt.Tx) error {
+		var current []byte
+		if collection := tx.Bucket(d.collection); collection != nil {
+			doc, err := readCollection(collection)
+			if err != nil {
+				return err
+			}
+			if current, err = doc.encode(); err != nil {
+				return err
+			}
+		}
+
+		updated, err := fn(current)
+		if err != nil {
+			return err
+		}
+		return d.write(tx, updated)
+	})
+}
+
+// Append adds the items of a document at the end of the collection. A new
+// collection takes the document's version.
+func (d *Database) Append(data []byte) error {
+	doc, err := parseDocument(data)
+	if err != nil {
+		return err
+	}
+
+	return d.update(func(tx *bolt.Tx) error {
+		created := tx.Bucket(d.collection) == nil
+		collection, err := createCollection(tx, d.collection)
+		if err != nil {
+			return err
+		}
+		if created {
+			if err := collection.Bucket(bucketMeta).Put(metaVersion, []byte(strconv.Itoa(doc.version))); err != nil {
+				return err
+			}
+		}
+
+		keys := collection.Bucket(bucketKeys)
+		for _, key := range doc.order {
+			if keys.Get([]byte(key)) != nil {
+				return fmt.Errorf("an item with internal ID %s already exists", key)
+			}
+			if err := insertRow(collection, key, doc.items[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Remove deletes the items with the given internal IDs; unknown IDs are ignored
+func (d *Database) Remove(ids []string) error {
+	return d.update(func(tx *bolt.Tx) error {
+		collection := tx.Bucket(d.collection)
+		if collection == nil {
+			return nil
+		}
+
+		for _, id := range ids {
+			seq := collection.Bucket(bucketKeys).Get([]byte(id))
+			if seq == nil {
+				continue
+			}
+			if err := deleteRow(collection, id, slices.Clone(seq)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Query returns the rows matching q, using the indexes to avoid reading other rows
+func (d *Database) Query(q Query) ([]Row, error) {
+	var rows []Row
+	err := d.view(func(collection *bolt.Bucket) error {
+		if collection == nil {
+			return nil
+		}
+
+		matches, all := queryIndexes(collection, q)
+		items := collection.Bucket(bucketItems)
+
+		// Positions come from a scan over the row keys; only matching items are copied
+		position := 0
+		cursor := items.Cursor()
+		for seq, item := cursor.First(); seq != nil; seq, item = cursor.Next() {
+			position++
+			if all || matches[string(seq)] {
+				rows = append(rows, Row{Position: position, Item: slices.Clone(item)})
+			}
+		}
+		return nil
+	})
+	return rows, err
+}
+
+// queryIndexes returns the row sequences that satisfy every condition of q,
+// or all=true when q has no conditions
+func queryIndexes(collection *bolt.Bucket, q Query) (matches map[string]bool, all bool) {
+	var sets []map[string]bool
+
+	if len(q.IDs) > 0 {
+		set := make(map[string]bool)
+		for _, id := range q.IDs {
+			if seq := collection.Bucket(bucketKeys).Get([]byte(id)); seq != nil {
+				set[string(seq)] = true
+			}
+		}
+		sets = append(sets, set)
+	}
+	if q.Completed != nil {
+		sets = append(sets, scanIndex(collection.Bucket(bucketCompleted), completedKey(*q.Completed, nil)))
+	}
+	for _, tag := range q.Tags {
+		sets = append(sets, scanIndex(collection.Bucket(bucketTags), append([]byte(tag), 0)))
+	}
+	if !q.DueBefore.IsZero() || !q.DueAfter.IsZero() {
+		sets = append(sets, scanDueIndex(collection.Bucket(bucketDue), q.DueAfter, q.DueBefore))
+	}
+
+	if len(sets) == 0 {
+		return nil, true
+	}
+
+	matches = sets[0]
+	for _, set := range sets[1:] {
+		for seq := range matches {
+			if !set[seq] {
+				delete(matches, seq)
+			}
+		}
+	}
+	return matches, false
+}
+
+// scanIndex collects the row sequences of the index keys that start with prefix
+func scanIndex(index *bolt.Bucket, prefix []byte) map[string]bool {
+	set := make(map[string]bool)
+	cursor := index.Cursor()
+	for key, _ := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Next() {
+		set[string(key[len(key)-8:])] = true
+	}
+	return set
+}
+
+// scanDueIndex collects the row sequences due between after and before, inclusive
+func scanDueIndex(index *bolt.Bucket, after, before time.Time) map[string]bool {
+	set := make(map[string]bool)
+	cursor := index.Cursor()
+
+	var key []byte
+	if after.IsZero() {
+		key, _ = cursor.First()
+	} else {
+		key, _ = cursor.Seek([]byte(after.UTC().Format(time.RFC3339)))
+	}
+
+	last := ""
+	if !before.IsZero() {
+		last = before.UTC().Format(time.RFC3339)
+	}
+	for ; key != nil; key, _ = cursor.Next() {
+		due := string(key[:len(key)-9])
+		if last != "" && due > last {
+			break
+		}
+		set[string(key[len(key)-8:])] = true
+	}
+	return set
+}
+
+// readCollection reads every row of a collection into a document
+func readCollection(collection *bolt.Bucket) (itemDocument, error) {
+	doc := newItemDocument()
+
+	if version := collection.Bucket(bucketMeta).Get(metaVersion); version != nil {
+		parsed, err := strconv.Atoi(string(version))
+		if err != nil {
+			return doc, fmt.Errorf("invalid version %q", version)
+		}
+		doc.version = parsed
+	}
+
+	keysBySeq := make(map[string]string)
+	collection.Bucket(bucketKeys).ForEach(func(key, seq []byte) error {
+		keysBySeq[string(seq)] = string(key)
+		return nil
+	})
+
+	err := collection.Bucket(bucketItems).ForEach(func(seq, item []byte) error {
+		key, ok := keysBySeq[string(seq)]
+		if !ok {
+			return fmt.Errorf("row %d has no key", binary.BigEndian.Uint64(seq))
+		}
+		doc.items[key] = slices.Clone(item)
+		doc.order = append(doc.order, key)
+		return nil
+	})
+	return doc, err
+}
+
+// write stores data in the collection, touching only the rows that changed
+func (d *Database) write(tx *bolt.Tx, data []byte) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		if tx.Bucket(d.collection) == nil {
+			return nil
+		}
+		return tx.DeleteBucket(d.collection)
+	}
+
+	next, err := parseDocument(data)
+	if err != nil {
+		return err
+	}
+
+	collection, err := createCollection(tx, d.collection)
+	if err != nil {
+		return err
+	}
+	current, err := readCollection(collection)
+	if err != nil {
+		return err
+	}
+
+	if err := collection.Bucket(bucketMeta).Put(metaVersion, []byte(strconv.Itoa(next.version))); err != nil {
+		return err
+	}
+
+	// Rows only keep their place when kept items stay in order and new ones go at
+	// the end; anything else is stored again from scratch
+	var order []string
+	for _, key := range current.order {
+		if _, ok := next.items[key]; ok {
+			order = append(order, key)
+		}
+	}
+	for _, key := range next.order {
+		if _, ok := current.items[key]; !ok {
+			order = append(order, key)
+		}
+	}
+	if !slices.Equal(order, next.order) {
+		if err := tx.DeleteBucket(d.collection); err != nil {
+			return err
+		}
+		if collection, err = createCollection(tx, d.collection); err != nil {
+			return err
+		}
+		if err := collection.Bucket(bucketMeta).Put(metaVersion, []byte(strconv.Itoa(next.version))); err != nil {
+			return err
+		}
+		current = newItemDocument()
+	}
+
+	keys := collection.Bucket(bucketKeys)
+	for _, key := range current.order {
+		if _, ok := next.items[key]; !ok {
+			if err := deleteRow(collection, key, slices.Clone(keys.Get([]byte(key)))); err != nil {
+				return err
+			}
+		}
+	}
+	for _, key := range next.order {
+		previous, ok := current.items[key]
+		switch {
+		case !ok:
+			err = insertRow(collection, key, next.items[key])
+		case !bytes.Equal(previous, next.items[key]):
+			err = replaceRow(collection, slices.Clone(keys.Get([]byte(key))), previous, next.items[key])
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createCollection returns the collection bucket and its sub-buckets, creating them as needed
+func createCollection(tx *bolt.Tx, name []byte) (*bolt.Bucket, error) {
+	collection, err := tx.CreateBucketIfNotExists(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, bucket := range [][]byte{bucketMeta, bucketItems, bucketKeys, bucketCompleted, bucketTags, bucketDue} {
+		if _, err := collection.CreateBucketIfNotExists(bucket); err != nil {
+			return nil, err
+		}
+	}
+	return collection, nil
+}
+
+// insertRow adds an item after the last row
+func insertRow(collection *bolt.Bucket, key string, item []byte) error {
+	items := collection.Bucket(bucketItems)
+	next, err := items.NextSequence()
+	if err != nil {
+		return err
+	}
+	seq := make([]byte, 8)
+	binary.BigEndian.PutUint64(seq, next)
+
+	if err := items.Put(seq, item); err != nil {
+		return err
+	}
+	if err := collection.Bucket(bucketKeys).Put([]byte(key), seq); err != nil {
+		return err
+	}
+	return updateIndexes(collection, seq, nil, item)
+}
+
+// replaceRow changes the item stored in a row
+func replaceRow(collection *bolt.Bucket, seq, previous, item []byte) error {
+	if err := collection.Bucket(bucketItems).Put(seq, item); err != nil {
+		return err
+	}
+	return updateIndexes(collection, seq, previous, item)
+}
+
+// deleteRow removes a row and its index entries
+func deleteRow(collection *bolt.Bucket, key string, seq []byte) error {
+	items := collection.Bucket(bucketItems)
+	previous := slices.Clone(items.Get(seq))
+	if err := items.Delete(seq); err != nil {
+		return err
+	}
+	if err := collection.Bucket(bucketKeys).Delete([]byte(key)); err != nil {
+		return err
+	}
+	return updateIndexes(collection, seq, previous, nil)
+}
+
+// indexedFields are the item fields the indexes are built from
+type indexedFields struct {
+	Completed bool     `json:"completed"`
+	Tags      []string `json:"tags"`
+	DueAt     string   `json:"due_at"`
+}
+
+// indexEntry is one key in one index bucket
+type indexEntry struct {
+	bucket []byte
+	key    string
+}
+
+// indexEntries returns the index keys for an item stored in row seq
+func indexEntries(seq, item []byte) []indexEntry {
+	if item == nil {
+		return nil
+	}
+
+	var fields indexedFields
+	_ = json.Unmarshal(item, &fields)
+
+	entries := []indexEntry{{bucketCompleted, string(completedKey(fields.Completed, seq))}}
+	for _, tag := range fields.Tags {
+		entries = append(entries, indexEntry{bucketTags, tag + "\x00" + string(seq)})
+	}
+	if dueAt, err := time.Parse(time.RFC3339, fields.DueAt); err == nil {
+		entries = append(entries, indexEntry{bucketDue, dueAt.UTC().Format(time.RFC3339) + "\x00" + string(seq)})
+	}
+	return entries
+}
+
+// updateIndexes replaces the index entries of a row's previous item with those of the new one
+func updateIndexes(collection *bolt.Bucket, seq, previous, item []byte) error {
+	for _, entry := range indexEntries(seq, previous) {
+		if err := collection.Bucket(entry.bucket).Delete([]byte(entry.key)); err != nil {
+			return err
+		}
+	}
+	for _, entry := range indexEntries(seq, item) {
+		if err := collection.Bucket(entry.bucket).Put([]byte(entry.key), []byte{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// completedKey returns the completion index key for a row, or its prefix when seq is nil
+func completedKey(completed bool, seq []byte) []byte {
+	key := []byte("0")
+	if completed {
+		key = []byte("1")
+	}
+	return append(key, seq...)
+}
+
+// databaseExists reports whether the collection exists in the database file
+func databaseExists(filename, collection string) bool {
+	if !fileExists(filename) {
+		return false
+	}
+
+	exists := false
+	NewDatabase(filename, collection).view(func(bucket *bolt.Bucket) error {
+		exists = bucket != nil
+		return nil
+	})
+	return exists
+}
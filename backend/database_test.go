@@ -0,0 +1,131 @@
+package backend
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// openTestDatabase returns the todo and archive collections of a new database
+func openTestDatabase(t *testing.T) (*Database, *Database) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "todos.json")
+	todos, _ := Open(DB, path)
+	archive, _ := Open(DB, filepath.Join(filepath.Dir(path), "todos.archive.json"))
+	return todos.(*Database), archive.(*Database)
+}
+
+// rowPositions returns the positions of the rows
+func rowPositions(rows []Row) []int {
+	var positions []int
+	for _, row := range rows {
+		positions = append(positions, row.Position)
+	}
+	return positions
+}
+
+func TestDatabase_SharedFile(t *testing.T) {
+	todos, archive := openTestDatabase(t)
+	if todos.filename != archive.filename {
+		t.Errorf("todo file and archive use %s and %s, want one database", todos.filename, archive.filename)
+	}
+
+	todos.Save([]byte(`{"version":3,"items":[{"internal_id":"a1","task":"Open"}]}`))
+	archive.Save([]byte(`{"version":3,"items":[{"internal_id":"b2","task":"Done"}]}`))
+
+	if loaded, _ := todos.Load(); string(loaded) != `{"version":3,"items":[{"internal_id":"a1","task":"Open"}]}` {
+		t.Errorf("todos Load() = %s", loaded)
+	}
+	if loaded, _ := archive.Load(); string(loaded) != `{"version":3,"items":[{"internal_id":"b2","task":"Done"}]}` {
+		t.Errorf("archive Load() = %s", loaded)
+	}
+}
+
+func TestDatabase_Query(t *testing.T) {
+	todos, _ := openTestDatabase(t)
+	todos.Save([]byte(`{"version":3,"items":[
+		{"internal_id":"a1","task":"One","completed":true,"tags":["docs"]},
+		{"internal_id":"b2","task":"Two","completed":false,"tags":["docs","release"],"due_at":"2025-09-01T00:00:00Z"},
+		{"internal_id":"c3","task":"Three","completed":false,"due_at":"2025-09-10T00:00:00+02:00"},
+		{"internal_id":"d4","task":"Four","completed":true,"tags":["release"]}
+	]}`))
+
+	incomplete, completed := false, true
+	tests := []struct {
+		name  string
+		query Query
+		want  []int
+	}{
+		{"all", Query{}, []int{1, 2, 3, 4}},
+		{"completed", Query{Completed: &completed}, []int{1, 4}},
+		{"incomplete", Query{Completed: &incomplete}, []int{2, 3}},
+		{"tag", Query{Tags: []string{"docs"}}, []int{1, 2}},
+		{"tags", Query{Tags: []string{"docs", "release"}}, []int{2}},
+		{"unknown tag", Query{Tags: []string{"doc"}}, nil},
+		{"due before", Query{DueBefore: time.Date(2025, 9, 5, 0, 0, 0, 0, time.UTC)}, []int{2}},
+		{"due after", Query{DueAfter: time.Date(2025, 9, 5, 0, 0, 0, 0, time.UTC)}, []int{3}},
+		{"ids", Query{IDs: []string{"d4", "a1", "zz"}}, []int{1, 4}},
+		{"combined", Query{Completed: &incomplete, Tags: []string{"release"}}, []int{2}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, err := todos.Query(test.query)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if got := rowPositions(rows); !slices.Equal(got, test.want) {
+				t.Errorf("Query() positions = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDatabase_IndexesFollowChanges(t *testing.T) {
+	todos, _ := openTestDatabase(t)
+	todos.Save([]byte(`{"version":3,"items":[{"internal_id":"a1","tags":["docs"]},{"internal_id":"b2"}]}`))
+	todos.Save([]byte(`{"version":3,"items":[{"internal_id":"b2","completed":true,"tags":["docs"]}]}`))
+
+	completed := true
+	rows, _ := todos.Query(Query{Completed: &completed, Tags: []string{"docs"}})
+	if len(rows) != 1 || rows[0].Position != 1 {
+		t.Fatalf("Query() after changes = %+v, want b2 at position 1", rows)
+	}
+
+	var item struct {
+		InternalID string `json:"internal_id"`
+	}
+	json.Unmarshal(rows[0].Item, &item)
+	if item.InternalID != "b2" {
+		t.Errorf("Query() returned %s, want b2", item.InternalID)
+	}
+}
+
+func TestDatabase_AppendRemove(t *testing.T) {
+	_, archive := openTestDatabase(t)
+
+	if err := archive.Append([]byte(`{"version":3,"items":[{"internal_id":"a1"},{"internal_id":"b2"}]}`)); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := archive.Append([]byte(`{"version":3,"items":[{"internal_id":"c3"}]}`)); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := archive.Append([]byte(`{"version":3,"items":[{"internal_id":"a1"}]}`)); err == nil {
+		t.Errorf("Append() of an existing internal ID should return an error")
+	}
+
+	want := `{"version":3,"items":[{"internal_id":"a1"},{"internal_id":"b2"},{"internal_id":"c3"}]}`
+	if loaded, _ := archive.Load(); string(loaded) != want {
+		t.Errorf("Load() after Append() = %s, want %s", loaded, want)
+	}
+
+	if err := archive.Remove([]string{"b2", "zz"}); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	want = `{"version":3,"items":[{"internal_id":"a1"},{"internal_id":"c3"}]}`
+	if loaded, _ := archive.Load(); string(loaded) != want {
+		t.Errorf("Load() after Remove() = %s, want %s", loaded, want)
+	}
+}
@@ -0,0 +1,86 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// itemDocument is a todo document split into items keyed by internal ID, the form
+// the event log and database backends work with. Version 0 stands for a bare
+// array without an envelope.
+type itemDocument struct {
+	version int
+	items   map[string]json.RawMessage
+	order   []string
+}
+
+// newItemDocument returns an empty document
+func newItemDocument() itemDocument {
+	return itemDocument{items: make(map[string]json.RawMessage)}
+}
+
+// encode returns the document the way the JSON file backend stores it
+func (doc *itemDocument) encode() ([]byte, error) {
+	items := make([]json.RawMessage, 0, len(doc.order))
+	for _, id := range doc.order {
+		items = append(items, doc.items[id])
+	}
+
+	if doc.version == 0 {
+		return json.Marshal(items)
+	}
+	return json.Marshal(struct {
+		Version int               `json:"version"`
+		Items   []json.RawMessage `json:"items"`
+	}{doc.version, items})
+}
+
+// parseDocument splits a document into items keyed by internal ID. Items without
+// one are keyed by position and repeated IDs get a suffix, so every item is kept.
+func parseDocument(data []byte) (itemDocument, error) {
+	doc := newItemDocument()
+
+	var items []json.RawMessage
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return doc, fmt.Errorf("error parsing document: %w", err)
+		}
+	} else {
+		var envelope struct {
+			Version int               `json:"version"`
+			Items   []json.RawMessage `json:"items"`
+		}
+		if err := json.Unmarshal(trimmed, &envelope); err != nil {
+			return doc, fmt.Errorf("error parsing document: %w", err)
+		}
+		doc.version = envelope.Version
+		items = envelope.Items
+	}
+
+	for index, item := range items {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, item); err != nil {
+			return doc, fmt.Errorf("error parsing item %d: %w", index+1, err)
+		}
+
+		var key struct {
+			InternalID string `json:"internal_id"`
+		}
+		_ = json.Unmarshal(item, &key)
+
+		id := key.InternalID
+		if id == "" {
+			id = "#" + strconv.Itoa(index+1)
+		}
+		for suffix := 2; doc.items[id] != nil; suffix++ {
+			id = key.InternalID + "#" + strconv.Itoa(suffix)
+		}
+
+		doc.items[id] = compact.Bytes()
+		doc.order = append(doc.order, id)
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,268 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// Event log operations, one JSON object per line:
+//
+//	{"op":"version","version":3,"at":"..."}
+//	{"op":"put","id":"3f2a9c1b7d4e","item":{...},"at":"..."}
+//	{"op":"delete","id":"3f2a9c1b7d4e","at":"..."}
+//	{"op":"order","ids":["...","..."],"at":"..."}
+const (
+	opVersion = "version"
+	opPut     = "put"
+	opDelete  = "delete"
+	opOrder   = "order"
+)
+
+// logEvent is one line of the event log
+type logEvent struct {
+	Op      string          `json:"op"`
+	Version *int            `json:"version,omitempty"`
+	ID      string          `json:"id,omitempty"`
+	Item    json.RawMessage `json:"item,omitempty"`
+	IDs     []string        `json:"ids,omitempty"`
+	At      string          `json:"at"`
+}
+
+// EventLog keeps the document as an append-only JSON Lines log of item changes.
+// Saving appends the changes since the replayed state, so earlier states stay on disk.
+type EventLog struct {
+	filename string
+	seed     string // JSON file loaded while the log doesn't exist yet
+}
+
+// NewEventLog creates a backend for the event log at filename
+func NewEventLog(filename string) *EventLog {
+	return &EventLog{filename: filename}
+}
+
+// newSeededEventLog creates an event log that starts from the JSON file at seed,
+// so switching an existing list to the log keeps its items
+func newSeededEventLog(filename, seed string) *EventLog {
+	log := NewEventLog(filename)
+	if seed != filename {
+		log.seed = seed
+	}
+	return log
+}
+
+// Load replays the log into a document; an empty log loads as nil
+func (l *EventLog) Load() ([]byte, error) {
+	logData, err := os.ReadFile(l.filename)
+	if os.IsNotExist(err) {
+		if l.seed != "" {
+			return NewJSONFile(l.seed).Load()
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	state, events, err := replayEventLog(logData)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", l.filename, err)
+	}
+	if events == 0 {
+		return nil, nil
+	}
+	return state.encode()
+}
+
+// Save appends the events that turn the logged state into data. An empty document
+// clears the log.
+func (l *EventLog) Save(data []byte) error {
+	return l.Update(func([]byte) ([]byte, error) {
+		return data, nil
+	})
+}
+
+// Update replays the log, applies fn and appends the resulting changes
+func (l *EventLog) Update(fn func(current []byte) ([]byte, error)) error {
+	logData, err := os.ReadFile(l.filename)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+
+	// Drop a partial last line left by an interrupted append
+	if end := bytes.LastIndexByte(logData, '\n') + 1; end < len(logData) {
+		if err := os.Truncate(l.filename, int64(end)); err != nil {
+			return fmt.Errorf("error repairing %s: %w", l.filename, err)
+		}
+		logData = logData[:end]
+	}
+
+	state, events, err := replayEventLog(logData)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", l.filename, err)
+	}
+
+	var current []byte
+	if events > 0 {
+		if current, err = state.encode(); err != nil {
+			return err
+		}
+	} else if logData == nil && l.seed != "" {
+		if current, err = NewJSONFile(l.seed).Load(); err != nil {
+			return err
+		}
+	}
+
+	updated, err := fn(current)
+	if err != nil {
+		return err
+	}
+
+	if len(bytes.TrimSpace(updated)) == 0 {
+		return WriteFileAtomic(l.filename, nil, 0644)
+	}
+
+	next, err := parseDocument(updated)
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	for _, event := range state.diff(next, events == 0) {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("error encoding event: %w", err)
+		}
+	}
+
+	return appendFile(l.filename, buffer.Bytes())
+}
+
+// replayEventLog applies every event in the log and returns the state and event count
+func replayEventLog(logData []byte) (itemDocument, int, error) {
+	state := newItemDocument()
+	events := 0
+
+	lines := bytes.Split(logData, []byte("\n"))
+	for number, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var event logEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			// A partial last line is an append that never finished
+			if number == len(lines)-1 {
+				break
+			}
+			return state, 0, fmt.Errorf("line %d: %w", number+1, err)
+		}
+		if err := state.apply(event); err != nil {
+			return state, 0, fmt.Errorf("line %d: %w", number+1, err)
+		}
+		events++
+	}
+
+	return state, events, nil
+}
+
+// apply changes the state by one event
+func (s *itemDocument) apply(event logEvent) error {
+	switch event.Op {
+	case opVersion:
+		if event.Version == nil {
+			return fmt.Errorf("version event without a version")
+		}
+		s.version = *event.Version
+	case opPut:
+		if event.ID == "" || len(event.Item) == 0 {
+			return fmt.Errorf("put event needs an id and an item")
+		}
+		if _, ok := s.items[event.ID]; !ok {
+			s.order = append(s.order, event.ID)
+		}
+		s.items[event.ID] = event.Item
+	case opDelete:
+		delete(s.items, event.ID)
+		s.order = slices.DeleteFunc(s.order, func(id string) bool { return id == event.ID })
+	case opOrder:
+		// Keep only known IDs, then any the event left out, so no item is lost
+		order := make([]string, 0, len(s.order))
+		for _, id := range event.IDs {
+			if _, ok := s.items[id]; ok && !slices.Contains(order, id) {
+				order = append(order, id)
+			}
+		}
+		for _, id := range s.order {
+			if !slices.Contains(order, id) {
+				order = append(order, id)
+			}
+		}
+		s.order = order
+	default:
+		return fmt.Errorf("unknown event %q", event.Op)
+	}
+	return nil
+}
+
+// diff returns the events that turn s into next. A fresh log always records its version.
+func (s *itemDocument) diff(next itemDocument, fresh bool) []logEvent {
+	at := time.Now().UTC().Format(time.RFC3339)
+	var events []logEvent
+
+	if fresh || next.version != s.version {
+		version := next.version
+		events = append(events, logEvent{Op: opVersion, Version: &version, At: at})
+	}
+
+	// Deletes first, then puts in document order, which is also the order the
+	// replay appends new items in
+	order := make([]string, 0, len(s.order))
+	for _, id := range s.order {
+		if _, ok := next.items[id]; ok {
+			order = append(order, id)
+			continue
+		}
+		events = append(events, logEvent{Op: opDelete, ID: id, At: at})
+	}
+	for _, id := range next.order {
+		previous, ok := s.items[id]
+		if ok && bytes.Equal(previous, next.items[id]) {
+			continue
+		}
+		if !ok {
+			order = append(order, id)
+		}
+		events = append(events, logEvent{Op: opPut, ID: id, Item: next.items[id], At: at})
+	}
+
+	if !slices.Equal(order, next.order) {
+		events = append(events, logEvent{Op: opOrder, IDs: next.order, At: at})
+	}
+
+	return events
+}
+
+// appendFile appends data to filename in a single write and syncs it, creating the file if needed
+func appendFile(filename string, data []byte) error {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to open %s: %w", filename, err)
+	}
+
+	if len(data) > 0 {
+		if _, err := file.Write(data); err != nil {
+			file.Close()
+			return fmt.Errorf("unable to append to %s: %w", filename, err)
+		}
+		if err := file.Sync(); err != nil {
+			file.Close()
+			return fmt.Errorf("unable to sync %s: %w", filename, err)
+		}
+	}
+
+	return file.Close()
+}
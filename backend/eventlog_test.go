@@ -0,0 +1,122 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readEvents returns the events in the log at path
+func readEvents(t *testing.T, path string) []logEvent {
+	t.Helper()
+	logData, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	var events []logEvent
+	for _, line := range bytes.Split(bytes.TrimSpace(logData), []byte("\n")) {
+		var event logEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			t.Fatalf("invalid event %s: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestEventLog_AppendsChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.jsonl")
+	log := NewEventLog(path)
+
+	log.Save([]byte(`{"version":3,"items":[{"internal_id":"a1","task":"One"},{"internal_id":"b2","task":"Two"}]}`))
+	log.Save([]byte(`{"version":3,"items":[{"internal_id":"a1","task":"One"},{"internal_id":"b2","task":"Two","completed":true}]}`))
+	log.Save([]byte(`{"version":3,"items":[{"internal_id":"b2","task":"Two","completed":true}]}`))
+	log.Save([]byte(`{"version":3,"items":[{"internal_id":"b2","task":"Two","completed":true}]}`))
+
+	var ops []string
+	for _, event := range readEvents(t, path) {
+		ops = append(ops, event.Op+" "+event.ID)
+	}
+	want := "version ,put a1,put b2,put b2,delete a1"
+	if got := strings.Join(ops, ","); got != want {
+		t.Errorf("events = %s, want %s", got, want)
+	}
+}
+
+func TestEventLog_Reorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.jsonl")
+	log := NewEventLog(path)
+
+	log.Save([]byte(`{"version":3,"items":[{"internal_id":"a1"},{"internal_id":"b2"}]}`))
+	reordered := `{"version":3,"items":[{"internal_id":"c3"},{"internal_id":"b2"},{"internal_id":"a1"}]}`
+	log.Save([]byte(reordered))
+
+	events := readEvents(t, path)
+	if last := events[len(events)-1]; last.Op != opOrder {
+		t.Errorf("last event = %s, want order", last.Op)
+	}
+	if loaded, _ := log.Load(); string(loaded) != reordered {
+		t.Errorf("Load() = %s, want %s", loaded, reordered)
+	}
+}
+
+func TestEventLog_PartialLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.jsonl")
+	log := NewEventLog(path)
+
+	document := `{"version":3,"items":[{"internal_id":"a1","task":"One"}]}`
+	log.Save([]byte(document))
+
+	// Simulate an append that was cut off
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	file.WriteString(`{"op":"put","id":"b2","it`)
+	file.Close()
+
+	if loaded, err := log.Load(); err != nil || string(loaded) != document {
+		t.Errorf("Load() = %s, %v; want %s", loaded, err, document)
+	}
+
+	updated := `{"version":3,"items":[{"internal_id":"a1","task":"One"},{"internal_id":"b2","task":"Two"}]}`
+	if err := log.Save([]byte(updated)); err != nil {
+		t.Fatalf("Save() after partial line error = %v", err)
+	}
+	if loaded, err := log.Load(); err != nil || string(loaded) != updated {
+		t.Errorf("Load() = %s, %v; want %s", loaded, err, updated)
+	}
+}
+
+func TestEventLog_SeedFromJSONFile(t *testing.T) {
+	tempDir := t.TempDir()
+	jsonPath := filepath.Join(tempDir, "todos.json")
+	document := `{"version":3,"items":[{"internal_id":"a1","task":"One"}]}`
+	os.WriteFile(jsonPath, []byte(document), 0644)
+
+	store, _ := Open(JSONL, jsonPath)
+	if loaded, err := store.Load(); err != nil || string(loaded) != document {
+		t.Errorf("Load() from JSON file = %s, %v; want %s", loaded, err, document)
+	}
+
+	updated := `{"version":3,"items":[{"internal_id":"a1","task":"One"},{"internal_id":"b2","task":"Two"}]}`
+	if err := store.Save([]byte(updated)); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if loaded, _ := os.ReadFile(jsonPath); string(loaded) != document {
+		t.Errorf("Save() changed the JSON file: %s", loaded)
+	}
+	if loaded, _ := store.Load(); string(loaded) != updated {
+		t.Errorf("Load() = %s, want %s", loaded, updated)
+	}
+}
+
+func TestEventLog_InvalidEvent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.jsonl")
+	os.WriteFile(path, []byte("{\"op\":\"rename\"}\n{\"op\":\"version\",\"version\":3}\n"), 0644)
+
+	if _, err := NewEventLog(path).Load(); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Load() error = %v, want a line 1 error", err)
+	}
+}
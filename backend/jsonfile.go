@@ -0,0 +1,39 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+)
+
+// JSONFile keeps the document as a single JSON file
+type JSONFile struct {
+	filename string
+}
+
+// NewJSONFile creates a backend for the JSON file at filename
+func NewJSONFile(filename string) *JSONFile {
+	return &JSONFile{filename: filename}
+}
+
+// Load reads the file; a missing file loads as nil and is only created by the first Save
+func (f *JSONFile) Load() ([]byte, error) {
+	fileData, err := os.ReadFile(f.filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	return fileData, nil
+}
+
+// Save atomically replaces the file
+func (f *JSONFile) Save(data []byte) error {
+	return WriteFileAtomic(f.filename, data, 0644)
+}
+
+// fileExists reports whether filename exists
+func fileExists(filename string) bool {
+	_, err := os.Stat(filename)
+	return err == nil
+}
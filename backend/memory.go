@@ -0,0 +1,77 @@
+package backend
+
+import (
+	"path/filepath"
+	"sync"
+)
+
+// memoryStore holds the memory backend's documents by path, so every backend
+// opened for the same path in one process sees the same data
+var memoryStore = struct {
+	sync.Mutex
+	documents map[string][]byte
+}{documents: make(map[string][]byte)}
+
+// MemoryBackend keeps the document in process memory. It saves nothing to disk,
+// so it isn't one of the Kinds a user can select; tests build it with NewMemory.
+type MemoryBackend struct {
+	path string
+}
+
+// NewMemory creates a memory backend for path
+func NewMemory(path string) *MemoryBackend {
+	return &MemoryBackend{path: memoryKey(path)}
+}
+
+// Load returns a copy of the stored document
+func (m *MemoryBackend) Load() ([]byte, error) {
+	memoryStore.Lock()
+	defer memoryStore.Unlock()
+	return cloneBytes(memoryStore.documents[m.path]), nil
+}
+
+// Save stores a copy of the document
+func (m *MemoryBackend) Save(data []byte) error {
+	memoryStore.Lock()
+	defer memoryStore.Unlock()
+	m.store(data)
+	return nil
+}
+
+// Update changes the document while holding the store lock
+func (m *MemoryBackend) Update(fn func(current []byte) ([]byte, error)) error {
+	memoryStore.Lock()
+	defer memoryStore.Unlock()
+
+	updated, err := fn(cloneBytes(memoryStore.documents[m.path]))
+	if err != nil {
+		return err
+	}
+	m.store(updated)
+	return nil
+}
+
+// store saves data; the caller holds the store lock
+func (m *MemoryBackend) store(data []byte) {
+	if len(data) == 0 {
+		delete(memoryStore.documents, m.path)
+		return
+	}
+	memoryStore.documents[m.path] = cloneBytes(data)
+}
+
+// memoryKey makes relative and absolute spellings of a path share a document
+func memoryKey(path string) string {
+	if absPath, err := filepath.Abs(path); err == nil {
+		return absPath
+	}
+	return path
+}
+
+// cloneBytes copies data so callers can't change stored documents
+func cloneBytes(data []byte) []byte {
+	if data == nil {
+		return nil
+	}
+	return append([]byte(nil), data...)
+}
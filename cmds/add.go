@@ -15,6 +15,23 @@ func NewAddCommand() *cli.Command {
 		Usage:     "Add a new todo item",
 		Aliases:   []string{"a"},
 		ArgsUsage: "<task>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "priority",
+				Aliases: []string{"p"},
+				Usage:   "Priority of the task (high, medium, low)",
+			},
+			&cli.StringFlag{
+				Name:    "due",
+				Aliases: []string{"d"},
+				Usage:   "Due date (e.g. 2025-09-01, tomorrow, next friday, in 3d)",
+			},
+			&cli.StringSliceFlag{
+				Name:    "tag",
+				Aliases: []string{"t"},
+				Usage:   "Tag the task (repeatable); +tag tokens in the task text are also used",
+			},
+		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			// Validate archive flag usage
 			if err := ValidateArchiveFlagUsage(c, "add"); err != nil {
@@ -25,11 +42,40 @@ func NewAddCommand() *cli.Command {
 				return cli.Exit("task description is required", 1)
 			}
 
-			// Join all arguments as the task description
-			task := strings.Join(c.Args().Slice(), " ")
+			// Join all arguments as the task description and pull out +tag tokens
+			task, tags := ExtractTags(strings.Join(c.Args().Slice(), " "))
+			if task == "" {
+				return cli.Exit("task description is required", 1)
+			}
+
+			for _, value := range c.StringSlice("tag") {
+				tag, err := NormalizeTag(value)
+				if err != nil {
+					return cli.Exit(err.Error(), 1)
+				}
+				tags = appendUniqueTags(tags, tag)
+			}
+
+			// Validate the priority before touching storage
+			priority, err := ParsePriority(c.String("priority"))
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
 
-			// Get the appropriate storage path based on global flag
-			storagePath, err := GetStoragePath(c.Bool("global"))
+			// New local lists are only created by 'todo init'
+			if err := RequireTodoFile(GetStorageOptions(c)); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// Hold the list lock until every file is saved
+			lock, err := lockTodoList(c)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to acquire lock: %v", err), 2)
+			}
+			defer lock.Unlock()
+
+			// Get the appropriate storage path for the selected list
+			storagePath, err := GetStoragePath(GetStorageOptions(c))
 			if err != nil {
 				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
 			}
@@ -45,11 +91,34 @@ func NewAddCommand() *cli.Command {
 				return cli.Exit(fmt.Sprintf("failed to add task: %v", err), 1)
 			}
 
+			if priority != "" {
+				if err := todoList.SetPriority(len(*todoList)-1, priority); err != nil {
+					return cli.Exit(fmt.Sprintf("failed to set priority: %v", err), 1)
+				}
+			}
+
+			if len(tags) > 0 {
+				if err := todoList.AddTags(len(*todoList)-1, tags); err != nil {
+					return cli.Exit(fmt.Sprintf("failed to set tags: %v", err), 1)
+				}
+			}
+
+			if due := c.String("due"); due != "" {
+				if err := todoList.SetDueDate(len(*todoList)-1, due); err != nil {
+					return cli.Exit(fmt.Sprintf("failed to set due date: %v", err), 1)
+				}
+			}
+
 			// Save the updated todo list
 			if err := storage.Save(*todoList); err != nil {
 				return cli.Exit(fmt.Sprintf("error saving todos: %v", err), 2)
 			}
 
+			// Record the change so it can be undone
+			if err := recordJournalEntry(c, storage); err != nil {
+				return cli.Exit(fmt.Sprintf("error recording undo history: %v", err), 2)
+			}
+
 			fmt.Printf("Added task: %s\n", task)
 
 			// Check if --list flag is set and execute list command after add
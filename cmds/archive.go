@@ -14,33 +14,64 @@ func NewArchiveCommand() *cli.Command {
 		Name:      "archive",
 		Usage:     "Archive a todo item by ID (moves to archive file)",
 		Aliases:   []string{"ar"},
-		ArgsUsage: "<id>",
+		ArgsUsage: "<id...> | --where <query>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "where",
+				Aliases: []string{"w"},
+				Usage:   "Archive every item matching a query (e.g. 'completed=true and tag:docs')",
+			},
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "Skip confirmation prompt when archiving with --where",
+			},
+		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			// Validate archive flag usage
 			if err := ValidateArchiveFlagUsage(c, "archive"); err != nil {
 				return cli.Exit(err.Error(), 1)
 			}
 
-			if c.Args().Len() != 1 {
-				return cli.Exit("exactly one ID is required", 1)
+			var predicate Predicate
+			var ranges []IDRange
+			var err error
+
+			if where := c.String("where"); where != "" {
+				if c.Args().Len() > 0 {
+					return cli.Exit("an ID cannot be combined with --where", 1)
+				}
+
+				predicate, err = ParseQuery(where)
+				if err != nil {
+					return cli.Exit(err.Error(), 1)
+				}
+			} else {
+				ranges, err = ParseIDs(c.Args().Slice())
+				if err != nil {
+					return cli.Exit(err.Error(), 1)
+				}
 			}
 
-			id, err := strconv.Atoi(c.Args().First())
-			if err != nil {
-				return cli.Exit(fmt.Sprintf("invalid ID: %s must be a number", c.Args().First()), 1)
+			// New local lists are only created by 'todo init'
+			if err := RequireTodoFile(GetStorageOptions(c)); err != nil {
+				return cli.Exit(err.Error(), 1)
 			}
 
-			if id <= 0 {
-				return cli.Exit("ID must be greater than 0", 1)
+			// Hold the list lock until every file is saved
+			lock, err := lockTodoList(c)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to acquire lock: %v", err), 2)
 			}
+			defer lock.Unlock()
 
-			// Get the appropriate storage paths based on global flag
-			storagePath, err := GetStoragePath(c.Bool("global"))
+			// Get the appropriate storage paths for the selected list
+			storagePath, err := GetStoragePath(GetStorageOptions(c))
 			if err != nil {
 				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
 			}
 
-			archivePath, err := GetArchivePath(c.Bool("global"))
+			archivePath, err := GetArchivePath(GetStorageOptions(c))
 			if err != nil {
 				return cli.Exit(fmt.Sprintf("error getting archive path: %v", err), 2)
 			}
@@ -51,44 +82,68 @@ func NewArchiveCommand() *cli.Command {
 				return cli.Exit(fmt.Sprintf("failed to initialize todo list: %v", err), 2)
 			}
 
-			// Initialize archive list and storage
-			archiveList, archiveStorage, err := initializeTodoListWithPath(archivePath)
-			if err != nil {
-				return cli.Exit(fmt.Sprintf("failed to initialize archive list: %v", err), 2)
-			}
+			// The archive is only appended to, so it isn't loaded here
+			archiveStorage := NewStorage[TodoList](archivePath)
 
-			// Validate the ID exists
-			if id-1 < 0 || id-1 >= len(*todoList) {
-				return cli.Exit(fmt.Sprintf("invalid ID: %d (valid range: 1-%d)", id, len(*todoList)), 1)
-			}
+			// Select the items to archive
+			var selected []Todo
+			if predicate != nil {
+				var remaining TodoList
+				selected, remaining = partitionTodos(*todoList, predicate.Matches)
 
-			// Get the item to archive
-			todoItem := (*todoList)[id-1]
+				if len(selected) == 0 {
+					fmt.Println("No matching items found to archive.")
+					return nil
+				}
 
-			// Add to archive
-			if err := archiveList.Add(todoItem.Task); err != nil {
-				return cli.Exit(fmt.Sprintf("failed to add item to archive: %v", err), 1)
-			}
+				// Show confirmation unless --force flag is used
+				if !c.Bool("force") {
+					confirmed, err := confirmItems("archive", "matching", selected)
+					if err != nil {
+						return cli.Exit(err.Error(), 2)
+					}
+					if !confirmed {
+						fmt.Println("Archive cancelled.")
+						return nil
+					}
+				}
 
-			// Update the archived item to match the original (preserve timestamps and completion status)
-			archiveIndex := len(*archiveList) - 1
-			(*archiveList)[archiveIndex] = todoItem
+				*todoList = remaining
+			} else {
+				// Validate every ID before anything is moved
+				ids, err := ResolveIDs(ranges, *todoList)
+				if err != nil {
+					return cli.Exit(err.Error(), 1)
+				}
 
-			// Remove from main list
-			if err := todoList.Delete(id - 1); err != nil {
-				return cli.Exit(fmt.Sprintf("failed to remove item from todo list: %v", err), 1)
+				selected, err = todoList.takeItems(ids)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("failed to remove item from todo list: %v", err), 1)
+				}
+			}
+
+			// Add to archive first (preserving timestamps and completion status), so a
+			// failure never drops the items from both lists
+			if err := appendTodos(archiveStorage, selected); err != nil {
+				return cli.Exit(fmt.Sprintf("error saving archive: %v", err), 2)
 			}
 
-			// Save both lists
 			if err := storage.Save(*todoList); err != nil {
 				return cli.Exit(fmt.Sprintf("error saving todos: %v", err), 2)
 			}
 
-			if err := archiveStorage.Save(*archiveList); err != nil {
-				return cli.Exit(fmt.Sprintf("error saving archive: %v", err), 2)
+			// Record the change so it can be undone
+			if err := recordJournalEntry(c, storage, archiveStorage); err != nil {
+				return cli.Exit(fmt.Sprintf("error recording undo history: %v", err), 2)
 			}
 
-			fmt.Printf("Archived todo item: %s\n", todoItem.Task)
+			if predicate != nil {
+				fmt.Printf("Successfully archived %d matching item(s).\n", len(selected))
+			} else {
+				for _, item := range selected {
+					fmt.Printf("Archived todo item: %s\n", item.Task)
+				}
+			}
 
 			// Check if --list flag is set and execute list command after archive
 			if CheckAndExecuteListFlag(c) {
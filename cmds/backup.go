@@ -0,0 +1,579 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aquasecurity/table"
+	"github.com/bennthewolfe/todo-cli/backend"
+	"github.com/urfave/cli/v3"
+)
+
+// backupTimeFormat names backups so they sort by time; it is also the restore argument
+const backupTimeFormat = "20060102T150405.000"
+
+// BackupPolicy controls the rotating backups kept before each save
+type BackupPolicy struct {
+	Dir    string        // root directory, ~/.todo/backups when empty
+	Count  int           // backups kept per file; 0 disables backups
+	MaxAge time.Duration // backups older than this are removed; 0 keeps them regardless of age
+}
+
+// backupPolicy is set from the config by ApplyConfig; the zero value disables backups
+var backupPolicy BackupPolicy
+
+// backupStamp is shared by every file saved in one run, so a command's backups restore together
+var backupStamp = sync.OnceValue(func() string {
+	return time.Now().Format(backupTimeFormat)
+})
+
+// unsafeBackupNameChars are replaced when a directory name becomes a backup folder name
+var unsafeBackupNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// Backup is one saved copy of a todo or archive file
+type Backup struct {
+	Stamp string // backupTimeFormat timestamp, shared by files saved together
+	File  string // base name of the file that was backed up
+	Path  string // location of the backup
+}
+
+// Time returns when the backup was taken
+func (b Backup) Time() time.Time {
+	parsed, _ := time.ParseInLocation(backupTimeFormat, b.Stamp, time.Local)
+	return parsed
+}
+
+// parseBackupAge parses a maximum backup age: a Go duration, a number of days ("30d")
+// or weeks ("2w"), or "0" / "" to keep backups regardless of age
+func parseBackupAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		return 0, nil
+	}
+
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			count, err := strconv.Atoi(number)
+			if err != nil || count < 0 {
+				return 0, fmt.Errorf("invalid age: %s", value)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age: %s (use e.g. 30d, 2w or 12h)", value)
+	}
+	return age, nil
+}
+
+// GetBackupDir returns the backup folder for a todo or archive file:
+// ~/.todo/backups/<list>, where <list> is "global", a registered list name, or
+// the file's directory name with a short hash of its path for local and --file lists
+func GetBackupDir(filename string) (string, error) {
+	root := backupPolicy.Dir
+	if root == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("unable to get user home directory: %w", err)
+		}
+		root = filepath.Join(homeDir, ".todo", "backups")
+	}
+
+	name, err := backupListName(filename)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, name), nil
+}
+
+// backupListName names the list a file belongs to
+func backupListName(filename string) (string, error) {
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve %s: %w", filename, err)
+	}
+
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		globalPath := filepath.Join(homeDir, ".todo", "todos.json")
+		if absPath == globalPath || absPath == deriveArchivePath(globalPath) {
+			return GlobalListName, nil
+		}
+	}
+
+	registry, _, err := LoadListRegistry()
+	if err != nil {
+		return "", err
+	}
+	for name, list := range registry.Lists {
+		archivePath := list.ArchivePath
+		if archivePath == "" {
+			archivePath = deriveArchivePath(list.Path)
+		}
+		if absPath == list.Path || absPath == archivePath {
+			return name, nil
+		}
+	}
+
+	// Files in the same directory share a folder, so a list and its archive stay together
+	dir := filepath.Dir(absPath)
+	base := unsafeBackupNameChars.ReplaceAllString(filepath.Base(dir), "-")
+	if strings.Trim(base, "-.") == "" {
+		base = "root"
+	}
+	sum := sha256.Sum256([]byte(dir))
+	return fmt.Sprintf("%s-%x", base, sum[:4]), nil
+}
+
+// backupTodoFile copies the current content of filename into its backup folder
+// and prunes old backups. A file that doesn't exist yet has nothing to keep. A file
+// saved more than once in a run keeps its first backup, the state before the command.
+func backupTodoFile(filename string, fileData []byte) error {
+	if fileData == nil {
+		return nil
+	}
+
+	dir, err := GetBackupDir(filename)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("unable to create backup directory: %w", err)
+	}
+
+	base := filepath.Base(filename)
+	backupPath := filepath.Join(dir, backupStamp()+"_"+base)
+	if _, err := os.Stat(backupPath); err == nil {
+		return nil
+	}
+	if err := backend.WriteFileAtomic(backupPath, fileData, 0600); err != nil {
+		return err
+	}
+
+	return pruneBackups(dir, base, time.Now())
+}
+
+// pruneBackups removes the backups of one file beyond the policy's count and age
+func pruneBackups(dir, file string, now time.Time) error {
+	backups, err := listBackups(dir)
+	if err != nil {
+		return err
+	}
+
+	kept := 0
+	for index := len(backups) - 1; index >= 0; index-- {
+		backup := backups[index]
+		if backup.File != file {
+			continue
+		}
+
+		if kept >= backupPolicy.Count || (backupPolicy.MaxAge > 0 && now.Sub(backup.Time()) > backupPolicy.MaxAge) {
+			if err := os.Remove(backup.Path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("error removing old backup: %w", err)
+			}
+			continue
+		}
+		kept++
+	}
+
+	return nil
+}
+
+// listBackups returns the backups in a folder, oldest first
+func listBackups(dir string) ([]Backup, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading backups: %w", err)
+	}
+
+	var backups []Backup
+	for _, entry := range entries {
+		stamp, file, found := strings.Cut(entry.Name(), "_")
+		if entry.IsDir() || !found {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+			continue
+		}
+		backups = append(backups, Backup{Stamp: stamp, File: file, Path: filepath.Join(dir, entry.Name())})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].Stamp != backups[j].Stamp {
+			return backups[i].Stamp < backups[j].Stamp
+		}
+		return backups[i].File < backups[j].File
+	})
+	return backups, nil
+}
+
+// listBackupsFor returns the backups of the given files, oldest first
+func listBackupsFor(filenames ...string) ([]Backup, error) {
+	var backups []Backup
+	seenDirs := make(map[string]bool)
+	wanted := make(map[string]bool)
+
+	for _, filename := range filenames {
+		wanted[filepath.Base(filename)] = true
+
+		dir, err := GetBackupDir(filename)
+		if err != nil {
+			return nil, err
+		}
+		if seenDirs[dir] {
+			continue
+		}
+		seenDirs[dir] = true
+
+		dirBackups, err := listBackups(dir)
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, dirBackups...)
+	}
+
+	// Other lists in the same directory share the folder
+	var matching []Backup
+	for _, backup := range backups {
+		if wanted[backup.File] {
+			matching = append(matching, backup)
+		}
+	}
+
+	sort.SliceStable(matching, func(i, j int) bool {
+		return matching[i].Stamp < matching[j].Stamp
+	})
+	return matching, nil
+}
+
+// BackupDiff summarizes how restoring a backup changes a list, matching items by internal ID
+type BackupDiff struct {
+	Added   []Todo // in the backup but not in the current list
+	Removed []Todo // in the current list but not in the backup
+	Changed []Todo // in both with different content (backup version)
+}
+
+// Empty reports whether restoring would change nothing
+func (d BackupDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// diffTodoLists compares the current list with the one that would replace it
+func diffTodoLists(current, restored TodoList) BackupDiff {
+	var diff BackupDiff
+
+	currentByID := make(map[string]Todo)
+	for _, todo := range current {
+		currentByID[todo.InternalID] = todo
+	}
+
+	restoredIDs := make(map[string]bool)
+	for _, todo := range restored {
+		restoredIDs[todo.InternalID] = true
+
+		existing, ok := currentByID[todo.InternalID]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, todo)
+		case !reflect.DeepEqual(existing, todo):
+			diff.Changed = append(diff.Changed, todo)
+		}
+	}
+
+	for _, todo := range current {
+		if !restoredIDs[todo.InternalID] {
+			diff.Removed = append(diff.Removed, todo)
+		}
+	}
+
+	return diff
+}
+
+// NewBackupCommand creates a new backup command for urfave/cli
+func NewBackupCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "backup",
+		Usage: "Show and restore the backups kept before each change",
+		Commands: []*cli.Command{
+			newBackupLsCommand(),
+			newBackupRestoreCommand(),
+		},
+	}
+}
+
+// newBackupLsCommand creates the 'backup ls' subcommand
+func newBackupLsCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "ls",
+		Aliases:   []string{"list"},
+		Usage:     "Show the backups of the todo and archive files",
+		ArgsUsage: " ",
+		Action: func(ctx context.Context, c *cli.Command) error {
+			storagePath, err := GetStoragePath(GetStorageOptions(c))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
+			}
+
+			archivePath, err := GetArchivePath(GetStorageOptions(c))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting archive path: %v", err), 2)
+			}
+
+			backups, err := listBackupsFor(storagePath, archivePath)
+			if err != nil {
+				return cli.Exit(err.Error(), 2)
+			}
+
+			if len(backups) == 0 {
+				fmt.Println("No backups found.")
+				return nil
+			}
+
+			t := table.New(os.Stdout)
+			t.SetRowLines(false)
+			t.SetHeaders("Timestamp", "Saved", "File", "Items")
+			for _, backup := range backups {
+				items := "?"
+				if fileData, err := readBackup(backup.Path); err == nil {
+					if todoList, _, err := migrateTodoData(fileData); err == nil {
+						items = strconv.Itoa(len(todoList))
+					}
+				}
+				t.AddRow(backup.Stamp, backup.Time().Format(tableDateFormat+" 15:04:05"), backup.File, items)
+			}
+			t.Render()
+
+			return nil
+		},
+	}
+}
+
+// newBackupRestoreCommand creates the 'backup restore' subcommand
+func newBackupRestoreCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "restore",
+		Usage:     "Restore the todo and archive files saved at a timestamp shown by 'backup ls'",
+		ArgsUsage: "<timestamp>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "Skip confirmation prompt",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if c.Args().Len() != 1 {
+				return cli.Exit("a backup timestamp is required (see 'todo backup ls')", 1)
+			}
+			prefix := c.Args().First()
+
+			// New local lists are only created by 'todo init'
+			if err := RequireTodoFile(GetStorageOptions(c)); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// Hold the list lock until every file is saved
+			lock, err := lockTodoList(c)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to acquire lock: %v", err), 2)
+			}
+			defer lock.Unlock()
+
+			storagePath, err := GetStoragePath(GetStorageOptions(c))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
+			}
+
+			archivePath, err := GetArchivePath(GetStorageOptions(c))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting archive path: %v", err), 2)
+			}
+
+			backups, err := listBackupsFor(storagePath, archivePath)
+			if err != nil {
+				return cli.Exit(err.Error(), 2)
+			}
+
+			stamp, err := matchBackupStamp(backups, prefix)
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			type restore struct {
+				path     string
+				todoList TodoList
+				storage  *Storage[TodoList]
+			}
+			var restores []restore
+
+			for _, path := range []string{storagePath, archivePath} {
+				backup, ok := findBackup(backups, stamp, filepath.Base(path))
+				if !ok {
+					continue
+				}
+
+				fileData, err := readBackup(backup.Path)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("error reading backup: %v", err), 2)
+				}
+				restored, _, err := migrateTodoData(fileData)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("backup %s is not a valid todo file: %v", backup.Path, err), 2)
+				}
+
+				// A file that no longer loads (see 'todo doctor') is replaced as a whole
+				storage := NewStorage[TodoList](path)
+				current, err := storage.Load()
+				if err != nil {
+					fmt.Printf("%s cannot be read (%v); restoring backup %s replaces it\n", path, err, stamp)
+					restores = append(restores, restore{path: path, todoList: restored, storage: storage})
+					continue
+				}
+
+				diff := diffTodoLists(current, restored)
+				printBackupDiff(path, stamp, diff)
+				if !diff.Empty() {
+					restores = append(restores, restore{path: path, todoList: restored, storage: storage})
+				}
+			}
+
+			if len(restores) == 0 {
+				fmt.Println("Nothing to restore.")
+				return nil
+			}
+
+			// Show confirmation unless --force flag is used
+			if !c.Bool("force") {
+				fmt.Printf("\nRestore backup %s? (y/N): ", stamp)
+				response, err := bufio.NewReader(os.Stdin).ReadString('\n')
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("error reading confirmation: %v", err), 2)
+				}
+				if response = strings.TrimSpace(strings.ToLower(response)); response != "y" && response != "yes" {
+					fmt.Println("Restore cancelled.")
+					return nil
+				}
+			}
+
+			// The current files are backed up again by Save, so a restore can be restored away
+			var storages []*Storage[TodoList]
+			for _, r := range restores {
+				if err := r.storage.Save(r.todoList); err != nil {
+					return cli.Exit(fmt.Sprintf("error saving %s: %v", r.path, err), 2)
+				}
+				storages = append(storages, r.storage)
+				fmt.Printf("Restored %s from backup %s\n", r.path, stamp)
+			}
+
+			// Record the change so it can be undone
+			if err := recordJournalEntry(c, storages...); err != nil {
+				return cli.Exit(fmt.Sprintf("error recording undo history: %v", err), 2)
+			}
+
+			// Check if --list flag is set and execute list command after restore
+			if CheckAndExecuteListFlag(c) {
+				if err := ExecuteListCommand(c); err != nil {
+					return cli.Exit(fmt.Sprintf("error executing list: %v", err), 2)
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+// readBackup returns the todo document saved in a backup, decrypting it if needed
+func readBackup(path string) ([]byte, error) {
+	fileData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTodoData(fileData)
+}
+
+// matchBackupStamp finds the one backup timestamp starting with prefix
+func matchBackupStamp(backups []Backup, prefix string) (string, error) {
+	var stamps []string
+	for _, backup := range backups {
+		if strings.HasPrefix(backup.Stamp, prefix) && !containsString(stamps, backup.Stamp) {
+			stamps = append(stamps, backup.Stamp)
+		}
+	}
+
+	switch len(stamps) {
+	case 0:
+		return "", fmt.Errorf("no backup matches %s (see 'todo backup ls')", prefix)
+	case 1:
+		return stamps[0], nil
+	default:
+		return "", fmt.Errorf("ambiguous backup timestamp %s matches: %s", prefix, strings.Join(stamps, ", "))
+	}
+}
+
+// findBackup returns the backup of file taken at stamp
+func findBackup(backups []Backup, stamp, file string) (Backup, bool) {
+	for _, backup := range backups {
+		if backup.Stamp == stamp && backup.File == file {
+			return backup, true
+		}
+	}
+	return Backup{}, false
+}
+
+// printBackupDiff prints what restoring a backup changes in one file
+func printBackupDiff(path, stamp string, diff BackupDiff) {
+	if diff.Empty() {
+		fmt.Printf("%s already matches backup %s\n", path, stamp)
+		return
+	}
+
+	fmt.Printf("%s: %d added, %d removed, %d changed\n", path, len(diff.Added), len(diff.Removed), len(diff.Changed))
+	for _, todo := range diff.Added {
+		fmt.Printf("  + %s\n", todo.Task)
+	}
+	for _, todo := range diff.Removed {
+		fmt.Printf("  - %s\n", todo.Task)
+	}
+	for _, todo := range diff.Changed {
+		fmt.Printf("  ~ %s\n", todo.Task)
+	}
+}
+
+// Legacy command struct for backward compatibility
+type BackupCommand struct{}
+
+func init() {
+	RegisterCommand(&BackupCommand{})
+}
+
+func (c *BackupCommand) Name() string {
+	return "backup"
+}
+
+func (c *BackupCommand) Description() string {
+	return "Show and restore the backups kept before each change"
+}
+
+func (c *BackupCommand) Usage() string {
+	return "todo-cli backup ls | backup restore <timestamp>"
+}
+
+func (c *BackupCommand) Execute(args []string, todoList TodoListInterface) error {
+	// Note: Legacy interface works on an already loaded list
+	return fmt.Errorf("backup functionality not supported in legacy interface")
+}
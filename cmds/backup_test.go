@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bennthewolfe/todo-cli/backend"
+)
+
+// setupTestBackups enables backups into a temporary folder for one test
+func setupTestBackups(t *testing.T, count int, maxAge time.Duration) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	oldPolicy := backupPolicy
+	backupPolicy = BackupPolicy{Dir: dir, Count: count, MaxAge: maxAge}
+	t.Cleanup(func() { backupPolicy = oldPolicy })
+	return dir
+}
+
+func TestParseBackupAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":    0,
+		"0":   0,
+		"30d": 30 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+	}
+	for value, want := range tests {
+		if got, err := parseBackupAge(value); err != nil || got != want {
+			t.Errorf("parseBackupAge(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+
+	for _, value := range []string{"soon", "-1d", "3x"} {
+		if _, err := parseBackupAge(value); err == nil {
+			t.Errorf("parseBackupAge(%q) should fail", value)
+		}
+	}
+}
+
+func TestBackupListName(t *testing.T) {
+	home := setupTestHome(t)
+	cwd, _ := os.Getwd()
+
+	registry, storage, err := LoadListRegistry()
+	if err != nil {
+		t.Fatalf("LoadListRegistry() error = %v", err)
+	}
+	if err := registry.Add("work", filepath.Join(home, "work", "todos.json"), ""); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := SaveListRegistry(registry, storage); err != nil {
+		t.Fatalf("SaveListRegistry() error = %v", err)
+	}
+
+	tests := map[string]string{
+		filepath.Join(home, ".todo", "todos.json"):         "global",
+		filepath.Join(home, ".todo", "todos.archive.json"): "global",
+		filepath.Join(home, "work", "todos.json"):          "work",
+		filepath.Join(home, "work", "todos.archive.json"):  "work",
+	}
+	for path, want := range tests {
+		if got, err := backupListName(path); err != nil || got != want {
+			t.Errorf("backupListName(%s) = %s, %v; want %s", path, got, err, want)
+		}
+	}
+
+	// A local list and its archive share a folder named after their directory
+	local, _ := backupListName(localStorageFile)
+	archive, _ := backupListName(".todos.archive.json")
+	if local != archive || !strings.HasPrefix(local, filepath.Base(cwd)+"-") {
+		t.Errorf("backupListName() = %s and %s, want one folder for %s", local, archive, filepath.Base(cwd))
+	}
+}
+
+func TestStorageSave_Backups(t *testing.T) {
+	setupTestHome(t)
+	backupRoot := setupTestBackups(t, 2, 0)
+
+	storage := NewStorage[TodoList](localStorageFile)
+
+	// The first save has nothing to back up
+	if err := storage.Save(TodoList{{Task: "one"}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	dir, _ := GetBackupDir(localStorageFile)
+	if !strings.HasPrefix(dir, backupRoot) {
+		t.Errorf("GetBackupDir() = %s, want it under %s", dir, backupRoot)
+	}
+	if backups, _ := listBackups(dir); len(backups) != 0 {
+		t.Errorf("backups after the first save = %v, want none", backups)
+	}
+
+	if err := storage.Save(TodoList{{Task: "two"}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	backups, _ := listBackups(dir)
+	if len(backups) != 1 || backups[0].File != localStorageFile {
+		t.Fatalf("backups = %v, want one of %s", backups, localStorageFile)
+	}
+	if content, _ := os.ReadFile(backups[0].Path); !strings.Contains(string(content), `"one"`) {
+		t.Errorf("backup content = %s, want the previous version", content)
+	}
+
+	// Saving again in the same run keeps the backup of the state before the run
+	if err := storage.Save(TodoList{{Task: "three"}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	backups, _ = listBackups(dir)
+	if len(backups) != 1 {
+		t.Fatalf("backups = %v, want the first backup of this run only", backups)
+	}
+	if content, _ := os.ReadFile(backups[0].Path); !strings.Contains(string(content), `"one"`) {
+		t.Errorf("backup content = %s, want the version from before the run", content)
+	}
+
+	// Other stored data is never backed up
+	if err := NewStorage[Journal]("journal.json").Save(Journal{}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if backups, _ := listBackups(dir); len(backups) != 1 {
+		t.Errorf("backups = %v, want only the todo file", backups)
+	}
+}
+
+func TestStorageSave_RowStoreBackups(t *testing.T) {
+	setupTestHome(t)
+	setupTestBackups(t, 2, 0)
+	useTestBackend(t, backend.DB)
+
+	// Saves write only the changed rows, without reading the list for a backup
+	storage := NewStorage[TodoList](localStorageFile)
+	for _, task := range []string{"one", "two"} {
+		if err := storage.Save(TodoList{{InternalID: "a1b2c3d4e5f6", Task: task}}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	dir, _ := GetBackupDir(localStorageFile)
+	if backups, _ := listBackups(dir); len(backups) != 0 {
+		t.Errorf("backups of a row backend = %v, want none", backups)
+	}
+
+	if todoList, err := NewStorage[TodoList](localStorageFile).Load(); err != nil || len(todoList) != 1 || todoList[0].Task != "two" {
+		t.Errorf("Load() = %+v, %v; want the saved item", todoList, err)
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	dir := setupTestBackups(t, 2, 48*time.Hour)
+	now := time.Now()
+
+	for _, age := range []time.Duration{72 * time.Hour, 3 * time.Hour, 2 * time.Hour, time.Hour} {
+		stamp := now.Add(-age).Format(backupTimeFormat)
+		os.WriteFile(filepath.Join(dir, stamp+"_todos.json"), []byte("[]"), 0644)
+	}
+	other := now.Add(-72 * time.Hour).Format(backupTimeFormat)
+	os.WriteFile(filepath.Join(dir, other+"_todos.archive.json"), []byte("[]"), 0644)
+
+	if err := pruneBackups(dir, "todos.json", now); err != nil {
+		t.Fatalf("pruneBackups() error = %v", err)
+	}
+
+	backups, _ := listBackups(dir)
+	var kept []string
+	for _, backup := range backups {
+		kept = append(kept, backup.File+"@"+backup.Stamp)
+	}
+	want := []string{
+		"todos.archive.json@" + other,
+		"todos.json@" + now.Add(-2*time.Hour).Format(backupTimeFormat),
+		"todos.json@" + now.Add(-time.Hour).Format(backupTimeFormat),
+	}
+	if strings.Join(kept, ",") != strings.Join(want, ",") {
+		t.Errorf("kept %v, want %v", kept, want)
+	}
+}
+
+func TestDiffTodoLists(t *testing.T) {
+	current := TodoList{
+		{InternalID: "aaaa", Task: "same"},
+		{InternalID: "bbbb", Task: "edited"},
+		{InternalID: "cccc", Task: "new since backup"},
+	}
+	restored := TodoList{
+		{InternalID: "aaaa", Task: "same"},
+		{InternalID: "bbbb", Task: "original"},
+		{InternalID: "dddd", Task: "deleted since backup"},
+	}
+
+	diff := diffTodoLists(current, restored)
+	if len(diff.Added) != 1 || diff.Added[0].Task != "deleted since backup" {
+		t.Errorf("Added = %v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Task != "new since backup" {
+		t.Errorf("Removed = %v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Task != "original" {
+		t.Errorf("Changed = %v", diff.Changed)
+	}
+	if !diffTodoLists(current, current).Empty() {
+		t.Errorf("diffTodoLists() of identical lists should be empty")
+	}
+}
+
+func TestMatchBackupStamp(t *testing.T) {
+	backups := []Backup{
+		{Stamp: "20250101T100000.000", File: "todos.json"},
+		{Stamp: "20250101T100000.000", File: "todos.archive.json"},
+		{Stamp: "20250102T090000.000", File: "todos.json"},
+	}
+
+	if stamp, err := matchBackupStamp(backups, "20250101"); err != nil || stamp != "20250101T100000.000" {
+		t.Errorf("matchBackupStamp() = %s, %v", stamp, err)
+	}
+	if _, err := matchBackupStamp(backups, "2025"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("matchBackupStamp() error = %v, want ambiguous", err)
+	}
+	if _, err := matchBackupStamp(backups, "2024"); err == nil {
+		t.Errorf("matchBackupStamp() should fail for an unknown timestamp")
+	}
+}
@@ -1,11 +1,8 @@
 package commands
 
 import (
-	"bufio"
 	"context"
 	"fmt"
-	"os"
-	"strings"
 
 	"github.com/urfave/cli/v3"
 )
@@ -28,6 +25,11 @@ func NewCleanupCommand() *cli.Command {
 				Aliases: []string{"d"},
 				Usage:   "Delete completed items instead of archiving them",
 			},
+			&cli.StringFlag{
+				Name:    "where",
+				Aliases: []string{"w"},
+				Usage:   "Select items with a query instead of completion (e.g. 'tag:docs and created<2025-01-01')",
+			},
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			// Validate archive flag usage
@@ -38,28 +40,47 @@ func NewCleanupCommand() *cli.Command {
 			// Determine the action based on flags
 			isDelete := c.Bool("delete")
 
-			// Get the appropriate storage paths based on global flag
-			storagePath, err := GetStoragePath(c.Bool("global"))
+			// Select completed items unless a --where query is given
+			match := func(todo Todo) bool { return todo.Completed }
+			description := "completed"
+			if where := c.String("where"); where != "" {
+				predicate, err := ParseQuery(where)
+				if err != nil {
+					return cli.Exit(err.Error(), 1)
+				}
+				match = predicate.Matches
+				description = "matching"
+			}
+
+			// New local lists are only created by 'todo init'
+			if err := RequireTodoFile(GetStorageOptions(c)); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// Hold the list lock until every file is saved
+			lock, err := lockTodoList(c)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to acquire lock: %v", err), 2)
+			}
+			defer lock.Unlock()
+
+			// Get the appropriate storage paths for the selected list
+			storagePath, err := GetStoragePath(GetStorageOptions(c))
 			if err != nil {
 				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
 			}
 
-			var archivePath string
-			var archiveList *TodoList
 			var archiveStorage *Storage[TodoList]
 
 			// Only initialize archive if we're archiving (not deleting)
 			if !isDelete {
-				archivePath, err = GetArchivePath(c.Bool("global"))
+				archivePath, err := GetArchivePath(GetStorageOptions(c))
 				if err != nil {
 					return cli.Exit(fmt.Sprintf("error getting archive path: %v", err), 2)
 				}
 
-				// Initialize archive list and storage
-				archiveList, archiveStorage, err = initializeTodoListWithPath(archivePath)
-				if err != nil {
-					return cli.Exit(fmt.Sprintf("failed to initialize archive list: %v", err), 2)
-				}
+				// The archive is only appended to, so it isn't loaded here
+				archiveStorage = NewStorage[TodoList](archivePath)
 			}
 
 			// Initialize todo list and storage
@@ -68,53 +89,32 @@ func NewCleanupCommand() *cli.Command {
 				return cli.Exit(fmt.Sprintf("failed to initialize todo list: %v", err), 2)
 			}
 
-			// Find all completed items
-			var completedItems []Todo
-			var remainingItems TodoList
-
-			for _, item := range *todoList {
-				if item.Completed {
-					completedItems = append(completedItems, item)
-				} else {
-					remainingItems = append(remainingItems, item)
-				}
-			}
+			// Find all completed (or matching) items
+			completedItems, remainingItems := partitionTodos(*todoList, match)
 
 			// Check if there are any completed items to process
 			if len(completedItems) == 0 {
 				if isDelete {
-					fmt.Println("No completed items found to delete.")
+					fmt.Printf("No %s items found to delete.\n", description)
 				} else {
-					fmt.Println("No completed items found to archive.")
+					fmt.Printf("No %s items found to archive.\n", description)
 				}
 				return nil
 			}
 
 			// Show confirmation unless --force flag is used
 			if !c.Bool("force") {
+				action := "archive"
 				if isDelete {
-					fmt.Printf("Found %d completed item(s) to delete:\n", len(completedItems))
-				} else {
-					fmt.Printf("Found %d completed item(s) to archive:\n", len(completedItems))
-				}
-				for i, item := range completedItems {
-					fmt.Printf("  %d. %s\n", i+1, item.Task)
-				}
-
-				if isDelete {
-					fmt.Printf("\nAre you sure you want to delete these %d completed item(s)? (y/N): ", len(completedItems))
-				} else {
-					fmt.Printf("\nAre you sure you want to archive these %d completed item(s)? (y/N): ", len(completedItems))
+					action = "delete"
 				}
 
-				reader := bufio.NewReader(os.Stdin)
-				response, err := reader.ReadString('\n')
+				confirmed, err := confirmItems(action, description, completedItems)
 				if err != nil {
-					return cli.Exit(fmt.Sprintf("error reading confirmation: %v", err), 2)
+					return cli.Exit(err.Error(), 2)
 				}
 
-				response = strings.TrimSpace(strings.ToLower(response))
-				if response != "y" && response != "yes" {
+				if !confirmed {
 					if isDelete {
 						fmt.Println("Delete cancelled.")
 					} else {
@@ -129,23 +129,12 @@ func NewCleanupCommand() *cli.Command {
 				*todoList = remainingItems
 			} else {
 				// Archive mode: add completed items to archive, then remove from main list
-				for _, item := range completedItems {
-					if err := archiveList.Add(item.Task); err != nil {
-						return cli.Exit(fmt.Sprintf("failed to add item to archive: %v", err), 1)
-					}
-
-					// Update the archived item to match the original (preserve timestamps and completion status)
-					archiveIndex := len(*archiveList) - 1
-					(*archiveList)[archiveIndex] = item
+				if err := appendTodos(archiveStorage, completedItems); err != nil {
+					return cli.Exit(fmt.Sprintf("error saving archive: %v", err), 2)
 				}
 
 				// Update the main todo list to only contain non-completed items
 				*todoList = remainingItems
-
-				// Save the archive
-				if err := archiveStorage.Save(*archiveList); err != nil {
-					return cli.Exit(fmt.Sprintf("error saving archive: %v", err), 2)
-				}
 			}
 
 			// Save the main todo list (always needed)
@@ -153,11 +142,16 @@ func NewCleanupCommand() *cli.Command {
 				return cli.Exit(fmt.Sprintf("error saving todos: %v", err), 2)
 			}
 
+			// Record the change so it can be undone
+			if err := recordJournalEntry(c, storage, archiveStorage); err != nil {
+				return cli.Exit(fmt.Sprintf("error recording undo history: %v", err), 2)
+			}
+
 			// Show success message
 			if isDelete {
-				fmt.Printf("Successfully deleted %d completed item(s).\n", len(completedItems))
+				fmt.Printf("Successfully deleted %d %s item(s).\n", len(completedItems), description)
 			} else {
-				fmt.Printf("Successfully archived %d completed item(s).\n", len(completedItems))
+				fmt.Printf("Successfully archived %d %s item(s).\n", len(completedItems), description)
 			}
 
 			// Check if --list flag is set and execute list command after cleanup
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/bennthewolfe/todo-cli/backend"
 	"github.com/urfave/cli/v3"
 )
 
@@ -254,6 +255,10 @@ func TestCommandsWithTodoList(t *testing.T) {
 
 // TestGetStoragePath tests the GetStoragePath function
 func TestGetStoragePath(t *testing.T) {
+	// Run from an empty directory so no parent .todos.json is found
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
 	tests := []struct {
 		name     string
 		global   bool
@@ -270,7 +275,7 @@ func TestGetStoragePath(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			if !tt.global {
 				// Test local storage path
-				path, err := GetStoragePath(tt.global)
+				path, err := GetStoragePath(StorageOptions{Global: tt.global})
 				if err != nil {
 					t.Errorf("GetStoragePath() error = %v", err)
 				}
@@ -303,7 +308,7 @@ func TestGetStoragePath(t *testing.T) {
 		os.Setenv("USERPROFILE", tempDir)
 
 		// Test global storage path
-		path, err := GetStoragePath(true)
+		path, err := GetStoragePath(StorageOptions{Global: true})
 		if err != nil {
 			t.Errorf("GetStoragePath() error = %v", err)
 		}
@@ -326,6 +331,10 @@ func TestGetStoragePath(t *testing.T) {
 
 // TestGetArchivePath tests the GetArchivePath function
 func TestGetArchivePath(t *testing.T) {
+	// Run from an empty directory so no parent .todos.json is found
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
 	tests := []struct {
 		name     string
 		global   bool
@@ -342,7 +351,7 @@ func TestGetArchivePath(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			if !tt.global {
 				// Test local archive path
-				path, err := GetArchivePath(tt.global)
+				path, err := GetArchivePath(StorageOptions{Global: tt.global})
 				if err != nil {
 					t.Errorf("GetArchivePath() error = %v", err)
 				}
@@ -375,7 +384,7 @@ func TestGetArchivePath(t *testing.T) {
 		os.Setenv("USERPROFILE", tempDir)
 
 		// Test global archive path
-		path, err := GetArchivePath(true)
+		path, err := GetArchivePath(StorageOptions{Global: true})
 		if err != nil {
 			t.Errorf("GetArchivePath() error = %v", err)
 		}
@@ -396,6 +405,122 @@ func TestGetArchivePath(t *testing.T) {
 	})
 }
 
+// TestGetStoragePath_File tests the --file and --archive-file overrides
+func TestGetStoragePath_File(t *testing.T) {
+	// Run from an empty directory so no parent .todos.json is found
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tests := []struct {
+		name        string
+		opts        StorageOptions
+		wantStorage string
+		wantArchive string
+	}{
+		{
+			name:        "file with derived archive",
+			opts:        StorageOptions{File: "tasks/feature.json", UseActive: true},
+			wantStorage: "tasks/feature.json",
+			wantArchive: "tasks/feature.archive.json",
+		},
+		{
+			name:        "file with explicit archive",
+			opts:        StorageOptions{File: "feature.json", ArchiveFile: "done.json"},
+			wantStorage: "feature.json",
+			wantArchive: "done.json",
+		},
+		{
+			name:        "archive file alone",
+			opts:        StorageOptions{ArchiveFile: "done.json"},
+			wantStorage: ".todos.json",
+			wantArchive: "done.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storagePath, err := GetStoragePath(tt.opts)
+			if err != nil {
+				t.Fatalf("GetStoragePath() error = %v", err)
+			}
+			if storagePath != tt.wantStorage {
+				t.Errorf("GetStoragePath() = %s, want %s", storagePath, tt.wantStorage)
+			}
+
+			archivePath, err := GetArchivePath(tt.opts)
+			if err != nil {
+				t.Fatalf("GetArchivePath() error = %v", err)
+			}
+			if archivePath != tt.wantArchive {
+				t.Errorf("GetArchivePath() = %s, want %s", archivePath, tt.wantArchive)
+			}
+		})
+	}
+
+	if _, err := GetStoragePath(StorageOptions{File: "feature.json", Global: true}); err == nil {
+		t.Errorf("GetStoragePath() should reject --file combined with --global")
+	}
+}
+
+// TestFindLocalStoragePath tests searching parent directories for .todos.json
+func TestFindLocalStoragePath(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	nested := filepath.Join(tempDir, "repo", "src", "pkg")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+
+	// Nothing found yet: default to the current directory and refuse to create it implicitly
+	os.Chdir(nested)
+	path, found, err := findLocalStoragePath()
+	if err != nil || found || path != ".todos.json" {
+		t.Errorf("findLocalStoragePath() = %s, %v, %v; want .todos.json, false, nil", path, found, err)
+	}
+	if err := RequireTodoFile(StorageOptions{}); err == nil || !strings.Contains(err.Error(), "todo init") {
+		t.Errorf("RequireTodoFile() error = %v, want a hint to run todo init", err)
+	}
+
+	// A todo file in a parent directory is found from below
+	repoFile := filepath.Join(tempDir, "repo", ".todos.json")
+	if err := os.WriteFile(repoFile, []byte("[]"), 0644); err != nil {
+		t.Fatalf("Failed to write todo file: %v", err)
+	}
+	path, found, err = findLocalStoragePath()
+	if err != nil || !found || !sameFile(t, path, repoFile) {
+		t.Errorf("findLocalStoragePath() = %s, %v, %v; want %s", path, found, err, repoFile)
+	}
+	if err := RequireTodoFile(StorageOptions{}); err != nil {
+		t.Errorf("RequireTodoFile() error = %v", err)
+	}
+
+	archivePath, err := GetArchivePath(StorageOptions{})
+	if err != nil || !sameFile(t, filepath.Dir(archivePath), filepath.Dir(repoFile)) || filepath.Base(archivePath) != ".todos.archive.json" {
+		t.Errorf("GetArchivePath() = %s, %v; want the archive next to %s", archivePath, err, repoFile)
+	}
+
+	// The search stops at a git repository boundary
+	if err := os.Rename(repoFile, filepath.Join(tempDir, ".todos.json")); err != nil {
+		t.Fatalf("Failed to move todo file: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(tempDir, "repo", ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git: %v", err)
+	}
+	path, found, err = findLocalStoragePath()
+	if err != nil || found || path != ".todos.json" {
+		t.Errorf("findLocalStoragePath() across .git = %s, %v, %v; want .todos.json, false, nil", path, found, err)
+	}
+}
+
+// sameFile reports whether two paths name the same file, ignoring symlinks in temp directories
+func sameFile(t *testing.T, a, b string) bool {
+	t.Helper()
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
 // TestInitializeTodoListWithPath tests the initializeTodoListWithPath function
 func TestInitializeTodoListWithPath(t *testing.T) {
 	tempDir, cleanup := setupTestEnvironment(t)
@@ -434,3 +559,74 @@ func TestInitializeTodoListWithPath(t *testing.T) {
 		t.Errorf("Custom storage file was not created at %s", customPath)
 	}
 }
+
+// useTestBackend selects a storage backend for the rest of the test
+func useTestBackend(t *testing.T, kind string) {
+	t.Helper()
+	previous := storageBackend
+	storageBackend = kind
+	t.Cleanup(func() { storageBackend = previous })
+}
+
+func TestAppendTodos(t *testing.T) {
+	for _, kind := range []string{backend.JSON, backend.DB} {
+		t.Run(kind, func(t *testing.T) {
+			tempDir, cleanup := setupTestEnvironment(t)
+			defer cleanup()
+			useTestBackend(t, kind)
+
+			archivePath := filepath.Join(tempDir, "todos.archive.json")
+			storage := NewStorage[TodoList](archivePath)
+			if err := storage.Save(TodoList{{InternalID: "a1", Task: "First"}}); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			storage = NewStorage[TodoList](archivePath)
+			if err := appendTodos(storage, []Todo{{InternalID: "b2", Task: "Second", position: 4}}); err != nil {
+				t.Fatalf("appendTodos() error = %v", err)
+			}
+
+			// Only row backends skip loading the list
+			if rowStore := kind == backend.DB; (storage.appended != nil) != rowStore || (storage.loaded == nil) != rowStore {
+				t.Errorf("appendTodos() appended = %s, loaded = %s", storage.appended, storage.loaded)
+			}
+
+			todoList, err := NewStorage[TodoList](archivePath).Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if len(todoList) != 2 || todoList[1].Task != "Second" {
+				t.Errorf("Load() after appendTodos() = %+v", todoList)
+			}
+		})
+	}
+}
+
+func TestQueryTodoList(t *testing.T) {
+	for _, kind := range []string{backend.JSON, backend.DB} {
+		t.Run(kind, func(t *testing.T) {
+			tempDir, cleanup := setupTestEnvironment(t)
+			defer cleanup()
+			useTestBackend(t, kind)
+
+			storagePath := filepath.Join(tempDir, "todos.json")
+			NewStorage[TodoList](storagePath).Save(TodoList{
+				{InternalID: "a1", Task: "First", Completed: true},
+				{InternalID: "b2", Task: "Second", Tags: []string{"docs"}},
+				{InternalID: "c3", Task: "Third", Tags: []string{"docs"}},
+			})
+
+			incomplete := false
+			todoList, err := queryTodoList(storagePath, backend.Query{Completed: &incomplete, Tags: []string{"docs"}})
+			if err != nil {
+				t.Fatalf("queryTodoList() error = %v", err)
+			}
+
+			// Backends without indexes return everything for the caller to filter
+			todoList.FilterIncomplete()
+			if len(*todoList) != 2 || (*todoList)[0].displayID(0) != 2 || (*todoList)[1].displayID(1) != 3 {
+				t.Errorf("queryTodoList() = %+v, want items 2 and 3", *todoList)
+			}
+		})
+	}
+}
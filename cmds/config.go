@@ -0,0 +1,487 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bennthewolfe/todo-cli/backend"
+	"github.com/liamg/tml"
+	"github.com/urfave/cli/v3"
+)
+
+// Project config files created by 'todo init', looked up like .todos.json
+const (
+	projectConfigTOML = ".todo.toml"
+	projectConfigJSON = ".todo.json"
+)
+
+// AllowedListFormats lists the formats accepted by list --format and the format setting
+var AllowedListFormats = []string{"table", "json", "pretty", "csv", "tsv", "markdown", "markdown-table", "none"}
+
+// Config holds user settings. Each layer only overrides the values it sets:
+// defaults < user config (~/.todo/config) < project config < environment < flags.
+type Config struct {
+	Format      string `json:"format,omitempty"`       // default list format
+	DateFormat  string `json:"date_format,omitempty"`  // Go time layout used in tables
+	Sort        string `json:"sort,omitempty"`         // default list sort key
+	Columns     string `json:"columns,omitempty"`      // comma-separated columns of csv and tsv output
+	ArchivePath string `json:"archive_path,omitempty"` // archive file for the local list
+	Color       *bool  `json:"color,omitempty"`        // colored table output
+	Backend     string `json:"backend,omitempty"`      // how todo lists are stored (json, jsonl, db)
+	KeyFile     string `json:"key_file,omitempty"`     // file holding the passphrase of encrypted lists
+
+	BackupDir    string `json:"backup_dir,omitempty"`     // root of the rotating backups (~/.todo/backups)
+	BackupCount  *int   `json:"backup_count,omitempty"`   // backups kept per file, 0 disables them
+	BackupMaxAge string `json:"backup_max_age,omitempty"` // backups older than this are removed (e.g. 30d, 12h, 0 to keep)
+}
+
+// DefaultConfig returns the built-in settings
+func DefaultConfig() Config {
+	color := true
+	backupCount := 20
+	return Config{
+		Format:       "table",
+		DateFormat:   "2006-01-02",
+		Color:        &color,
+		Backend:      backend.JSON,
+		BackupCount:  &backupCount,
+		BackupMaxAge: "30d",
+	}
+}
+
+// ColorEnabled reports whether colored output is turned on
+func (cfg Config) ColorEnabled() bool {
+	return cfg.Color == nil || *cfg.Color
+}
+
+// merge overrides cfg with every value set in other
+func (cfg *Config) merge(other Config) {
+	if other.Format != "" {
+		cfg.Format = other.Format
+	}
+	if other.DateFormat != "" {
+		cfg.DateFormat = other.DateFormat
+	}
+	if other.Sort != "" {
+		cfg.Sort = other.Sort
+	}
+	if other.Columns != "" {
+		cfg.Columns = other.Columns
+	}
+	if other.ArchivePath != "" {
+		cfg.ArchivePath = other.ArchivePath
+	}
+	if other.Color != nil {
+		cfg.Color = other.Color
+	}
+	if other.Backend != "" {
+		cfg.Backend = other.Backend
+	}
+	if other.KeyFile != "" {
+		cfg.KeyFile = other.KeyFile
+	}
+	if other.BackupDir != "" {
+		cfg.BackupDir = other.BackupDir
+	}
+	if other.BackupCount != nil {
+		cfg.BackupCount = other.BackupCount
+	}
+	if other.BackupMaxAge != "" {
+		cfg.BackupMaxAge = other.BackupMaxAge
+	}
+}
+
+// validate checks the settings that commands rely on
+func (cfg Config) validate() error {
+	if !containsString(AllowedListFormats, cfg.Format) {
+		return fmt.Errorf("invalid format setting: %s. Allowed formats: %s", cfg.Format, strings.Join(AllowedListFormats, ", "))
+	}
+	if cfg.Sort != "" && !containsString(AllowedSortKeys, cfg.Sort) {
+		return fmt.Errorf("invalid sort setting: %s. Allowed keys: %s", cfg.Sort, strings.Join(AllowedSortKeys, ", "))
+	}
+	if cfg.Columns != "" {
+		if _, err := ParseColumns(cfg.Columns); err != nil {
+			return fmt.Errorf("invalid columns setting: %w", err)
+		}
+	}
+	if strings.TrimSpace(cfg.DateFormat) == "" {
+		return fmt.Errorf("date_format setting must not be empty")
+	}
+	if !containsString(backend.Kinds, cfg.Backend) {
+		return fmt.Errorf("invalid backend setting: %s. Allowed backends: %s", cfg.Backend, strings.Join(backend.Kinds, ", "))
+	}
+	if cfg.BackupCount != nil && *cfg.BackupCount < 0 {
+		return fmt.Errorf("invalid backup_count setting: %d must not be negative", *cfg.BackupCount)
+	}
+	if _, err := parseBackupAge(cfg.BackupMaxAge); err != nil {
+		return fmt.Errorf("invalid backup_max_age setting: %w", err)
+	}
+	return nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUserConfigPath returns the user config file (~/.todo/config)
+func GetUserConfigPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".todo", "config"), nil
+}
+
+// findProjectConfig searches the current directory and its parents for a project
+// config, using the same boundaries as the .todos.json lookup
+func findProjectConfig() (string, bool, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", false, fmt.Errorf("unable to get current directory: %w", err)
+	}
+
+	for dir := cwd; ; {
+		for _, name := range []string{projectConfigTOML, projectConfigJSON} {
+			candidate := filepath.Join(dir, name)
+			if _, err := os.Stat(candidate); err == nil {
+				return candidate, true, nil
+			}
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			break
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	return "", false, nil
+}
+
+// loadFileConfig merges the defaults with the user and project config files.
+// Relative archive paths are resolved against the directory of the file that set them.
+func loadFileConfig() (Config, error) {
+	cfg := DefaultConfig()
+
+	userPath, err := GetUserConfigPath()
+	if err != nil {
+		return cfg, err
+	}
+	projectPath, _, err := findProjectConfig()
+	if err != nil {
+		return cfg, err
+	}
+
+	for _, path := range []string{userPath, projectPath} {
+		if path == "" {
+			continue
+		}
+
+		fileConfig, err := loadConfigFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return cfg, err
+		}
+
+		if fileConfig.ArchivePath != "" && !filepath.IsAbs(fileConfig.ArchivePath) {
+			fileConfig.ArchivePath = filepath.Join(filepath.Dir(path), fileConfig.ArchivePath)
+		}
+		if fileConfig.BackupDir != "" && !filepath.IsAbs(fileConfig.BackupDir) {
+			fileConfig.BackupDir = filepath.Join(filepath.Dir(path), fileConfig.BackupDir)
+		}
+		if fileConfig.KeyFile != "" && !filepath.IsAbs(fileConfig.KeyFile) {
+			fileConfig.KeyFile = filepath.Join(filepath.Dir(path), fileConfig.KeyFile)
+		}
+		cfg.merge(fileConfig)
+	}
+
+	return cfg, nil
+}
+
+// LoadConfig returns the settings for a command, applying config files, TODO_*
+// environment variables and the global --date-format, --color and --backend flags
+func LoadConfig(c *cli.Command) (Config, error) {
+	cfg, err := loadFileConfig()
+	if err != nil {
+		return cfg, err
+	}
+
+	envConfig, err := configFromEnv()
+	if err != nil {
+		return cfg, err
+	}
+	cfg.merge(envConfig)
+
+	if c.IsSet("date-format") {
+		cfg.DateFormat = c.String("date-format")
+	}
+	if c.IsSet("color") {
+		color := c.Bool("color")
+		cfg.Color = &color
+	}
+	if c.IsSet("backend") {
+		cfg.Backend = c.String("backend")
+	}
+
+	if err := cfg.validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// configFromEnv reads settings from TODO_* environment variables (and NO_COLOR)
+func configFromEnv() (Config, error) {
+	cfg := Config{
+		Format:       os.Getenv("TODO_FORMAT"),
+		DateFormat:   os.Getenv("TODO_DATE_FORMAT"),
+		Sort:         os.Getenv("TODO_SORT"),
+		Columns:      os.Getenv("TODO_COLUMNS"),
+		Backend:      os.Getenv("TODO_BACKEND"),
+		KeyFile:      os.Getenv("TODO_KEY_FILE"),
+		BackupDir:    os.Getenv("TODO_BACKUP_DIR"),
+		BackupMaxAge: os.Getenv("TODO_BACKUP_MAX_AGE"),
+	}
+
+	if _, set := os.LookupEnv("NO_COLOR"); set {
+		color := false
+		cfg.Color = &color
+	}
+	if value := os.Getenv("TODO_COLOR"); value != "" {
+		color, err := strconv.ParseBool(value)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid TODO_COLOR value: %s", value)
+		}
+		cfg.Color = &color
+	}
+	if value := os.Getenv("TODO_BACKUP_COUNT"); value != "" {
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid TODO_BACKUP_COUNT value: %s", value)
+		}
+		cfg.BackupCount = &count
+	}
+
+	return cfg, nil
+}
+
+// activeConfig holds the settings last applied by ApplyConfig
+var activeConfig = DefaultConfig()
+
+// CurrentConfig returns the settings applied for this run, so commands don't load the config files again
+func CurrentConfig() Config {
+	return activeConfig
+}
+
+// ApplyConfig applies the display, storage, encryption and backup settings shared by every command
+func ApplyConfig(cfg Config) {
+	activeConfig = cfg
+	tableDateFormat = cfg.DateFormat
+	listColumns = AllowedColumns
+	if cfg.Columns != "" {
+		// validate has already checked the columns
+		listColumns, _ = ParseColumns(cfg.Columns)
+	}
+	storageBackend = cfg.Backend
+	encryptionKeyFile = cfg.KeyFile
+	if cfg.ColorEnabled() {
+		tml.EnableFormatting()
+	} else {
+		tml.DisableFormatting()
+	}
+
+	// validate has already checked the age
+	maxAge, _ := parseBackupAge(cfg.BackupMaxAge)
+	backupPolicy = BackupPolicy{Dir: cfg.BackupDir, MaxAge: maxAge}
+	if cfg.BackupCount != nil {
+		backupPolicy.Count = *cfg.BackupCount
+	}
+}
+
+// loadConfigFile reads a config file as JSON or TOML. The user config has no
+// extension, so anything that starts with '{' is treated as JSON.
+func loadConfigFile(path string) (Config, error) {
+	var cfg Config
+
+	fileData, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+
+	trimmed := bytes.TrimSpace(fileData)
+	if filepath.Ext(path) == ".json" || bytes.HasPrefix(trimmed, []byte("{")) {
+		decoder := json.NewDecoder(bytes.NewReader(fileData))
+		decoder.DisallowUnknownFields()
+		if len(trimmed) > 0 {
+			if err := decoder.Decode(&cfg); err != nil {
+				return cfg, fmt.Errorf("error reading %s: %w", path, err)
+			}
+		}
+		return cfg, nil
+	}
+
+	if err := parseTOMLConfig(fileData, &cfg); err != nil {
+		return cfg, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// parseTOMLConfig parses the flat subset of TOML used by config files:
+// key = value pairs with string, integer or boolean values, and # comments
+func parseTOMLConfig(fileData []byte, cfg *Config) error {
+	scanner := bufio.NewScanner(bytes.NewReader(fileData))
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(stripTOMLComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			return fmt.Errorf("line %d: tables are not supported", lineNumber)
+		}
+
+		key, rawValue, found := strings.Cut(line, "=")
+		if !found {
+			return fmt.Errorf("line %d: expected key = value", lineNumber)
+		}
+		key = strings.TrimSpace(key)
+		rawValue = strings.TrimSpace(rawValue)
+
+		if key == "color" {
+			color, err := strconv.ParseBool(rawValue)
+			if err != nil {
+				return fmt.Errorf("line %d: color must be true or false", lineNumber)
+			}
+			cfg.Color = &color
+			continue
+		}
+		if key == "backup_count" {
+			count, err := strconv.Atoi(rawValue)
+			if err != nil {
+				return fmt.Errorf("line %d: backup_count must be an integer", lineNumber)
+			}
+			cfg.BackupCount = &count
+			continue
+		}
+
+		value, err := parseTOMLString(rawValue)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		switch key {
+		case "format":
+			cfg.Format = value
+		case "date_format":
+			cfg.DateFormat = value
+		case "sort":
+			cfg.Sort = value
+		case "columns":
+			cfg.Columns = value
+		case "archive_path":
+			cfg.ArchivePath = value
+		case "backend":
+			cfg.Backend = value
+		case "key_file":
+			cfg.KeyFile = value
+		case "backup_dir":
+			cfg.BackupDir = value
+		case "backup_max_age":
+			cfg.BackupMaxAge = value
+		default:
+			return fmt.Errorf("line %d: unknown setting: %s", lineNumber, key)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// stripTOMLComment removes a trailing # comment that isn't inside a string
+func stripTOMLComment(line string) string {
+	var quote rune
+	for index, char := range line {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '#':
+			return line[:index]
+		}
+	}
+	return line
+}
+
+// parseTOMLString parses a basic ("...") or literal ('...') TOML string
+func parseTOMLString(value string) (string, error) {
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return value[1 : len(value)-1], nil
+	}
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid string: %s", value)
+		}
+		return unquoted, nil
+	}
+	return "", fmt.Errorf("expected a quoted string, got: %s", value)
+}
+
+// writeProjectConfig writes the default settings to a new project config file
+func writeProjectConfig(path string) error {
+	cfg := DefaultConfig()
+	cfg.ArchivePath = deriveArchivePath(localStorageFile)
+	cfg.Backend = storageBackend
+
+	// Backup retention is a per-user setting, so projects don't override it
+	cfg.BackupCount = nil
+	cfg.BackupMaxAge = ""
+
+	if filepath.Ext(path) == ".json" {
+		fileData, err := json.MarshalIndent(cfg, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling data to JSON: %w", err)
+		}
+		return backend.WriteFileAtomic(path, append(fileData, '\n'), 0644)
+	}
+
+	var builder strings.Builder
+	builder.WriteString("# Project settings for todo. TODO_* environment variables and flags take precedence.\n\n")
+	fmt.Fprintf(&builder, "# Default list format: %s\n", strings.Join(AllowedListFormats, ", "))
+	fmt.Fprintf(&builder, "format = %q\n\n", cfg.Format)
+	builder.WriteString("# Go time layout used for dates in tables\n")
+	fmt.Fprintf(&builder, "date_format = %q\n\n", cfg.DateFormat)
+	fmt.Fprintf(&builder, "# Default sort order: %s (empty keeps the stored order)\n", strings.Join(AllowedSortKeys, ", "))
+	fmt.Fprintf(&builder, "sort = %q\n\n", cfg.Sort)
+	fmt.Fprintf(&builder, "# Columns of csv and tsv output: %s (empty for all)\n", strings.Join(AllowedColumns, ", "))
+	fmt.Fprintf(&builder, "columns = %q\n\n", cfg.Columns)
+	builder.WriteString("# Archive file, relative to this file\n")
+	fmt.Fprintf(&builder, "archive_path = %q\n\n", cfg.ArchivePath)
+	builder.WriteString("# Colored table output\n")
+	fmt.Fprintf(&builder, "color = %t\n\n", cfg.ColorEnabled())
+	fmt.Fprintf(&builder, "# Storage backend: %s\n", strings.Join(backend.Kinds, ", "))
+	fmt.Fprintf(&builder, "backend = %q\n", cfg.Backend)
+
+	return backend.WriteFileAtomic(path, []byte(builder.String()), 0644)
+}
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseTOMLConfig(t *testing.T) {
+	input := `# Project settings
+format = "json"   # trailing comment
+date_format = '02 Jan 2006'
+sort = "priority"
+archive_path = "done/#archive.json"
+color = false
+`
+
+	var cfg Config
+	if err := parseTOMLConfig([]byte(input), &cfg); err != nil {
+		t.Fatalf("parseTOMLConfig() error = %v", err)
+	}
+
+	if cfg.Format != "json" || cfg.DateFormat != "02 Jan 2006" || cfg.Sort != "priority" {
+		t.Errorf("parseTOMLConfig() = %+v", cfg)
+	}
+	if cfg.ArchivePath != "done/#archive.json" {
+		t.Errorf("parseTOMLConfig() archive_path = %s, want done/#archive.json", cfg.ArchivePath)
+	}
+	if cfg.Color == nil || *cfg.Color {
+		t.Errorf("parseTOMLConfig() color should be false")
+	}
+}
+
+func TestParseTOMLConfig_Errors(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":     `colour = true`,
+		"unquoted string": `format = json`,
+		"bad bool":        `color = "yes"`,
+		"table":           `[todo]`,
+		"missing value":   `format`,
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			var cfg Config
+			if err := parseTOMLConfig([]byte(input), &cfg); err == nil || !strings.Contains(err.Error(), "line 1") {
+				t.Errorf("parseTOMLConfig(%q) error = %v, want a line 1 error", input, err)
+			}
+		})
+	}
+}
+
+func TestLoadFileConfig_Precedence(t *testing.T) {
+	home := setupTestHome(t)
+	cwd, _ := os.Getwd()
+
+	// User config (JSON without an extension) sets everything
+	userConfig := `{"format": "pretty", "sort": "due", "date_format": "2006/01/02"}`
+	os.MkdirAll(filepath.Join(home, ".todo"), 0755)
+	if err := os.WriteFile(filepath.Join(home, ".todo", "config"), []byte(userConfig), 0644); err != nil {
+		t.Fatalf("Failed to write user config: %v", err)
+	}
+
+	// The project config overrides part of it
+	projectConfig := "format = \"json\"\narchive_path = \"old/archive.json\"\n"
+	if err := os.WriteFile(projectConfigTOML, []byte(projectConfig), 0644); err != nil {
+		t.Fatalf("Failed to write project config: %v", err)
+	}
+
+	cfg, err := loadFileConfig()
+	if err != nil {
+		t.Fatalf("loadFileConfig() error = %v", err)
+	}
+	if cfg.Format != "json" {
+		t.Errorf("Format = %s, want project value json", cfg.Format)
+	}
+	if cfg.Sort != "due" || cfg.DateFormat != "2006/01/02" {
+		t.Errorf("Sort/DateFormat = %s/%s, want user values due/2006/01/02", cfg.Sort, cfg.DateFormat)
+	}
+	if !cfg.ColorEnabled() {
+		t.Errorf("ColorEnabled() should default to true")
+	}
+
+	// Relative archive paths are resolved next to the project config
+	if want := filepath.Join(cwd, "old", "archive.json"); cfg.ArchivePath != want {
+		t.Errorf("ArchivePath = %s, want %s", cfg.ArchivePath, want)
+	}
+	archivePath, err := GetArchivePath(StorageOptions{})
+	if err != nil || archivePath != cfg.ArchivePath {
+		t.Errorf("GetArchivePath() = %s, %v; want %s", archivePath, err, cfg.ArchivePath)
+	}
+
+	// Environment variables override both files
+	t.Setenv("TODO_FORMAT", "none")
+	t.Setenv("TODO_COLOR", "false")
+	envConfig, err := configFromEnv()
+	if err != nil {
+		t.Fatalf("configFromEnv() error = %v", err)
+	}
+	cfg.merge(envConfig)
+	if cfg.Format != "none" || cfg.ColorEnabled() {
+		t.Errorf("after env merge Format = %s, color = %v; want none, false", cfg.Format, cfg.ColorEnabled())
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.validate(); err != nil {
+		t.Errorf("DefaultConfig().validate() error = %v", err)
+	}
+
+	cfg.Format = "xml"
+	if err := cfg.validate(); err == nil {
+		t.Errorf("validate() should reject format xml")
+	}
+
+	cfg = DefaultConfig()
+	cfg.Sort = "size"
+	if err := cfg.validate(); err == nil {
+		t.Errorf("validate() should reject sort key size")
+	}
+
+	cfg = DefaultConfig()
+	cfg.Backend = "sqlite"
+	if err := cfg.validate(); err == nil {
+		t.Errorf("validate() should reject backend sqlite")
+	}
+
+	cfg = DefaultConfig()
+	cfg.Columns = "task,owner"
+	if err := cfg.validate(); err == nil {
+		t.Errorf("validate() should reject column owner")
+	}
+}
+
+func TestWriteProjectConfig_RoundTrip(t *testing.T) {
+	setupTestHome(t)
+
+	for _, path := range []string{projectConfigTOML, projectConfigJSON} {
+		t.Run(path, func(t *testing.T) {
+			if err := writeProjectConfig(path); err != nil {
+				t.Fatalf("writeProjectConfig() error = %v", err)
+			}
+			defer os.Remove(path)
+
+			cfg, err := loadConfigFile(path)
+			if err != nil {
+				t.Fatalf("loadConfigFile() error = %v", err)
+			}
+			if cfg.Format != "table" || cfg.DateFormat != "2006-01-02" || cfg.ArchivePath != ".todos.archive.json" || !cfg.ColorEnabled() {
+				t.Errorf("loadConfigFile() = %+v, want the defaults", cfg)
+			}
+		})
+	}
+}
+
+func TestApplyConfig_CurrentConfig(t *testing.T) {
+	previous := CurrentConfig()
+	t.Cleanup(func() { ApplyConfig(previous) })
+
+	cfg := DefaultConfig()
+	cfg.Format = "json"
+	cfg.Sort = "due"
+	ApplyConfig(cfg)
+
+	if current := CurrentConfig(); current.Format != "json" || current.Sort != "due" {
+		t.Errorf("CurrentConfig() format, sort = %q, %q; want json, due", current.Format, current.Sort)
+	}
+}
@@ -0,0 +1,296 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AllowedColumns lists the columns of csv and tsv output, named like the JSON fields
+var AllowedColumns = []string{"id", "task", "priority", "due_at", "tags", "completed", "created_at", "updated_at", "completed_at", "internal_id"}
+
+// listColumns are the columns of csv and tsv output, set from 'list --columns' or the columns setting
+var listColumns = AllowedColumns
+
+// ParseColumns splits a comma-separated column list and checks every name
+func ParseColumns(value string) ([]string, error) {
+	var columns []string
+	for _, column := range strings.Split(value, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if column == "" {
+			continue
+		}
+		if !containsString(AllowedColumns, column) {
+			return nil, fmt.Errorf("invalid column: %s. Allowed columns: %s", column, strings.Join(AllowedColumns, ", "))
+		}
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("at least one column is required")
+	}
+	return columns, nil
+}
+
+// viewDelimited prints the list as csv or tsv with a header row
+func (todoList *TodoList) viewDelimited(comma rune) {
+	output, err := encodeDelimited(*todoList, listColumns, comma)
+	if err != nil {
+		fmt.Println("Error writing CSV:", err)
+		return
+	}
+	fmt.Print(string(output))
+}
+
+// encodeDelimited writes a header row and one row per item. Times stay in RFC3339
+// and tags are comma-separated, so the file can be imported again.
+func encodeDelimited(todoList TodoList, columns []string, comma rune) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	writer.Comma = comma
+
+	if err := writer.Write(columns); err != nil {
+		return nil, err
+	}
+	for index, todo := range todoList {
+		row := make([]string, len(columns))
+		for column, name := range columns {
+			row[column] = delimitedValue(todo, index, name)
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
+
+// delimitedValue returns one cell of an item's row
+func delimitedValue(todo Todo, index int, column string) string {
+	switch column {
+	case "id":
+		return strconv.Itoa(todo.displayID(index))
+	case "task":
+		return todo.Task
+	case "priority":
+		return todo.Priority
+	case "due_at":
+		return todo.DueAt
+	case "tags":
+		return strings.Join(todo.Tags, ",")
+	case "completed":
+		return strconv.FormatBool(todo.Completed)
+	case "created_at":
+		return todo.CreatedAt
+	case "updated_at":
+		return todo.UpdatedAt
+	case "completed_at":
+		return todo.CompletedAt
+	case "internal_id":
+		return todo.InternalID
+	default:
+		return ""
+	}
+}
+
+// ParseColumnMap parses an import --map value such as "task=Title,completed=Done"
+// into item fields and the columns that hold them
+func ParseColumnMap(value string) (map[string]string, error) {
+	columnMap := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		field, column, found := strings.Cut(pair, "=")
+		field = strings.ToLower(strings.TrimSpace(field))
+		column = strings.TrimSpace(column)
+		if !found || field == "" || column == "" {
+			return nil, fmt.Errorf("invalid --map entry: %s (use field=Column)", pair)
+		}
+		if field == "id" || !containsString(AllowedColumns, field) {
+			return nil, fmt.Errorf("invalid --map field: %s. Allowed fields: %s", field, strings.Join(AllowedColumns[1:], ", "))
+		}
+		columnMap[field] = column
+	}
+	return columnMap, nil
+}
+
+// decodeDelimited reads a csv or tsv file with a header row. Columns are matched to
+// fields by columnMap, then by header names equal to the field names; task is required.
+// Warnings name the columns and values that weren't imported.
+func decodeDelimited(fileData []byte, comma rune, columnMap map[string]string) (TodoList, []string, error) {
+	// Spreadsheets often start the file with a byte order mark
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(fileData, []byte("\xef\xbb\xbf"))))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("the file has no header row")
+	}
+	header := records[0]
+
+	// Find the column of every field
+	var warnings []string
+	fieldColumns := make(map[string]int)
+	for field, name := range columnMap {
+		column := headerIndex(header, name)
+		if column < 0 {
+			return nil, nil, fmt.Errorf("column %q (mapped to %s) not found; columns: %s", name, field, strings.Join(header, ", "))
+		}
+		fieldColumns[field] = column
+	}
+	for column, name := range header {
+		field := strings.ToLower(strings.TrimSpace(name))
+		if _, mapped := fieldColumns[field]; mapped || !containsString(AllowedColumns, field) || field == "id" || isMappedColumn(fieldColumns, column) {
+			continue
+		}
+		fieldColumns[field] = column
+	}
+	if _, ok := fieldColumns["task"]; !ok {
+		return nil, nil, fmt.Errorf("no task column; name one with --map task=<column>")
+	}
+
+	for column, name := range header {
+		if !strings.EqualFold(strings.TrimSpace(name), "id") && !isMappedColumn(fieldColumns, column) {
+			warnings = append(warnings, fmt.Sprintf("column %q not imported", name))
+		}
+	}
+
+	_, hasTagsColumn := fieldColumns["tags"]
+
+	var todoList TodoList
+	for number, record := range records[1:] {
+		line := number + 2
+		value := func(field string) string {
+			if column, ok := fieldColumns[field]; ok && column < len(record) {
+				return strings.TrimSpace(record[column])
+			}
+			return ""
+		}
+
+		// Without a tags column, +tag words in the task are the only tags there are
+		task, tags := value("task"), []string(nil)
+		if !hasTagsColumn {
+			task, tags = ExtractTags(task)
+		}
+		if task == "" {
+			warnings = append(warnings, fmt.Sprintf("row %d: skipped, it has no task", line))
+			continue
+		}
+
+		todo := Todo{InternalID: value("internal_id"), Task: task, Tags: tags}
+		for _, tag := range strings.FieldsFunc(value("tags"), func(r rune) bool { return r == ',' || r == ' ' || r == ';' }) {
+			normalized, err := NormalizeTag(tag)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("row %d: %v", line, err))
+				continue
+			}
+			todo.Tags = appendUniqueTags(todo.Tags, normalized)
+		}
+
+		if todo.Priority, err = ParsePriority(value("priority")); err != nil {
+			warnings = append(warnings, fmt.Sprintf("row %d: %v", line, err))
+		}
+		if due := value("due_at"); due != "" {
+			if dueAt, err := ParseDueDate(due, time.Now()); err == nil {
+				todo.DueAt = dueAt.Format(time.RFC3339)
+			} else {
+				warnings = append(warnings, fmt.Sprintf("row %d: %v", line, err))
+			}
+		}
+		if completed := value("completed"); completed != "" {
+			if todo.Completed, err = parseCompletedValue(completed); err != nil {
+				warnings = append(warnings, fmt.Sprintf("row %d: %v", line, err))
+			}
+		}
+
+		times := []struct {
+			field  string
+			target *string
+		}{{"created_at", &todo.CreatedAt}, {"updated_at", &todo.UpdatedAt}, {"completed_at", &todo.CompletedAt}}
+		for _, timestamp := range times {
+			if raw := value(timestamp.field); raw != "" {
+				if *timestamp.target, err = parseImportTime(raw); err != nil {
+					warnings = append(warnings, fmt.Sprintf("row %d: invalid %s: %s", line, timestamp.field, raw))
+				}
+			}
+		}
+
+		if todo.InternalID == "" {
+			todo.InternalID = generateShortGUID()
+		}
+		if todo.CreatedAt == "" {
+			todo.CreatedAt = time.Now().Format(time.RFC3339)
+		}
+		if todo.UpdatedAt == "" {
+			todo.UpdatedAt = todo.CreatedAt
+		}
+		if !todo.Completed {
+			todo.CompletedAt = ""
+		} else if todo.CompletedAt == "" {
+			todo.CompletedAt = todo.UpdatedAt
+		}
+		todoList = append(todoList, todo)
+	}
+
+	return todoList, warnings, nil
+}
+
+// isMappedColumn reports whether a column already holds a field
+func isMappedColumn(fieldColumns map[string]int, column int) bool {
+	for _, fieldColumn := range fieldColumns {
+		if fieldColumn == column {
+			return true
+		}
+	}
+	return false
+}
+
+// headerIndex finds a column by name, ignoring case and surrounding spaces
+func headerIndex(header []string, name string) int {
+	for column, existing := range header {
+		if strings.EqualFold(strings.TrimSpace(existing), name) {
+			return column
+		}
+	}
+	return -1
+}
+
+// parseCompletedValue reads a completion cell: true/false, yes/no, 1/0 or x
+func parseCompletedValue(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "y", "x", "done", "✅":
+		return true, nil
+	case "no", "n", "❌":
+		return false, nil
+	}
+	completed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid completed value: %s", value)
+	}
+	return completed, nil
+}
+
+// parseImportTime reads an RFC3339 time or a date (local midnight) into the stored format
+func parseImportTime(value string) (string, error) {
+	for _, layout := range dueDateLayouts {
+		var parsed time.Time
+		var err error
+		if layout == time.RFC3339 {
+			parsed, err = time.Parse(layout, value)
+		} else {
+			parsed, err = time.ParseInLocation(layout, value, time.Local)
+		}
+		if err == nil {
+			return parsed.Format(time.RFC3339), nil
+		}
+	}
+	return "", fmt.Errorf("invalid time: %s", value)
+}
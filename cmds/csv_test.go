@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns(" ID, task ,completed,")
+	if err != nil || !reflect.DeepEqual(columns, []string{"id", "task", "completed"}) {
+		t.Errorf("ParseColumns() = %v, %v", columns, err)
+	}
+
+	for _, value := range []string{"", "task,owner"} {
+		if _, err := ParseColumns(value); err == nil {
+			t.Errorf("ParseColumns(%q) should fail", value)
+		}
+	}
+}
+
+func TestEncodeDelimited(t *testing.T) {
+	todoList := TodoList{
+		{InternalID: "a1b2c3d4e5f6", Task: `Say "hi", then leave`, Tags: []string{"docs", "work"}, CreatedAt: "2025-09-01T10:00:00Z", UpdatedAt: "2025-09-01T10:00:00Z"},
+		{InternalID: "b2c3d4e5f6a1", Task: "Ship", Completed: true, CreatedAt: "2025-09-01T10:00:00Z", UpdatedAt: "2025-09-03T10:00:00Z", CompletedAt: "2025-09-03T10:00:00Z", position: 5},
+	}
+
+	csvData, err := encodeDelimited(todoList, []string{"id", "task", "tags", "completed"}, ',')
+	if err != nil {
+		t.Fatalf("encodeDelimited() error = %v", err)
+	}
+	want := "id,task,tags,completed\n1,\"Say \"\"hi\"\", then leave\",\"docs,work\",false\n5,Ship,,true\n"
+	if string(csvData) != want {
+		t.Errorf("encodeDelimited(csv) = %q, want %q", csvData, want)
+	}
+
+	tsvData, _ := encodeDelimited(todoList, []string{"task", "completed_at"}, '\t')
+	if want := "task\tcompleted_at\n\"Say \"\"hi\"\", then leave\"\t\nShip\t2025-09-03T10:00:00Z\n"; string(tsvData) != want {
+		t.Errorf("encodeDelimited(tsv) = %q, want %q", tsvData, want)
+	}
+}
+
+func TestExportImport_CSVRoundTrip(t *testing.T) {
+	todoList := TodoList{
+		{InternalID: "a1b2c3d4e5f6", Task: "Write docs, then review", Priority: "high", Tags: []string{"docs", "@office"}, CreatedAt: "2025-09-01T10:00:00Z", UpdatedAt: "2025-09-02T10:00:00Z", DueAt: "2025-09-05T23:59:59+02:00"},
+		{InternalID: "b2c3d4e5f6a1", Task: "Ship", Completed: true, CreatedAt: "2025-09-01T10:00:00Z", UpdatedAt: "2025-09-03T10:00:00Z", CompletedAt: "2025-09-03T10:00:00Z"},
+	}
+
+	for _, format := range []string{"csv", "tsv"} {
+		fileData, err := encodeExport(todoList, format)
+		if err != nil {
+			t.Fatalf("encodeExport(%s) error = %v", format, err)
+		}
+
+		imported, warnings, err := decodeImport(fileData, format, nil)
+		if err != nil {
+			t.Fatalf("decodeImport(%s) error = %v", format, err)
+		}
+		if len(warnings) > 0 {
+			t.Errorf("decodeImport(%s) warnings = %v", format, warnings)
+		}
+		if !reflect.DeepEqual(imported, todoList) {
+			t.Errorf("decodeImport(%s) = %+v, want %+v", format, imported, todoList)
+		}
+	}
+}
+
+func TestDecodeDelimited_ColumnMap(t *testing.T) {
+	fileData := []byte("\xef\xbb\xbfTitle,Done,Owner,Due\nBuy milk +shopping,yes,Sam,2025-09-05\nCall plumber,,Alex,someday\n,no,Kim,\n")
+
+	columnMap, err := ParseColumnMap("task=Title, completed=Done,due_at=Due")
+	if err != nil {
+		t.Fatalf("ParseColumnMap() error = %v", err)
+	}
+
+	todoList, warnings, err := decodeDelimited(fileData, ',', columnMap)
+	if err != nil {
+		t.Fatalf("decodeDelimited() error = %v", err)
+	}
+	if len(todoList) != 2 {
+		t.Fatalf("decodeDelimited() = %d items, want 2", len(todoList))
+	}
+
+	first := todoList[0]
+	if first.Task != "Buy milk" || !first.Completed || !reflect.DeepEqual(first.Tags, []string{"shopping"}) || !strings.HasPrefix(first.DueAt, "2025-09-05T23:59:59") {
+		t.Errorf("first item = %+v", first)
+	}
+	if first.InternalID == "" || first.CreatedAt == "" || first.UpdatedAt != first.CreatedAt || first.CompletedAt != first.UpdatedAt {
+		t.Errorf("first item should get an internal ID and timestamps, got %+v", first)
+	}
+	if todoList[1].Completed || todoList[1].DueAt != "" {
+		t.Errorf("second item = %+v", todoList[1])
+	}
+
+	wantWarnings := []string{`column "Owner" not imported`, "row 3: invalid due date: someday", "row 4: skipped, it has no task"}
+	if len(warnings) != len(wantWarnings) {
+		t.Fatalf("decodeDelimited() warnings = %v, want %v", warnings, wantWarnings)
+	}
+	for index, want := range wantWarnings {
+		if !strings.HasPrefix(warnings[index], want) {
+			t.Errorf("warning %d = %q, want %q", index, warnings[index], want)
+		}
+	}
+
+	// With a tags column, +words in the task are text
+	todoList, _, err = decodeDelimited([]byte("task,tags\nReply to +1 votes,work\n"), ',', nil)
+	if err != nil || len(todoList) != 1 || todoList[0].Task != "Reply to +1 votes" || !reflect.DeepEqual(todoList[0].Tags, []string{"work"}) {
+		t.Errorf("decodeDelimited() = %+v, %v; want the task text kept", todoList, err)
+	}
+
+	// Without a task column the file can't be imported
+	if _, _, err := decodeDelimited(fileData, ',', nil); err == nil || !strings.Contains(err.Error(), "--map task=") {
+		t.Errorf("decodeDelimited() error = %v, want a missing task column", err)
+	}
+	if _, _, err := decodeDelimited(fileData, ',', map[string]string{"task": "Name"}); err == nil {
+		t.Error("decodeDelimited() should reject a mapped column that doesn't exist")
+	}
+}
+
+func TestParseColumnMap_Invalid(t *testing.T) {
+	for _, value := range []string{"task", "owner=Owner", "id=ID", "task="} {
+		if _, err := ParseColumnMap(value); err == nil {
+			t.Errorf("ParseColumnMap(%q) should fail", value)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+)
+
+// NewDecryptCommand creates a new decrypt command for urfave/cli
+func NewDecryptCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "decrypt",
+		Usage:     "Store the todo and archive files unencrypted again",
+		ArgsUsage: " ",
+		Action: func(ctx context.Context, c *cli.Command) error {
+			// Validate archive flag usage
+			if err := ValidateArchiveFlagUsage(c, "decrypt"); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// New local lists are only created by 'todo init'
+			if err := RequireTodoFile(GetStorageOptions(c)); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// Hold the list lock until every file is saved
+			lock, err := lockTodoList(c)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to acquire lock: %v", err), 2)
+			}
+			defer lock.Unlock()
+
+			// Get the appropriate storage paths for the selected list
+			storagePath, err := GetStoragePath(GetStorageOptions(c))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
+			}
+
+			archivePath, err := GetArchivePath(GetStorageOptions(c))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting archive path: %v", err), 2)
+			}
+
+			var storages []*Storage[TodoList]
+			for _, path := range []string{storagePath, archivePath} {
+				todoList, storage, err := initializeTodoListWithPath(path)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("failed to initialize %s: %v", path, err), 2)
+				}
+
+				// A missing file has nothing to decrypt
+				if storage.loaded == nil {
+					continue
+				}
+				if storage.encrypt != encryptOn {
+					fmt.Printf("%s: not encrypted\n", path)
+					continue
+				}
+
+				storage.encrypt = encryptOff
+				if err := storage.Save(*todoList); err != nil {
+					return cli.Exit(fmt.Sprintf("error saving %s: %v", path, err), 2)
+				}
+				storages = append(storages, storage)
+				fmt.Printf("%s: decrypted\n", path)
+			}
+
+			// Record the change so it can be undone
+			if err := recordJournalEntry(c, storages...); err != nil {
+				return cli.Exit(fmt.Sprintf("error recording undo history: %v", err), 2)
+			}
+
+			return nil
+		},
+	}
+}
+
+// Legacy command struct for backward compatibility
+type DecryptCommand struct{}
+
+func init() {
+	RegisterCommand(&DecryptCommand{})
+}
+
+func (c *DecryptCommand) Name() string {
+	return "decrypt"
+}
+
+func (c *DecryptCommand) Description() string {
+	return "Store the todo and archive files unencrypted again"
+}
+
+func (c *DecryptCommand) Usage() string {
+	return "todo-cli decrypt"
+}
+
+func (c *DecryptCommand) Execute(args []string, todoList TodoListInterface) error {
+	// Note: Legacy interface works on an already loaded list
+	return fmt.Errorf("decrypt functionality not supported in legacy interface")
+}
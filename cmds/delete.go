@@ -14,28 +14,59 @@ func NewDeleteCommand() *cli.Command {
 		Name:      "delete",
 		Usage:     "Delete a todo item by ID",
 		Aliases:   []string{"del", "rm"},
-		ArgsUsage: "<id>",
+		ArgsUsage: "<id...> | --where <query>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "where",
+				Aliases: []string{"w"},
+				Usage:   "Delete every item matching a query (e.g. 'completed=true and updated<2025-01-01')",
+			},
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "Skip confirmation prompt when deleting with --where",
+			},
+		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			// Validate archive flag usage
 			if err := ValidateArchiveFlagUsage(c, "delete"); err != nil {
 				return cli.Exit(err.Error(), 1)
 			}
 
-			if c.Args().Len() != 1 {
-				return cli.Exit("exactly one ID is required", 1)
+			var predicate Predicate
+			var ranges []IDRange
+			var err error
+
+			if where := c.String("where"); where != "" {
+				if c.Args().Len() > 0 {
+					return cli.Exit("an ID cannot be combined with --where", 1)
+				}
+
+				predicate, err = ParseQuery(where)
+				if err != nil {
+					return cli.Exit(err.Error(), 1)
+				}
+			} else {
+				ranges, err = ParseIDs(c.Args().Slice())
+				if err != nil {
+					return cli.Exit(err.Error(), 1)
+				}
 			}
 
-			id, err := strconv.Atoi(c.Args().First())
-			if err != nil {
-				return cli.Exit(fmt.Sprintf("invalid ID: %s must be a number", c.Args().First()), 1)
+			// New local lists are only created by 'todo init'
+			if err := RequireTodoFile(GetStorageOptions(c)); err != nil {
+				return cli.Exit(err.Error(), 1)
 			}
 
-			if id <= 0 {
-				return cli.Exit("ID must be greater than 0", 1)
+			// Hold the list lock until every file is saved
+			lock, err := lockTodoList(c)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to acquire lock: %v", err), 2)
 			}
+			defer lock.Unlock()
 
-			// Get the appropriate storage path based on global and archive flags
-			storagePath, err := GetEffectiveStoragePath(c.Bool("global"), c.Bool("archive"))
+			// Get the appropriate storage path for the selected list and archive flag
+			storagePath, err := GetEffectiveStoragePath(GetStorageOptions(c), c.Bool("archive"))
 			if err != nil {
 				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
 			}
@@ -46,9 +77,42 @@ func NewDeleteCommand() *cli.Command {
 				return cli.Exit(fmt.Sprintf("failed to initialize todo list: %v", err), 2)
 			}
 
-			// Delete the item
-			if err := todoList.Delete(id - 1); err != nil { // Convert to 0-based index
-				return cli.Exit(fmt.Sprintf("failed to delete task: %v", err), 1)
+			var deleted []Todo
+			var ids []int
+			if predicate != nil {
+				// Delete every matching item
+				var remaining TodoList
+				deleted, remaining = partitionTodos(*todoList, predicate.Matches)
+
+				if len(deleted) == 0 {
+					fmt.Println("No matching items found to delete.")
+					return nil
+				}
+
+				// Show confirmation unless --force flag is used
+				if !c.Bool("force") {
+					confirmed, err := confirmItems("delete", "matching", deleted)
+					if err != nil {
+						return cli.Exit(err.Error(), 2)
+					}
+					if !confirmed {
+						fmt.Println("Delete cancelled.")
+						return nil
+					}
+				}
+
+				*todoList = remaining
+			} else {
+				// Validate every ID before anything is deleted
+				ids, err = ResolveIDs(ranges, *todoList)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("failed to delete task: %v", err), 1)
+				}
+
+				deleted, err = todoList.takeItems(ids)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("failed to delete task: %v", err), 1)
+				}
 			}
 
 			// Save the updated todo list
@@ -56,7 +120,18 @@ func NewDeleteCommand() *cli.Command {
 				return cli.Exit(fmt.Sprintf("error saving todos: %v", err), 2)
 			}
 
-			fmt.Printf("Deleted todo item with ID: %d\n", id)
+			// Record the change so it can be undone
+			if err := recordJournalEntry(c, storage); err != nil {
+				return cli.Exit(fmt.Sprintf("error recording undo history: %v", err), 2)
+			}
+
+			if predicate != nil {
+				fmt.Printf("Successfully deleted %d matching item(s).\n", len(deleted))
+			} else if len(ids) == 1 {
+				fmt.Printf("Deleted todo item with ID: %d\n", ids[0])
+			} else {
+				fmt.Printf("Deleted todo items with IDs: %s\n", formatIDs(ids))
+			}
 
 			// Check if --list flag is set and execute list command after delete
 			if CheckAndExecuteListFlag(c) {
@@ -0,0 +1,394 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+// repairTimestampLayouts are the layouts doctor --fix accepts for timestamps that aren't RFC3339
+var repairTimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// NewDoctorCommand creates a new doctor command for urfave/cli
+func NewDoctorCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "doctor",
+		Usage:     "Check the todo and archive files for problems and optionally repair them",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "fix",
+				Usage: "Repair the problems found, keeping a backup of each changed file",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			// Validate archive flag usage
+			if err := ValidateArchiveFlagUsage(c, "doctor"); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// New local lists are only created by 'todo init'
+			if err := RequireTodoFile(GetStorageOptions(c)); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			fix := c.Bool("fix")
+			if fix {
+				// Hold the list lock until every file is saved
+				lock, err := lockTodoList(c)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("failed to acquire lock: %v", err), 2)
+				}
+				defer lock.Unlock()
+			}
+
+			// Get the appropriate storage paths for the selected list
+			storagePath, err := GetStoragePath(GetStorageOptions(c))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
+			}
+
+			archivePath, err := GetArchivePath(GetStorageOptions(c))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting archive path: %v", err), 2)
+			}
+
+			var storages []*Storage[TodoList]
+			remaining := 0
+			for _, path := range []string{storagePath, archivePath} {
+				storage := NewStorage[TodoList](path)
+				todoList, loadErr := storage.Load()
+
+				// A missing file (usually an archive that was never written) has nothing to check
+				if loadErr == nil && storage.loaded == nil {
+					continue
+				}
+
+				var problems []string
+				report := MigrationReport{FromVersion: CurrentSchemaVersion, ToVersion: CurrentSchemaVersion}
+				if loadErr != nil {
+					// Undecryptable files have no content to salvage from
+					salvaged, dropped := salvageTodos(storage.content)
+					if storage.content == nil || len(salvaged) == 0 {
+						fmt.Printf("%s: 1 problem(s)\n", path)
+						fmt.Printf("  - malformed JSON: %v (cannot be repaired automatically)\n", loadErr)
+						printNewestBackupHint(path)
+						remaining++
+						continue
+					}
+
+					problem := fmt.Sprintf("malformed JSON: %v; %d item(s) can be salvaged, %d dropped", loadErr, len(salvaged), dropped)
+					if !fix {
+						fmt.Printf("%s: 1 problem(s)\n", path)
+						fmt.Printf("  - %s\n", problem)
+						printNewestBackupHint(path)
+						remaining++
+						continue
+					}
+					todoList = salvaged
+					problems = append(problems, problem)
+				} else {
+					var err error
+					if _, report, err = migrateTodoData(storage.content); err != nil {
+						return cli.Exit(fmt.Sprintf("failed to check %s: %v", path, err), 2)
+					}
+					storage.dropped = nil // reported with the other problems
+				}
+
+				problems = append(problems, diagnoseTodos(todoList, report, fix)...)
+				if len(problems) == 0 {
+					fmt.Printf("%s: ok\n", path)
+					continue
+				}
+
+				fmt.Printf("%s: %d problem(s)\n", path, len(problems))
+				for _, problem := range problems {
+					if fix {
+						fmt.Printf("  - %s (fixed)\n", problem)
+					} else {
+						fmt.Printf("  - %s\n", problem)
+					}
+				}
+
+				if !fix {
+					remaining += len(problems)
+					continue
+				}
+
+				// Save keeps the original with the rotating backups
+				if err := storage.Save(todoList); err != nil {
+					return cli.Exit(fmt.Sprintf("error saving %s: %v", path, err), 2)
+				}
+				storages = append(storages, storage)
+
+				if storage.keepsBackups() {
+					backupDir, err := GetBackupDir(path)
+					if err != nil {
+						return cli.Exit(fmt.Sprintf("error getting backup directory: %v", err), 2)
+					}
+					fmt.Printf("  Backup saved to %s (see 'todo backup ls')\n", backupDir)
+				} else {
+					fmt.Println("  No backup kept for this list; 'todo undo' restores the original")
+				}
+			}
+
+			// Record the repairs so they can be undone
+			if err := recordJournalEntry(c, storages...); err != nil {
+				return cli.Exit(fmt.Sprintf("error recording undo history: %v", err), 2)
+			}
+
+			if remaining > 0 {
+				if fix {
+					return cli.Exit(fmt.Sprintf("%d problem(s) could not be repaired", remaining), 1)
+				}
+				return cli.Exit(fmt.Sprintf("%d problem(s) found; run 'todo doctor --fix' to repair them", remaining), 1)
+			}
+
+			return nil
+		},
+	}
+}
+
+// salvageTodos recovers the items of a malformed todo file that still parse on their
+// own, returning them and the number of items that had to be dropped
+func salvageTodos(data []byte) (TodoList, int) {
+	// Items are the objects directly inside the bare array or the envelope's items
+	start := bytes.IndexByte(data, '[')
+	if key := bytes.Index(data, []byte(`"items"`)); key >= 0 && !bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if open := bytes.IndexByte(data[key:], '['); open >= 0 {
+			start = key + open
+		} else {
+			start = -1
+		}
+	}
+	if start < 0 {
+		return nil, 0
+	}
+
+	var items []json.RawMessage
+	dropped := 0
+	depth, itemStart := 0, -1
+	inString, escaped := false, false
+	for index := start + 1; index < len(data); index++ {
+		char := data[index]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case char == '\\':
+				escaped = true
+			case char == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch char {
+		case '"':
+			inString = true
+		case '{':
+			if depth == 0 {
+				itemStart = index
+			}
+			depth++
+		case '}':
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				item := data[itemStart : index+1]
+				var todo Todo
+				if json.Unmarshal(item, &todo) == nil {
+					items = append(items, item)
+				} else {
+					dropped++
+				}
+			}
+		}
+	}
+
+	// An item cut off by the end of the file is lost
+	if depth > 0 {
+		dropped++
+	}
+	if len(items) == 0 {
+		return nil, dropped
+	}
+
+	// Salvaged items go through the usual migrations, which backfill missing IDs
+	array, err := json.Marshal(items)
+	if err != nil {
+		return nil, dropped + len(items)
+	}
+	todoList, _, err := migrateTodoData(array)
+	if err != nil {
+		return nil, dropped + len(items)
+	}
+	return todoList, dropped
+}
+
+// printNewestBackupHint points at the newest backup of a file that still loads
+func printNewestBackupHint(path string) {
+	backups, err := listBackupsFor(path)
+	if err != nil {
+		return
+	}
+	for index := len(backups) - 1; index >= 0; index-- {
+		fileData, err := readBackup(backups[index].Path)
+		if err != nil {
+			continue
+		}
+		if _, _, err := migrateTodoData(fileData); err != nil {
+			continue
+		}
+		fmt.Printf("  The newest readable backup is from %s; run 'todo backup restore %s' to restore it\n", backups[index].Time().Format("2006-01-02 15:04:05"), backups[index].Stamp)
+		return
+	}
+}
+
+// diagnoseTodos describes the problems in a loaded list. With fix set each problem is
+// repaired in place as it is found, so later checks see the repaired values.
+func diagnoseTodos(todoList TodoList, report MigrationReport, fix bool) []string {
+	var problems []string
+	problem := func(id int, format string, args ...any) {
+		prefix := ""
+		if id > 0 {
+			prefix = fmt.Sprintf("item %d: ", id)
+		}
+		problems = append(problems, prefix+fmt.Sprintf(format, args...))
+	}
+
+	if report.NeedsMigration() {
+		problem(0, "schema version %d is out of date (current is %d)", report.FromVersion, report.ToVersion)
+	}
+	for _, dropped := range report.Dropped {
+		problem(0, "%s", dropped)
+	}
+
+	// Loading has already backfilled these, so saving the list repairs them
+	for _, id := range report.Backfilled {
+		problem(id, "missing internal_id")
+	}
+
+	now := time.Now()
+	seen := make(map[string]int)
+	for index := range todoList {
+		todo := &todoList[index]
+		id := index + 1
+
+		// Internal IDs
+		if todo.InternalID == "" {
+			problem(id, "missing internal_id")
+			if fix {
+				todo.InternalID = generateShortGUID()
+			}
+		} else if first, ok := seen[todo.InternalID]; ok {
+			problem(id, "duplicate internal_id %s (also item %d)", todo.InternalID, first)
+			if fix {
+				todo.InternalID = generateShortGUID()
+			}
+		}
+		seen[todo.InternalID] = id
+
+		// Timestamps
+		if !isRFC3339(todo.CreatedAt) {
+			problem(id, "created_at %q is not an RFC3339 timestamp", todo.CreatedAt)
+			if fix {
+				fallback := now.Format(time.RFC3339)
+				if isRFC3339(todo.UpdatedAt) {
+					fallback = todo.UpdatedAt
+				}
+				todo.CreatedAt = repairTimestamp(todo.CreatedAt, fallback)
+			}
+		}
+		if !isRFC3339(todo.UpdatedAt) {
+			problem(id, "updated_at %q is not an RFC3339 timestamp", todo.UpdatedAt)
+			if fix {
+				todo.UpdatedAt = repairTimestamp(todo.UpdatedAt, todo.CreatedAt)
+			}
+		}
+		if todo.CompletedAt != "" && !isRFC3339(todo.CompletedAt) {
+			problem(id, "completed_at %q is not an RFC3339 timestamp", todo.CompletedAt)
+			if fix {
+				todo.CompletedAt = repairTimestamp(todo.CompletedAt, todo.UpdatedAt)
+			}
+		}
+		if todo.DueAt != "" && !isRFC3339(todo.DueAt) {
+			problem(id, "due_at %q is not an RFC3339 timestamp", todo.DueAt)
+			if fix {
+				// Due dates accept everything 'edit --due' does; anything else is cleared
+				if dueAt, err := ParseDueDate(todo.DueAt, now); err == nil {
+					todo.DueAt = dueAt.Format(time.RFC3339)
+				} else {
+					todo.DueAt = ""
+				}
+			}
+		}
+
+		// Completion consistency
+		if todo.Completed && todo.CompletedAt == "" {
+			problem(id, "completed but completed_at is empty")
+			if fix {
+				todo.CompletedAt = todo.UpdatedAt
+			}
+		} else if !todo.Completed && todo.CompletedAt != "" {
+			problem(id, "not completed but completed_at is set")
+			if fix {
+				todo.CompletedAt = ""
+			}
+		}
+	}
+
+	return problems
+}
+
+// isRFC3339 reports whether value is a valid RFC3339 timestamp
+func isRFC3339(value string) bool {
+	_, err := time.Parse(time.RFC3339, value)
+	return err == nil
+}
+
+// repairTimestamp normalizes a timestamp in a common layout to RFC3339, or returns fallback
+func repairTimestamp(value, fallback string) string {
+	for _, layout := range repairTimestampLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return parsed.Format(time.RFC3339)
+		}
+	}
+	return fallback
+}
+
+// Legacy command struct for backward compatibility
+type DoctorCommand struct{}
+
+func init() {
+	RegisterCommand(&DoctorCommand{})
+}
+
+func (c *DoctorCommand) Name() string {
+	return "doctor"
+}
+
+func (c *DoctorCommand) Description() string {
+	return "Check the todo and archive files for problems"
+}
+
+func (c *DoctorCommand) Usage() string {
+	return "todo-cli doctor [--fix]"
+}
+
+func (c *DoctorCommand) Execute(args []string, todoList TodoListInterface) error {
+	// Note: Legacy interface works on an already loaded list
+	return fmt.Errorf("doctor functionality not supported in legacy interface")
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiagnoseTodos(t *testing.T) {
+	current := MigrationReport{FromVersion: CurrentSchemaVersion, ToVersion: CurrentSchemaVersion}
+	valid := "2025-01-01T00:00:00Z"
+
+	todoList := TodoList{
+		{InternalID: "a1b2c3d4e5f6", Task: "ok", CreatedAt: valid, UpdatedAt: valid},
+		{InternalID: "", Task: "missing id", CreatedAt: valid, UpdatedAt: valid},
+		{InternalID: "a1b2c3d4e5f6", Task: "duplicate id", CreatedAt: valid, UpdatedAt: valid},
+		{InternalID: "0000aaaa1111", Task: "bad dates", CreatedAt: "2025-02-03", UpdatedAt: "yesterday", DueAt: "2025-03-04"},
+		{InternalID: "0000aaaa2222", Task: "done", Completed: true, CreatedAt: valid, UpdatedAt: valid},
+		{InternalID: "0000aaaa3333", Task: "open", CompletedAt: valid, CreatedAt: valid, UpdatedAt: valid},
+	}
+
+	problems := diagnoseTodos(todoList, current, false)
+	want := []string{
+		"item 2: missing internal_id",
+		"item 3: duplicate internal_id a1b2c3d4e5f6 (also item 1)",
+		`item 4: created_at "2025-02-03" is not an RFC3339 timestamp`,
+		`item 4: updated_at "yesterday" is not an RFC3339 timestamp`,
+		`item 4: due_at "2025-03-04" is not an RFC3339 timestamp`,
+		"item 5: completed but completed_at is empty",
+		"item 6: not completed but completed_at is set",
+	}
+	if strings.Join(problems, "\n") != strings.Join(want, "\n") {
+		t.Errorf("diagnoseTodos() =\n%s\nwant\n%s", strings.Join(problems, "\n"), strings.Join(want, "\n"))
+	}
+	if todoList[1].InternalID != "" {
+		t.Errorf("diagnoseTodos() without fix changed the list")
+	}
+
+	// Fixing repairs everything, and a second pass finds nothing
+	diagnoseTodos(todoList, current, true)
+	if problems := diagnoseTodos(todoList, current, false); len(problems) != 0 {
+		t.Errorf("problems left after fixing: %v", problems)
+	}
+
+	if todoList[1].InternalID == "" || todoList[2].InternalID == "a1b2c3d4e5f6" {
+		t.Errorf("internal IDs not repaired: %q, %q", todoList[1].InternalID, todoList[2].InternalID)
+	}
+	if created, _ := time.Parse(time.RFC3339, todoList[3].CreatedAt); created.Format("2006-01-02") != "2025-02-03" {
+		t.Errorf("created_at = %s, want the original date normalized", todoList[3].CreatedAt)
+	}
+	if todoList[3].UpdatedAt != todoList[3].CreatedAt {
+		t.Errorf("unparsable updated_at = %s, want it to fall back to created_at", todoList[3].UpdatedAt)
+	}
+	if todoList[4].CompletedAt != valid || todoList[5].CompletedAt != "" {
+		t.Errorf("completed_at not made consistent: %q, %q", todoList[4].CompletedAt, todoList[5].CompletedAt)
+	}
+}
+
+func TestDiagnoseTodos_FileLevel(t *testing.T) {
+	report := MigrationReport{FromVersion: 2, ToVersion: CurrentSchemaVersion, Dropped: []string{`field "estimate" on 1 item(s) is not supported and will be dropped`}}
+
+	problems := diagnoseTodos(TodoList{}, report, false)
+	if len(problems) != 2 || !strings.Contains(problems[0], "schema version 2 is out of date") || !strings.Contains(problems[1], "estimate") {
+		t.Errorf("diagnoseTodos() = %v", problems)
+	}
+}
+
+func TestSalvageTodos(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		tasks   []string
+		dropped int
+	}{
+		{
+			name:    "truncated envelope",
+			data:    `{"version":3,"items":[{"internal_id":"aaaaaaaaaaaa","task":"first {"},{"internal_id":"bbbbbbbbbbbb","task":"sec`,
+			tasks:   []string{"first {"},
+			dropped: 1,
+		},
+		{
+			name:    "broken item in a bare array",
+			data:    `[{"task":"one","created_at":"2025-01-01T00:00:00Z"},{"task":"two" "oops"},{"task":"three \"}\""}]`,
+			tasks:   []string{"one", `three "}"`},
+			dropped: 1,
+		},
+		{
+			name:    "no items",
+			data:    `{"version":3,"items":`,
+			dropped: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todoList, dropped := salvageTodos([]byte(tt.data))
+			var tasks []string
+			for _, todo := range todoList {
+				tasks = append(tasks, todo.Task)
+				if todo.InternalID == "" {
+					t.Errorf("salvaged item %q has no internal_id", todo.Task)
+				}
+			}
+			if strings.Join(tasks, "|") != strings.Join(tt.tasks, "|") || dropped != tt.dropped {
+				t.Errorf("salvageTodos() = %q, %d dropped; want %q, %d dropped", tasks, dropped, tt.tasks, tt.dropped)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dueDateLayouts lists the absolute date formats accepted by ParseDueDate
+var dueDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// relativeDuePattern matches phrases like "in 3d", "in 2 weeks" or "in 4h"
+var relativeDuePattern = regexp.MustCompile(`^in\s*(\d+)\s*(m|min|mins|minutes?|h|hrs?|hours?|d|days?|w|wks?|weeks?)$`)
+
+// ParseDueDate parses an absolute ISO date or a natural language phrase such as
+// "today", "tomorrow", "next friday" or "in 3d" relative to now.
+// Phrases that name a day resolve to the end of that day.
+func ParseDueDate(value string, now time.Time) (time.Time, error) {
+	input := strings.ToLower(strings.TrimSpace(value))
+	if input == "" {
+		return time.Time{}, fmt.Errorf("due date is empty")
+	}
+
+	// Absolute dates first
+	for _, layout := range dueDateLayouts {
+		var parsed time.Time
+		var err error
+		if layout == time.RFC3339 {
+			parsed, err = time.Parse(layout, strings.ToUpper(input))
+		} else {
+			parsed, err = time.ParseInLocation(layout, input, now.Location())
+		}
+		if err != nil {
+			continue
+		}
+		if layout == "2006-01-02" {
+			return endOfDay(parsed), nil
+		}
+		return parsed, nil
+	}
+
+	switch input {
+	case "today":
+		return endOfDay(now), nil
+	case "tomorrow":
+		return endOfDay(now.AddDate(0, 0, 1)), nil
+	case "yesterday":
+		return endOfDay(now.AddDate(0, 0, -1)), nil
+	case "next week":
+		return endOfDay(now.AddDate(0, 0, 7)), nil
+	case "next month":
+		return endOfDay(now.AddDate(0, 1, 0)), nil
+	}
+
+	// Relative offsets such as "in 3d"
+	if matches := relativeDuePattern.FindStringSubmatch(input); matches != nil {
+		amount, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid due date: %s", value)
+		}
+		switch matches[2][0] {
+		case 'm':
+			return now.Add(time.Duration(amount) * time.Minute), nil
+		case 'h':
+			return now.Add(time.Duration(amount) * time.Hour), nil
+		case 'd':
+			return endOfDay(now.AddDate(0, 0, amount)), nil
+		case 'w':
+			return endOfDay(now.AddDate(0, 0, 7*amount)), nil
+		}
+	}
+
+	// Weekdays such as "friday" (today counts) or "next friday" (today excluded)
+	day, strict := input, false
+	if strings.HasPrefix(day, "next ") {
+		day, strict = strings.TrimSpace(strings.TrimPrefix(day, "next ")), true
+	}
+	if weekday, ok := parseWeekday(day); ok {
+		offset := (int(weekday) - int(now.Weekday()) + 7) % 7
+		if offset == 0 && strict {
+			offset = 7
+		}
+		return endOfDay(now.AddDate(0, 0, offset)), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid due date: %s (use YYYY-MM-DD, RFC3339, today, tomorrow, next <weekday> or in <n>d)", value)
+}
+
+// parseWeekday converts a full or abbreviated weekday name to a time.Weekday
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || name == full[:3] {
+			return day, true
+		}
+	}
+	return time.Sunday, false
+}
+
+// endOfDay returns the last second of the day containing t
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}
+
+// IsOverdue reports whether an incomplete todo is past its due date
+func (todo Todo) IsOverdue(now time.Time) bool {
+	if todo.Completed || todo.DueAt == "" {
+		return false
+	}
+	dueAt, err := time.Parse(time.RFC3339, todo.DueAt)
+	if err != nil {
+		return false
+	}
+	return dueAt.Before(now)
+}
+
+// SetDueDate sets or clears (with an empty value or "none") the due date of the item at index
+func (todoList *TodoList) SetDueDate(index int, value string) error {
+	return todoList.setDueDate(index, value, time.Now())
+}
+
+// FilterOverdue keeps only incomplete items that are past their due date
+func (todoList *TodoList) FilterOverdue(now time.Time) {
+	todoList.filter(func(todo Todo) bool {
+		return todo.IsOverdue(now)
+	})
+}
+
+// FilterDueRange keeps only items due before and/or after the given times;
+// a zero time leaves that side of the range open
+func (todoList *TodoList) FilterDueRange(before, after time.Time) {
+	todoList.filter(func(todo Todo) bool {
+		dueAt, err := time.Parse(time.RFC3339, todo.DueAt)
+		if err != nil {
+			return false
+		}
+		if !before.IsZero() && !dueAt.Before(before) {
+			return false
+		}
+		if !after.IsZero() && !dueAt.After(after) {
+			return false
+		}
+		return true
+	})
+}
+
+func (todoList *TodoList) setDueDate(index int, value string, now time.Time) error {
+	t := *todoList
+
+	// Validate the index before attempting to update
+	if err := t.validateIndex(index); err != nil {
+		return err
+	}
+
+	dueAt := ""
+	if trimmed := strings.TrimSpace(value); trimmed != "" && !strings.EqualFold(trimmed, "none") {
+		parsed, err := ParseDueDate(trimmed, now)
+		if err != nil {
+			return err
+		}
+		dueAt = parsed.Format(time.RFC3339)
+	}
+
+	t[index].DueAt = dueAt
+	t[index].UpdatedAt = now.Format(time.RFC3339)
+
+	return nil
+}
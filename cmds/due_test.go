@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDueDate(t *testing.T) {
+	// Wednesday, 2025-08-06 10:30 UTC
+	now := time.Date(2025, 8, 6, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"2025-08-20", time.Date(2025, 8, 20, 23, 59, 59, 0, time.UTC)},
+		{"2025-08-20 14:00", time.Date(2025, 8, 20, 14, 0, 0, 0, time.UTC)},
+		{"2025-08-20T14:00:00Z", time.Date(2025, 8, 20, 14, 0, 0, 0, time.UTC)},
+		{"today", time.Date(2025, 8, 6, 23, 59, 59, 0, time.UTC)},
+		{"Tomorrow", time.Date(2025, 8, 7, 23, 59, 59, 0, time.UTC)},
+		{"friday", time.Date(2025, 8, 8, 23, 59, 59, 0, time.UTC)},
+		{"wednesday", time.Date(2025, 8, 6, 23, 59, 59, 0, time.UTC)},
+		{"next wednesday", time.Date(2025, 8, 13, 23, 59, 59, 0, time.UTC)},
+		{"next fri", time.Date(2025, 8, 8, 23, 59, 59, 0, time.UTC)},
+		{"in 3d", time.Date(2025, 8, 9, 23, 59, 59, 0, time.UTC)},
+		{"in 2 weeks", time.Date(2025, 8, 20, 23, 59, 59, 0, time.UTC)},
+		{"in 4h", time.Date(2025, 8, 6, 14, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDueDate(tt.input, now)
+			if err != nil {
+				t.Fatalf("ParseDueDate(%q) error = %v", tt.input, err)
+			}
+			if !got.Equal(tt.expected) {
+				t.Errorf("ParseDueDate(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "someday", "in 3 months", "next tomorrow"} {
+		if _, err := ParseDueDate(input, now); err == nil {
+			t.Errorf("ParseDueDate(%q) expected error", input)
+		}
+	}
+}
+
+func TestTodo_IsOverdue(t *testing.T) {
+	now := time.Date(2025, 8, 6, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		todo     Todo
+		expected bool
+	}{
+		{"past due", Todo{DueAt: "2025-08-05T23:59:59Z"}, true},
+		{"future due", Todo{DueAt: "2025-08-07T23:59:59Z"}, false},
+		{"completed past due", Todo{DueAt: "2025-08-05T23:59:59Z", Completed: true}, false},
+		{"no due date", Todo{}, false},
+		{"invalid due date", Todo{DueAt: "soon"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.todo.IsOverdue(now); got != tt.expected {
+				t.Errorf("IsOverdue() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTodoList_FilterDueRange(t *testing.T) {
+	todoList := &TodoList{
+		{Task: "Early", DueAt: "2025-08-01T12:00:00Z"},
+		{Task: "No due date"},
+		{Task: "Middle", DueAt: "2025-08-10T12:00:00Z"},
+		{Task: "Late", DueAt: "2025-08-20T12:00:00Z"},
+	}
+
+	after := time.Date(2025, 8, 5, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC)
+	todoList.FilterDueRange(before, after)
+
+	if len(*todoList) != 1 || (*todoList)[0].Task != "Middle" {
+		t.Fatalf("FilterDueRange() = %v, want only 'Middle'", *todoList)
+	}
+	if id := (*todoList)[0].displayID(0); id != 3 {
+		t.Errorf("FilterDueRange() display ID = %d, want 3", id)
+	}
+}
+
+func TestTodoList_SetDueDate(t *testing.T) {
+	now := time.Date(2025, 8, 6, 10, 30, 0, 0, time.UTC)
+	todoList := &TodoList{{Task: "Task"}}
+
+	if err := todoList.setDueDate(0, "tomorrow", now); err != nil {
+		t.Fatalf("setDueDate() error = %v", err)
+	}
+	if got := (*todoList)[0].DueAt; got != "2025-08-07T23:59:59Z" {
+		t.Errorf("setDueDate() DueAt = %q, want %q", got, "2025-08-07T23:59:59Z")
+	}
+
+	if err := todoList.setDueDate(0, "none", now); err != nil {
+		t.Fatalf("setDueDate() clear error = %v", err)
+	}
+	if got := (*todoList)[0].DueAt; got != "" {
+		t.Errorf("setDueDate() clear DueAt = %q, want empty", got)
+	}
+
+	if err := todoList.setDueDate(0, "whenever", now); err == nil {
+		t.Errorf("setDueDate() expected error for invalid date")
+	}
+}
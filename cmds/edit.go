@@ -15,31 +15,78 @@ func NewEditCommand() *cli.Command {
 		Name:      "edit",
 		Usage:     "Edit a todo item by ID",
 		Aliases:   []string{"e"},
-		ArgsUsage: "<id> <new_task>",
+		ArgsUsage: "<id[,id...]> [new_task]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "priority",
+				Aliases: []string{"p"},
+				Usage:   "Set the priority of the task (high, medium, low, none)",
+			},
+			&cli.StringFlag{
+				Name:    "due",
+				Aliases: []string{"d"},
+				Usage:   "Set the due date (e.g. 2025-09-01, tomorrow, next friday, in 3d, none)",
+			},
+			&cli.StringSliceFlag{
+				Name:    "tag",
+				Aliases: []string{"t"},
+				Usage:   "Add a tag, or remove it with a '!' prefix (repeatable)",
+			},
+		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			// Validate archive flag usage
 			if err := ValidateArchiveFlagUsage(c, "edit"); err != nil {
 				return cli.Exit(err.Error(), 1)
 			}
 
-			if c.Args().Len() < 2 {
+			// A new task description is optional when only the priority, due date or tags change
+			hasPriority := c.IsSet("priority")
+			hasDue := c.IsSet("due")
+			hasTags := c.IsSet("tag")
+			if c.Args().Len() < 1 || (c.Args().Len() < 2 && !hasPriority && !hasDue && !hasTags) {
 				return cli.Exit("ID and new task description are required", 1)
 			}
 
-			id, err := strconv.Atoi(c.Args().First())
+			// The first argument selects the items, e.g. "3" or "1,4-6". With --priority,
+			// --due or --tag every leading ID argument does, as in 'edit 1 3 5-8 -p high'.
+			args := c.Args().Slice()
+			idArgs := 1
+			if hasPriority || hasDue || hasTags {
+				for idArgs < len(args) && idListPattern.MatchString(args[idArgs]) {
+					idArgs++
+				}
+			}
+			ranges, err := ParseIDs(args[:idArgs])
 			if err != nil {
-				return cli.Exit(fmt.Sprintf("invalid ID: %s must be a number", c.Args().First()), 1)
+				return cli.Exit(err.Error(), 1)
 			}
 
-			if id <= 0 {
-				return cli.Exit("ID must be greater than 0", 1)
+			// Join all arguments after the IDs as the new task and pull out +tag tokens
+			newTask, addTags := ExtractTags(strings.Join(args[idArgs:], " "))
+			if len(args) > idArgs && newTask == "" && len(addTags) == 0 {
+				return cli.Exit("ID and new task description are required", 1)
 			}
 
-			// Join all arguments after the ID as the new task
-			newTask := strings.Join(c.Args().Slice()[1:], " ")
+			include, removeTags, err := ParseTagFilters(c.StringSlice("tag"))
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+			addTags = appendUniqueTags(addTags, include...)
 
-			// Get the appropriate storage path based on global flag
-			storagePath, err := GetStoragePath(c.Bool("global"))
+			// New local lists are only created by 'todo init'
+			if err := RequireTodoFile(GetStorageOptions(c)); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// Hold the list lock until every file is saved
+			lock, err := lockTodoList(c)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to acquire lock: %v", err), 2)
+			}
+			defer lock.Unlock()
+
+			// Get the appropriate storage path for the selected list
+			storagePath, err := GetStoragePath(GetStorageOptions(c))
 			if err != nil {
 				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
 			}
@@ -50,17 +97,64 @@ func NewEditCommand() *cli.Command {
 				return cli.Exit(fmt.Sprintf("failed to initialize todo list: %v", err), 2)
 			}
 
-			// Update the item
-			if err := todoList.Update(id-1, newTask); err != nil { // Convert to 0-based index
+			// Validate every ID before anything is changed
+			ids, err := ResolveIDs(ranges, *todoList)
+			if err != nil {
 				return cli.Exit(fmt.Sprintf("failed to update task: %v", err), 1)
 			}
+			if len(ids) > 1 && newTask != "" {
+				return cli.Exit("a new task description can only be set for a single ID", 1)
+			}
+
+			// Nothing is saved unless every item updates cleanly
+			for _, id := range ids {
+				// Update the item
+				if newTask != "" {
+					if err := todoList.Update(id-1, newTask); err != nil { // Convert to 0-based index
+						return cli.Exit(fmt.Sprintf("failed to update task: %v", err), 1)
+					}
+				}
+
+				// Update the priority
+				if hasPriority {
+					if err := todoList.SetPriority(id-1, c.String("priority")); err != nil {
+						return cli.Exit(fmt.Sprintf("failed to set priority: %v", err), 1)
+					}
+				}
+
+				// Update the tags
+				if len(addTags) > 0 {
+					if err := todoList.AddTags(id-1, addTags); err != nil {
+						return cli.Exit(fmt.Sprintf("failed to add tags: %v", err), 1)
+					}
+				}
+				if len(removeTags) > 0 {
+					if err := todoList.RemoveTags(id-1, removeTags); err != nil {
+						return cli.Exit(fmt.Sprintf("failed to remove tags: %v", err), 1)
+					}
+				}
+
+				// Update the due date
+				if hasDue {
+					if err := todoList.SetDueDate(id-1, c.String("due")); err != nil {
+						return cli.Exit(fmt.Sprintf("failed to set due date: %v", err), 1)
+					}
+				}
+			}
 
 			// Save the updated todo list
 			if err := storage.Save(*todoList); err != nil {
 				return cli.Exit(fmt.Sprintf("error saving todos: %v", err), 2)
 			}
 
-			fmt.Printf("Updated todo item %d: %s\n", id, newTask)
+			// Record the change so it can be undone
+			if err := recordJournalEntry(c, storage); err != nil {
+				return cli.Exit(fmt.Sprintf("error recording undo history: %v", err), 2)
+			}
+
+			for _, id := range ids {
+				fmt.Printf("Updated todo item %d: %s\n", id, (*todoList)[id-1].Task)
+			}
 
 			// Check if --list flag is set and execute list command after edit
 			if CheckAndExecuteListFlag(c) {
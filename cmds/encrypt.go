@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bennthewolfe/todo-cli/backend"
+	"github.com/urfave/cli/v3"
+)
+
+// NewEncryptCommand creates a new encrypt command for urfave/cli
+func NewEncryptCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "encrypt",
+		Usage:     "Encrypt the todo and archive files with a passphrase",
+		ArgsUsage: " ",
+		Action: func(ctx context.Context, c *cli.Command) error {
+			// Validate archive flag usage
+			if err := ValidateArchiveFlagUsage(c, "encrypt"); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// New local lists are only created by 'todo init'
+			if err := RequireTodoFile(GetStorageOptions(c)); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			if storageBackend != backend.JSON {
+				return cli.Exit(fmt.Sprintf("encryption is only supported with the %s backend", backend.JSON), 1)
+			}
+
+			// Hold the list lock until every file is saved
+			lock, err := lockTodoList(c)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to acquire lock: %v", err), 2)
+			}
+			defer lock.Unlock()
+
+			// Get the appropriate storage paths for the selected list
+			storagePath, err := GetStoragePath(GetStorageOptions(c))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
+			}
+
+			archivePath, err := GetArchivePath(GetStorageOptions(c))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting archive path: %v", err), 2)
+			}
+
+			// A missing archive is created encrypted, so archived items are never written in plain text
+			for _, path := range []string{storagePath, archivePath} {
+				todoList, storage, err := initializeTodoListWithPath(path)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("failed to initialize %s: %v", path, err), 2)
+				}
+
+				if storage.encrypt == encryptOn {
+					fmt.Printf("%s: already encrypted\n", path)
+					continue
+				}
+
+				storage.encrypt = encryptOn
+				if err := storage.Save(*todoList); err != nil {
+					return cli.Exit(fmt.Sprintf("error saving %s: %v", path, err), 2)
+				}
+				fmt.Printf("%s: encrypted\n", path)
+			}
+
+			// Plain copies of the lists would defeat the encryption
+			removed, err := removePlainBackups(storagePath, archivePath)
+			if err != nil {
+				return cli.Exit(err.Error(), 2)
+			}
+			if removed > 0 {
+				fmt.Printf("Removed %d unencrypted backup(s)\n", removed)
+			}
+
+			journalPath, err := GetJournalPath(GetStorageOptions(c))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting journal path: %v", err), 2)
+			}
+			if err := os.Remove(journalPath); err == nil {
+				fmt.Println("Cleared the undo history, which held unencrypted content")
+			} else if !os.IsNotExist(err) {
+				return cli.Exit(fmt.Sprintf("error clearing undo history: %v", err), 2)
+			}
+
+			return nil
+		},
+	}
+}
+
+// removePlainBackups deletes the backups of the given files that aren't encrypted
+// and returns how many were removed
+func removePlainBackups(filenames ...string) (int, error) {
+	backups, err := listBackupsFor(filenames...)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, backup := range backups {
+		fileData, err := os.ReadFile(backup.Path)
+		if err != nil {
+			return removed, fmt.Errorf("error reading backup: %w", err)
+		}
+		if isEncryptedData(fileData) {
+			continue
+		}
+		if err := os.Remove(backup.Path); err != nil {
+			return removed, fmt.Errorf("error removing backup: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Legacy command struct for backward compatibility
+type EncryptCommand struct{}
+
+func init() {
+	RegisterCommand(&EncryptCommand{})
+}
+
+func (c *EncryptCommand) Name() string {
+	return "encrypt"
+}
+
+func (c *EncryptCommand) Description() string {
+	return "Encrypt the todo and archive files with a passphrase"
+}
+
+func (c *EncryptCommand) Usage() string {
+	return "todo-cli encrypt"
+}
+
+func (c *EncryptCommand) Execute(args []string, todoList TodoListInterface) error {
+	// Note: Legacy interface works on an already loaded list
+	return fmt.Errorf("encrypt functionality not supported in legacy interface")
+}
@@ -0,0 +1,240 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// Encrypted todo files are a JSON object holding the scrypt parameters, the AES-256-GCM
+// nonce and the sealed document. The header is authenticated along with the data.
+const (
+	encryptionCipher = "aes-256-gcm"
+	encryptionKDF    = "scrypt"
+
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// encryptedPrefix starts every encrypted file, so plain files are told apart without parsing them
+var encryptedPrefix = []byte(`{"encrypted":`)
+
+// encryptedFile is the on-disk form of an encrypted todo file
+type encryptedFile struct {
+	Encrypted string `json:"encrypted"`
+	KDF       string `json:"kdf"`
+	N         int    `json:"n"`
+	R         int    `json:"r"`
+	P         int    `json:"p"`
+	Salt      []byte `json:"salt"`
+	Nonce     []byte `json:"nonce"`
+	Data      []byte `json:"data"`
+}
+
+// additionalData authenticates the header fields of an encrypted file
+func (file encryptedFile) additionalData() []byte {
+	return fmt.Appendf(nil, "%s:%s:%d:%d:%d", file.Encrypted, file.KDF, file.N, file.R, file.P)
+}
+
+// encryptionKeyFile is the key file set with the key_file setting
+var encryptionKeyFile = ""
+
+// encryptionKeys caches the keys derived in this run by salt, so each file
+// doesn't pay for scrypt again; new files reuse the last salt
+var encryptionKeys = struct {
+	sync.Mutex
+	bySalt   map[string][]byte
+	lastSalt []byte
+}{bySalt: make(map[string][]byte)}
+
+// isEncryptedData reports whether stored data is an encrypted todo file. The undo
+// history stores files reformatted, so the check ignores whitespace.
+func isEncryptedData(fileData []byte) bool {
+	trimmed := bytes.TrimSpace(fileData)
+	if !bytes.HasPrefix(trimmed, []byte("{")) {
+		return false
+	}
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, trimmed); err != nil {
+		return false
+	}
+	return bytes.HasPrefix(compacted.Bytes(), encryptedPrefix)
+}
+
+// encryptData seals a document with a key derived from the passphrase
+func encryptData(plain []byte) ([]byte, error) {
+	encryptionKeys.Lock()
+	salt := encryptionKeys.lastSalt
+	encryptionKeys.Unlock()
+
+	if salt == nil {
+		salt = make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("error generating salt: %w", err)
+		}
+	}
+
+	file := encryptedFile{Encrypted: encryptionCipher, KDF: encryptionKDF, N: scryptN, R: scryptR, P: scryptP, Salt: salt}
+	aead, err := newEncryptionAEAD(file)
+	if err != nil {
+		return nil, err
+	}
+
+	file.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce: %w", err)
+	}
+	file.Data = aead.Seal(nil, file.Nonce, plain, file.additionalData())
+
+	fileData, err := json.Marshal(file)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling encrypted data: %w", err)
+	}
+	return append(fileData, '\n'), nil
+}
+
+// decryptData opens an encrypted todo file
+func decryptData(fileData []byte) ([]byte, error) {
+	var file encryptedFile
+	if err := json.Unmarshal(fileData, &file); err != nil {
+		return nil, fmt.Errorf("invalid encrypted file: %w", err)
+	}
+	if file.Encrypted != encryptionCipher || file.KDF != encryptionKDF {
+		return nil, fmt.Errorf("unsupported encryption: %s with %s", file.Encrypted, file.KDF)
+	}
+
+	aead, err := newEncryptionAEAD(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid encrypted file: bad nonce")
+	}
+
+	plain, err := aead.Open(nil, file.Nonce, file.Data, file.additionalData())
+	if err != nil {
+		return nil, errors.New("wrong passphrase or damaged file")
+	}
+	return plain, nil
+}
+
+// decodeTodoData returns the JSON document in stored data, decrypting it if needed
+func decodeTodoData(fileData []byte) ([]byte, error) {
+	if !isEncryptedData(fileData) {
+		return fileData, nil
+	}
+	return decryptData(fileData)
+}
+
+// newEncryptionAEAD derives the key for a file's parameters and returns its cipher
+func newEncryptionAEAD(file encryptedFile) (cipher.AEAD, error) {
+	key, err := encryptionKey(file)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptionKey derives (or reuses) the key for a file's salt and scrypt parameters
+func encryptionKey(file encryptedFile) ([]byte, error) {
+	encryptionKeys.Lock()
+	defer encryptionKeys.Unlock()
+
+	cacheKey := fmt.Sprintf("%x:%d:%d:%d", file.Salt, file.N, file.R, file.P)
+	if key, ok := encryptionKeys.bySalt[cacheKey]; ok {
+		encryptionKeys.lastSalt = file.Salt
+		return key, nil
+	}
+
+	passphrase, err := getPassphrase(len(encryptionKeys.bySalt) == 0 && file.Data == nil)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := scrypt.Key(passphrase, file.Salt, file.N, file.R, file.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving key: %w", err)
+	}
+	encryptionKeys.bySalt[cacheKey] = key
+	encryptionKeys.lastSalt = file.Salt
+	return key, nil
+}
+
+// passphraseOnce asks for the passphrase at most once per run
+var passphraseOnce struct {
+	sync.Once
+	passphrase []byte
+	err        error
+}
+
+// getPassphrase returns the passphrase from TODO_PASSPHRASE, the key file or a
+// terminal prompt. A new passphrase is asked for twice when prompting.
+func getPassphrase(confirm bool) ([]byte, error) {
+	passphraseOnce.Do(func() {
+		passphraseOnce.passphrase, passphraseOnce.err = readPassphrase(confirm)
+	})
+	return passphraseOnce.passphrase, passphraseOnce.err
+}
+
+// readPassphrase reads the passphrase from the first source that has one
+func readPassphrase(confirm bool) ([]byte, error) {
+	if passphrase := os.Getenv("TODO_PASSPHRASE"); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+
+	if encryptionKeyFile != "" {
+		fileData, err := os.ReadFile(encryptionKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading key file: %w", err)
+		}
+		passphrase := bytes.TrimRight(fileData, "\r\n")
+		if len(passphrase) == 0 {
+			return nil, fmt.Errorf("key file %s is empty", encryptionKeyFile)
+		}
+		return passphrase, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, errors.New("a passphrase is required: set TODO_PASSPHRASE or the key_file setting")
+	}
+
+	fmt.Fprint(os.Stderr, "Passphrase: ")
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("error reading passphrase: %w", err)
+	}
+	if len(strings.TrimSpace(string(passphrase))) == 0 {
+		return nil, errors.New("the passphrase must not be empty")
+	}
+
+	if confirm {
+		fmt.Fprint(os.Stderr, "Confirm passphrase: ")
+		again, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, fmt.Errorf("error reading passphrase: %w", err)
+		}
+		if !bytes.Equal(passphrase, again) {
+			return nil, errors.New("the passphrases don't match")
+		}
+	}
+
+	return passphrase, nil
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// useTestPassphrase sets the passphrase for one test and forgets any derived keys
+func useTestPassphrase(t *testing.T, passphrase string) {
+	t.Helper()
+
+	t.Setenv("TODO_PASSPHRASE", passphrase)
+	resetEncryptionKeys()
+	t.Cleanup(resetEncryptionKeys)
+}
+
+// resetEncryptionKeys makes the next encryption read the passphrase again
+func resetEncryptionKeys() {
+	encryptionKeys.Lock()
+	encryptionKeys.bySalt = make(map[string][]byte)
+	encryptionKeys.lastSalt = nil
+	encryptionKeys.Unlock()
+
+	passphraseOnce.Once = sync.Once{}
+	passphraseOnce.passphrase, passphraseOnce.err = nil, nil
+}
+
+func TestEncryptData_RoundTrip(t *testing.T) {
+	useTestPassphrase(t, "correct horse")
+
+	plain := []byte(`[{"task":"secret plans"}]`)
+	sealed, err := encryptData(plain)
+	if err != nil {
+		t.Fatalf("encryptData() error = %v", err)
+	}
+	if !isEncryptedData(sealed) || bytes.Contains(sealed, []byte("secret plans")) {
+		t.Fatalf("encryptData() = %s, want an encrypted file", sealed)
+	}
+
+	opened, err := decryptData(sealed)
+	if err != nil || !bytes.Equal(opened, plain) {
+		t.Errorf("decryptData() = %s, %v; want %s", opened, err, plain)
+	}
+
+	// Each save gets a new nonce
+	again, _ := encryptData(plain)
+	if bytes.Equal(again, sealed) {
+		t.Error("encryptData() should not repeat its output")
+	}
+}
+
+func TestDecryptData_WrongPassphrase(t *testing.T) {
+	useTestPassphrase(t, "correct horse")
+	sealed, err := encryptData([]byte(`[]`))
+	if err != nil {
+		t.Fatalf("encryptData() error = %v", err)
+	}
+
+	useTestPassphrase(t, "battery staple")
+	if _, err := decryptData(sealed); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("decryptData() error = %v, want a wrong passphrase error", err)
+	}
+
+	// A changed header is detected too
+	useTestPassphrase(t, "correct horse")
+	tampered := bytes.Replace(sealed, []byte(`"p":1`), []byte(`"p":2`), 1)
+	if _, err := decryptData(tampered); err == nil {
+		t.Error("decryptData() should reject a changed header")
+	}
+}
+
+func TestStorage_KeepsEncryption(t *testing.T) {
+	setupTestHome(t)
+	useTestPassphrase(t, "correct horse")
+	path := filepath.Join(t.TempDir(), "todos.json")
+
+	storage := NewStorage[TodoList](path)
+	storage.encrypt = encryptOn
+	if err := storage.Save(TodoList{{Task: "secret plans"}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// A new storage keeps the file encrypted without being told
+	todoList, storage, err := initializeTodoListWithPath(path)
+	if err != nil || len(*todoList) != 1 || (*todoList)[0].Task != "secret plans" {
+		t.Fatalf("initializeTodoListWithPath() = %v, %v", todoList, err)
+	}
+	if err := todoList.add("more plans"); err != nil {
+		t.Fatalf("add() error = %v", err)
+	}
+	if err := storage.Save(*todoList); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	fileData, _ := os.ReadFile(path)
+	if !isEncryptedData(fileData) || bytes.Contains(fileData, []byte("plans")) {
+		t.Errorf("saved file = %s, want it encrypted", fileData)
+	}
+
+	// Saving unchanged content leaves the file as it was
+	_, storage, _ = initializeTodoListWithPath(path)
+	loaded, _ := NewStorage[TodoList](path).Load()
+	if err := storage.Save(loaded); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if again, _ := os.ReadFile(path); !bytes.Equal(again, fileData) {
+		t.Error("saving unchanged content should not rewrite the encrypted file")
+	}
+
+	// decrypt switches it back
+	storage.encrypt = encryptOff
+	if err := storage.Save(loaded); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if fileData, _ := os.ReadFile(path); isEncryptedData(fileData) {
+		t.Errorf("saved file = %s, want it unencrypted", fileData)
+	}
+}
+
+func TestReadPassphrase_KeyFile(t *testing.T) {
+	t.Setenv("TODO_PASSPHRASE", "")
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte("from the key file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldKeyFile := encryptionKeyFile
+	encryptionKeyFile = keyFile
+	t.Cleanup(func() { encryptionKeyFile = oldKeyFile })
+
+	passphrase, err := readPassphrase(false)
+	if err != nil || string(passphrase) != "from the key file" {
+		t.Errorf("readPassphrase() = %q, %v; want the key file content", passphrase, err)
+	}
+
+	// The environment wins over the key file
+	t.Setenv("TODO_PASSPHRASE", "from the environment")
+	if passphrase, _ := readPassphrase(false); string(passphrase) != "from the environment" {
+		t.Errorf("readPassphrase() = %q, want TODO_PASSPHRASE", passphrase)
+	}
+
+	if err := os.WriteFile(keyFile, []byte("\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TODO_PASSPHRASE", "")
+	if _, err := readPassphrase(false); err == nil {
+		t.Error("readPassphrase() should reject an empty key file")
+	}
+}
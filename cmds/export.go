@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bennthewolfe/todo-cli/backend"
+	"github.com/urfave/cli/v3"
+)
+
+// AllowedExportFormats lists the formats accepted by export --format
+var AllowedExportFormats = []string{"json", "todotxt", "csv", "tsv", "ics", "taskwarrior"}
+
+// NewExportCommand creates a new export command for urfave/cli
+func NewExportCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "export",
+		Usage:     "Write the todo list (or the archive with --archive) to a file or stdout",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "Export format (json, todotxt, csv, tsv, ics, taskwarrior)",
+				Value:   "json",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Write to this file instead of stdout",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			// Validate archive flag usage
+			if err := ValidateArchiveFlagUsage(c, "export"); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			format := c.String("format")
+			if !containsString(AllowedExportFormats, format) {
+				return cli.Exit(fmt.Sprintf("invalid format: %s. Allowed formats: %s", format, strings.Join(AllowedExportFormats, ", ")), 1)
+			}
+
+			// New local lists are only created by 'todo init'
+			if err := RequireTodoFile(GetStorageOptions(c)); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// Get the appropriate storage path for the selected list and archive flag
+			storagePath, err := GetEffectiveStoragePath(GetStorageOptions(c), c.Bool("archive"))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
+			}
+
+			todoList, _, err := initializeTodoListWithPath(storagePath)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to initialize todo list: %v", err), 2)
+			}
+
+			fileData, err := encodeExport(*todoList, format)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error exporting todos: %v", err), 2)
+			}
+
+			output := c.String("output")
+			if output == "" {
+				os.Stdout.Write(fileData)
+				return nil
+			}
+
+			if err := backend.WriteFileAtomic(output, fileData, 0644); err != nil {
+				return cli.Exit(fmt.Sprintf("error writing %s: %v", output, err), 2)
+			}
+			fmt.Printf("Exported %d item(s) to %s\n", len(*todoList), output)
+
+			return nil
+		},
+	}
+}
+
+// encodeExport encodes a list in one of the export formats
+func encodeExport(todoList TodoList, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		fileData, err := json.MarshalIndent(todoList, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling data to JSON: %w", err)
+		}
+		return append(fileData, '\n'), nil
+	case "todotxt":
+		return encodeTodoTxt(todoList), nil
+	case "csv":
+		return encodeDelimited(todoList, listColumns, ',')
+	case "tsv":
+		return encodeDelimited(todoList, listColumns, '\t')
+	case "ics":
+		return encodeICS(todoList), nil
+	case "taskwarrior":
+		return encodeTaskwarrior(todoList)
+	default:
+		return nil, fmt.Errorf("unknown export format: %s", format)
+	}
+}
+
+// Legacy command struct for backward compatibility
+type ExportCommand struct{}
+
+func init() {
+	RegisterCommand(&ExportCommand{})
+}
+
+func (c *ExportCommand) Name() string {
+	return "export"
+}
+
+func (c *ExportCommand) Description() string {
+	return "Write the todo list to a file or stdout"
+}
+
+func (c *ExportCommand) Usage() string {
+	return "todo-cli export [--format <format>] [--output <file>]"
+}
+
+func (c *ExportCommand) Execute(args []string, todoList TodoListInterface) error {
+	// Note: Legacy interface works on an already loaded list
+	return fmt.Errorf("export functionality not supported in legacy interface")
+}
@@ -0,0 +1,488 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// iCalendar files (RFC 5545) hold each item as a VTODO:
+//
+//	BEGIN:VTODO
+//	UID:3f2a9c1b7d4e
+//	DTSTAMP:20250903T100000Z
+//	SUMMARY:Ship the release
+//	STATUS:COMPLETED
+//	PRIORITY:1
+//	DUE;VALUE=DATE:20250905
+//	CATEGORIES:work,@office
+//	END:VTODO
+//
+// Times are written in UTC. A due time at the end of a day is written as that date,
+// like the dates given to 'todo add --due'.
+const (
+	icsDateLayout     = "20060102"
+	icsDateTimeLayout = "20060102T150405"
+	icsUTCLayout      = "20060102T150405Z"
+
+	icsProductID = "-//bennthewolfe//todo-cli//EN"
+
+	// icsLineLimit is the longest content line in octets, not counting the line break
+	icsLineLimit = 75
+)
+
+// icsPriorities maps priorities to the values calendar apps show as high, medium and low
+var icsPriorities = map[string]int{
+	PriorityHigh:   1,
+	PriorityMedium: 5,
+	PriorityLow:    9,
+}
+
+// icsProperty is one unfolded content line: NAME;PARAM=value:value
+type icsProperty struct {
+	line   int
+	name   string
+	params map[string]string
+	value  string
+}
+
+// encodeICS writes a list as an iCalendar file with one VTODO per item
+func encodeICS(todoList TodoList) []byte {
+	var buffer bytes.Buffer
+	write := func(name, value string) {
+		buffer.WriteString(icsFold(name + ":" + value))
+	}
+
+	write("BEGIN", "VCALENDAR")
+	write("VERSION", "2.0")
+	write("PRODID", icsProductID)
+	for _, todo := range todoList {
+		write("BEGIN", "VTODO")
+		write("UID", icsEscape(todo.InternalID))
+
+		// DTSTAMP is required; without a METHOD it is the time the item last changed
+		stamp := todo.UpdatedAt
+		if stamp == "" {
+			stamp = todo.CreatedAt
+		}
+		if value, ok := icsTime(stamp); ok {
+			write("DTSTAMP", value)
+		}
+		if value, ok := icsTime(todo.CreatedAt); ok {
+			write("CREATED", value)
+		}
+		if value, ok := icsTime(todo.UpdatedAt); ok {
+			write("LAST-MODIFIED", value)
+		}
+
+		write("SUMMARY", icsEscape(todo.Task))
+		if todo.Completed {
+			write("STATUS", "COMPLETED")
+			if value, ok := icsTime(todo.CompletedAt); ok {
+				write("COMPLETED", value)
+			}
+		} else {
+			write("STATUS", "NEEDS-ACTION")
+		}
+		if priority, ok := icsPriorities[todo.Priority]; ok {
+			write("PRIORITY", strconv.Itoa(priority))
+		}
+		if todo.DueAt != "" {
+			if due, err := time.Parse(time.RFC3339, todo.DueAt); err == nil {
+				if local := due.In(time.Local); endOfDay(local).Equal(due) {
+					write("DUE;VALUE=DATE", local.Format(icsDateLayout))
+				} else {
+					write("DUE", due.UTC().Format(icsUTCLayout))
+				}
+			}
+		}
+		if len(todo.Tags) > 0 {
+			categories := make([]string, len(todo.Tags))
+			for index, tag := range todo.Tags {
+				categories[index] = icsEscape(tag)
+			}
+			write("CATEGORIES", strings.Join(categories, ","))
+		}
+		write("END", "VTODO")
+	}
+	write("END", "VCALENDAR")
+
+	return buffer.Bytes()
+}
+
+// icsTime converts a stored RFC3339 timestamp to a UTC date-time value
+func icsTime(timestamp string) (string, bool) {
+	parsed, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return "", false
+	}
+	return parsed.UTC().Format(icsUTCLayout), true
+}
+
+// icsEscape escapes a TEXT value
+func icsEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(text)
+}
+
+// icsUnescape reverses icsEscape; unknown escapes keep the escaped character
+func icsUnescape(text string) string {
+	if !strings.Contains(text, `\`) {
+		return text
+	}
+
+	var builder strings.Builder
+	for index := 0; index < len(text); index++ {
+		if text[index] != '\\' || index == len(text)-1 {
+			builder.WriteByte(text[index])
+			continue
+		}
+		index++
+		switch text[index] {
+		case 'n', 'N':
+			builder.WriteByte('\n')
+		default:
+			builder.WriteByte(text[index])
+		}
+	}
+	return builder.String()
+}
+
+// icsSplitList splits a list value on its unescaped commas and unescapes each part
+func icsSplitList(value string) []string {
+	var parts []string
+	start := 0
+	for index := 0; index < len(value); index++ {
+		switch value[index] {
+		case '\\':
+			index++
+		case ',':
+			parts = append(parts, icsUnescape(value[start:index]))
+			start = index + 1
+		}
+	}
+	return append(parts, icsUnescape(value[start:]))
+}
+
+// icsFold breaks a content line into lines of at most 75 octets, each continuation
+// starting with a space, without splitting a UTF-8 character, and ends it with CRLF
+func icsFold(line string) string {
+	var builder strings.Builder
+	limit := icsLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		builder.WriteString(line[:cut])
+		builder.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icsLineLimit - 1
+	}
+	builder.WriteString(line)
+	builder.WriteString("\r\n")
+	return builder.String()
+}
+
+// decodeICS reads the VTODOs of an iCalendar file. Items without a UID get a new
+// internal ID. Warnings name the components, properties and values that weren't imported.
+func decodeICS(fileData []byte) (TodoList, []string, error) {
+	properties, err := icsUnfold(fileData)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(properties) == 0 || properties[0].name != "BEGIN" || !strings.EqualFold(properties[0].value, "VCALENDAR") {
+		return nil, nil, fmt.Errorf("not an iCalendar file (no BEGIN:VCALENDAR)")
+	}
+
+	var todoList TodoList
+	var warnings []string
+	var todo *icsTodo
+	var components []string
+	skipped := make(map[string]int)
+	var skippedOrder []string
+	unmapped := make(map[string]bool)
+
+	for _, property := range properties {
+		switch property.name {
+		case "BEGIN":
+			component := strings.ToUpper(property.value)
+			components = append(components, component)
+			if component == "VTODO" && len(components) == 2 {
+				todo = &icsTodo{line: property.line}
+			} else if (len(components) == 2 && component != "VTIMEZONE") || (len(components) == 3 && todo != nil) {
+				// Events, journals and the alarms of a VTODO have no place in a list
+				if skipped[component] == 0 {
+					skippedOrder = append(skippedOrder, component)
+				}
+				skipped[component]++
+			}
+			continue
+		case "END":
+			component := strings.ToUpper(property.value)
+			if len(components) == 0 || components[len(components)-1] != component {
+				return nil, nil, fmt.Errorf("line %d: END:%s without a matching BEGIN", property.line, property.value)
+			}
+			components = components[:len(components)-1]
+			if component == "VTODO" && len(components) == 1 && todo != nil {
+				item, itemWarnings := todo.finish()
+				warnings = append(warnings, itemWarnings...)
+				if item.Task != "" {
+					todoList = append(todoList, item)
+				}
+				todo = nil
+			}
+			continue
+		}
+
+		// Only the properties of a VTODO itself are read, not those of its alarms
+		if todo == nil || len(components) != 2 {
+			continue
+		}
+		if !todo.set(property) && !unmapped[property.name] {
+			unmapped[property.name] = true
+			warnings = append(warnings, fmt.Sprintf("property %s not imported", property.name))
+		}
+	}
+	if len(components) > 0 {
+		return nil, nil, fmt.Errorf("BEGIN:%s is never ended", components[len(components)-1])
+	}
+
+	for _, component := range skippedOrder {
+		warnings = append(warnings, fmt.Sprintf("%d %s component(s) not imported", skipped[component], component))
+	}
+
+	return todoList, warnings, nil
+}
+
+// icsUnfold splits a file into content lines, joining folded lines, and parses each one
+func icsUnfold(fileData []byte) ([]icsProperty, error) {
+	type contentLine struct {
+		number int
+		text   string
+	}
+	var lines []contentLine
+
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(fileData, []byte("\xef\xbb\xbf"))))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	number := 0
+	for scanner.Scan() {
+		number++
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if text != "" && (text[0] == ' ' || text[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		lines = append(lines, contentLine{number, text})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	properties := make([]icsProperty, 0, len(lines))
+	for _, line := range lines {
+		property, err := parseICSLine(line.text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.number, err)
+		}
+		property.line = line.number
+		properties = append(properties, property)
+	}
+	return properties, nil
+}
+
+// parseICSLine parses NAME;PARAM=value;PARAM="quoted:value":value
+func parseICSLine(line string) (icsProperty, error) {
+	property := icsProperty{params: make(map[string]string)}
+
+	// The value starts at the first colon outside a quoted parameter value
+	quoted := false
+	colon := -1
+	for index := 0; index < len(line) && colon < 0; index++ {
+		switch line[index] {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				colon = index
+			}
+		}
+	}
+	if colon < 0 {
+		return property, fmt.Errorf("invalid content line: %s", line)
+	}
+	property.value = line[colon+1:]
+
+	parts := strings.Split(line[:colon], ";")
+	property.name = strings.ToUpper(strings.TrimSpace(parts[0]))
+	if property.name == "" {
+		return property, fmt.Errorf("invalid content line: %s", line)
+	}
+	for _, param := range parts[1:] {
+		name, value, _ := strings.Cut(param, "=")
+		property.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+	}
+	return property, nil
+}
+
+// icsTodo collects the properties of one VTODO
+type icsTodo struct {
+	line     int
+	todo     Todo
+	stamp    string
+	status   string
+	warnings []string
+}
+
+// set applies a property to the item and reports whether it is one that's imported
+func (item *icsTodo) set(property icsProperty) bool {
+	todo := &item.todo
+	var err error
+
+	switch property.name {
+	case "UID":
+		todo.InternalID = icsUnescape(property.value)
+	case "SUMMARY":
+		todo.Task = strings.TrimSpace(icsUnescape(property.value))
+	case "STATUS":
+		item.status = strings.ToUpper(property.value)
+	case "PRIORITY":
+		todo.Priority, err = parseICSPriority(property.value)
+	case "CATEGORIES":
+		for _, category := range icsSplitList(property.value) {
+			if strings.TrimSpace(category) == "" {
+				continue
+			}
+			tag, tagErr := NormalizeTag(strings.Join(strings.Fields(category), "-"))
+			if tagErr != nil {
+				item.warn(property.line, tagErr.Error())
+				continue
+			}
+			todo.Tags = appendUniqueTags(todo.Tags, tag)
+		}
+	case "DUE":
+		todo.DueAt, err = item.parseTime(property, true)
+	case "DTSTAMP":
+		item.stamp, err = item.parseTime(property, false)
+	case "CREATED":
+		todo.CreatedAt, err = item.parseTime(property, false)
+	case "LAST-MODIFIED":
+		todo.UpdatedAt, err = item.parseTime(property, false)
+	case "COMPLETED":
+		todo.CompletedAt, err = item.parseTime(property, false)
+	default:
+		return false
+	}
+
+	if err != nil {
+		item.warn(property.line, err.Error())
+	}
+	return true
+}
+
+// warn records a problem with one of the item's properties
+func (item *icsTodo) warn(line int, message string) {
+	item.warnings = append(item.warnings, fmt.Sprintf("line %d: %s", line, message))
+}
+
+// parseTime reads a DATE or DATE-TIME value into the stored format. Dates stand for
+// midnight local time, or the end of the day for due dates.
+func (item *icsTodo) parseTime(property icsProperty, due bool) (string, error) {
+	value := property.value
+	if property.params["VALUE"] == "DATE" || len(value) == len(icsDateLayout) {
+		parsed, err := time.ParseInLocation(icsDateLayout, value, time.Local)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s: %s", property.name, value)
+		}
+		if due {
+			parsed = endOfDay(parsed)
+		}
+		return parsed.Format(time.RFC3339), nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		parsed, err := time.Parse(icsUTCLayout, value)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s: %s", property.name, value)
+		}
+		return parsed.In(time.Local).Format(time.RFC3339), nil
+	}
+
+	// Times without a zone are in the named zone, or floating (local time)
+	location := time.Local
+	if zone := property.params["TZID"]; zone != "" {
+		if loaded, err := time.LoadLocation(strings.TrimPrefix(zone, "/")); err == nil {
+			location = loaded
+		} else {
+			item.warn(property.line, fmt.Sprintf("unknown time zone %s, read as local time", zone))
+		}
+	}
+	parsed, err := time.ParseInLocation(icsDateTimeLayout, value, location)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %s", property.name, value)
+	}
+	return parsed.In(time.Local).Format(time.RFC3339), nil
+}
+
+// finish fills in what the VTODO didn't give and returns the item with its warnings
+func (item *icsTodo) finish() (Todo, []string) {
+	todo := item.todo
+
+	if todo.Task == "" {
+		item.warnings = append(item.warnings, fmt.Sprintf("line %d: skipped, the VTODO has no summary", item.line))
+		return Todo{}, item.warnings
+	}
+
+	switch item.status {
+	case "", "NEEDS-ACTION", "IN-PROCESS":
+		todo.Completed = todo.CompletedAt != ""
+	case "COMPLETED":
+		todo.Completed = true
+	default:
+		item.warn(item.line, fmt.Sprintf("status %s imported as open", item.status))
+	}
+	if !todo.Completed {
+		todo.CompletedAt = ""
+	}
+
+	if todo.InternalID == "" {
+		todo.InternalID = generateShortGUID()
+	}
+	if todo.CreatedAt == "" {
+		todo.CreatedAt = item.stamp
+	}
+	if todo.CreatedAt == "" {
+		todo.CreatedAt = time.Now().Format(time.RFC3339)
+	}
+	if todo.UpdatedAt == "" {
+		todo.UpdatedAt = item.stamp
+	}
+	if todo.UpdatedAt == "" {
+		todo.UpdatedAt = todo.CreatedAt
+	}
+
+	return todo, item.warnings
+}
+
+// parseICSPriority maps 1-4 to high, 5 to medium and 6-9 to low; 0 is no priority
+func parseICSPriority(value string) (string, error) {
+	priority, err := strconv.Atoi(strings.TrimSpace(value))
+	switch {
+	case err != nil || priority < 0 || priority > 9:
+		return "", fmt.Errorf("invalid PRIORITY: %s", value)
+	case priority == 0:
+		return "", nil
+	case priority <= 4:
+		return PriorityHigh, nil
+	case priority == 5:
+		return PriorityMedium, nil
+	default:
+		return PriorityLow, nil
+	}
+}
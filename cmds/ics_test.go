@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// icsTestList is exported to testdata/export.ics
+func icsTestList() TodoList {
+	endOfDue := endOfDay(time.Date(2025, 9, 5, 0, 0, 0, 0, time.Local)).Format(time.RFC3339)
+
+	return TodoList{
+		{InternalID: "a1b2c3d4e5f6", Task: "Write docs", Priority: "high", Tags: []string{"docs", "@office"}, CreatedAt: "2025-09-01T10:00:00Z", UpdatedAt: "2025-09-02T10:00:00Z", DueAt: endOfDue},
+		{InternalID: "b2c3d4e5f6a1", Task: "Ship", Priority: "low", Completed: true, CreatedAt: "2025-09-01T12:00:00+02:00", UpdatedAt: "2025-09-03T10:00:00Z", CompletedAt: "2025-09-03T10:00:00Z", DueAt: "2025-09-05T12:30:00Z"},
+		{InternalID: "c3d4e5f6a1b2", Task: "Pack: tent, stove; the \\ key\nand a map", Priority: "medium", CreatedAt: "2025-09-04T08:00:00Z", UpdatedAt: "2025-09-04T08:00:00Z"},
+		{InternalID: "d4e5f6a1b2c3", Task: "Écrire la très longue note de synthèse pour l'équipe avant la réunion trimestrielle", CreatedAt: "2025-09-04T08:00:00Z", UpdatedAt: "2025-09-04T08:00:00Z"},
+	}
+}
+
+func TestEncodeICS_Golden(t *testing.T) {
+	got := encodeICS(icsTestList())
+
+	golden := filepath.Join("testdata", "export.ics")
+	if *updateGolden {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("reading golden file: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encodeICS() =\n%s\nwant (%s)\n%s", got, golden, want)
+	}
+}
+
+func TestExportImport_ICSRoundTrip(t *testing.T) {
+	todoList := icsTestList()
+
+	fileData, err := encodeExport(todoList, "ics")
+	if err != nil {
+		t.Fatalf("encodeExport() error = %v", err)
+	}
+
+	imported, warnings, err := decodeImport(fileData, "ics", nil)
+	if err != nil {
+		t.Fatalf("decodeImport() error = %v", err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("decodeImport() warnings = %v", warnings)
+	}
+
+	// Times come back in local time
+	for index := range todoList {
+		todo := &todoList[index]
+		for _, timestamp := range []*string{&todo.CreatedAt, &todo.UpdatedAt, &todo.CompletedAt, &todo.DueAt} {
+			if parsed, err := time.Parse(time.RFC3339, *timestamp); err == nil {
+				*timestamp = parsed.In(time.Local).Format(time.RFC3339)
+			}
+		}
+	}
+	if !reflect.DeepEqual(imported, todoList) {
+		t.Errorf("decodeImport() = %+v\nwant %+v\nfrom:\n%s", imported, todoList, fileData)
+	}
+}
+
+func TestICSFold(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("é", 100)
+	folded := icsFold(line)
+
+	if !strings.HasSuffix(folded, "\r\n") {
+		t.Fatalf("icsFold() = %q, want a CRLF ending", folded)
+	}
+	parts := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+	if len(parts) < 3 {
+		t.Fatalf("icsFold() = %d lines, want the line folded", len(parts))
+	}
+	for index, part := range parts {
+		if len(part) > icsLineLimit || !utf8.ValidString(part) {
+			t.Errorf("line %d = %q (%d octets), want at most %d octets of valid UTF-8", index, part, len(part), icsLineLimit)
+		}
+		if index > 0 && !strings.HasPrefix(part, " ") {
+			t.Errorf("line %d = %q, want a leading space", index, part)
+		}
+	}
+
+	properties, err := icsUnfold([]byte(folded))
+	if err != nil || len(properties) != 1 || properties[0].name+":"+properties[0].value != line {
+		t.Errorf("icsUnfold() = %+v, %v; want the original line", properties, err)
+	}
+}
+
+func TestICSEscape(t *testing.T) {
+	text := "a\\b; c, d\ne"
+	escaped := icsEscape(text)
+	if escaped != `a\\b\; c\, d\ne` {
+		t.Errorf("icsEscape() = %s", escaped)
+	}
+	if got := icsUnescape(escaped); got != text {
+		t.Errorf("icsUnescape() = %q, want %q", got, text)
+	}
+	if got := icsSplitList(`work,a\,b,x\\`); !reflect.DeepEqual(got, []string{"work", "a,b", `x\`}) {
+		t.Errorf("icsSplitList() = %q", got)
+	}
+}
+
+func TestDecodeICS_Calendar(t *testing.T) {
+	fileData, err := os.ReadFile(filepath.Join("testdata", "calendar.ics"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	todoList, warnings, err := decodeICS(fileData)
+	if err != nil {
+		t.Fatalf("decodeICS() error = %v", err)
+	}
+	if len(todoList) != 3 {
+		t.Fatalf("decodeICS() = %d items, want 3: %+v", len(todoList), todoList)
+	}
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	local := func(parsed time.Time) string { return parsed.In(time.Local).Format(time.RFC3339) }
+
+	want := TodoList{
+		{
+			InternalID: "20250901T080000Z-1234@calendar.example.com",
+			Task:       "Book flights to Lisbon, window seat; check baggage allowance before paying",
+			Priority:   PriorityHigh,
+			DueAt:      local(time.Date(2025, 9, 20, 18, 0, 0, 0, berlin)),
+			Tags:       []string{"travel", "family-trip"},
+			CreatedAt:  local(time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)),
+			UpdatedAt:  local(time.Date(2025, 9, 2, 9, 30, 0, 0, time.UTC)),
+		},
+		{
+			InternalID:  "renew-passport",
+			Task:        "Renew passport",
+			Priority:    PriorityLow,
+			DueAt:       endOfDay(time.Date(2025, 9, 30, 0, 0, 0, 0, time.Local)).Format(time.RFC3339),
+			Completed:   true,
+			CreatedAt:   local(time.Date(2025, 8, 1, 7, 0, 0, 0, time.UTC)),
+			UpdatedAt:   local(time.Date(2025, 8, 20, 7, 0, 0, 0, time.UTC)),
+			CompletedAt: local(time.Date(2025, 8, 20, 7, 0, 0, 0, time.UTC)),
+		},
+		{
+			InternalID: "cancelled-dinner",
+			Task:       "Cancel dinner reservation",
+			CreatedAt:  local(time.Date(2025, 9, 3, 12, 0, 0, 0, time.UTC)),
+			UpdatedAt:  local(time.Date(2025, 9, 3, 12, 0, 0, 0, time.UTC)),
+		},
+	}
+	if !reflect.DeepEqual(todoList, want) {
+		t.Errorf("decodeICS() = %+v\nwant %+v", todoList, want)
+	}
+
+	wantWarnings := []string{
+		"property DESCRIPTION not imported",
+		"line 40: status CANCELLED imported as open",
+		"line 46: skipped, the VTODO has no summary",
+		"1 VALARM component(s) not imported",
+		"1 VEVENT component(s) not imported",
+	}
+	if !reflect.DeepEqual(warnings, wantWarnings) {
+		t.Errorf("decodeICS() warnings = %q\nwant %q", warnings, wantWarnings)
+	}
+}
+
+func TestDecodeICS_Errors(t *testing.T) {
+	tests := map[string]string{
+		"not a calendar":  "BEGIN:VTODO\r\nEND:VTODO\r\n",
+		"unmatched end":   "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+		"never ended":     "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:Open\r\n",
+		"no colon":        "BEGIN:VCALENDAR\r\nSUMMARY\r\nEND:VCALENDAR\r\n",
+		"empty file":      "",
+		"only whitespace": "\r\n\r\n",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := decodeICS([]byte(input)); err == nil {
+				t.Errorf("decodeICS(%q) should fail", input)
+			}
+		})
+	}
+}
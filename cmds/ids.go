@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// minIDPrefixLength is the shortest internal ID prefix accepted, like git short hashes
+const minIDPrefixLength = 4
+
+var idPrefixPattern = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+
+// idListPattern matches arguments made only of display IDs, such as 3, 5-8 or 2,4
+var idListPattern = regexp.MustCompile(`^\d+(-\d+)?(,\d+(-\d+)?)*$`)
+
+// IDRange is an inclusive range of 1-based display IDs; a single ID has Start == End.
+// When Prefix is set the range instead names the item whose internal ID starts with it.
+type IDRange struct {
+	Start  int
+	End    int
+	Prefix string
+}
+
+// ParseIDs parses ID arguments made of single IDs ("3"), comma-separated
+// lists ("2,4"), ranges ("5-8") and internal ID prefixes ("3fa9", or "@1234"
+// for an all-digit prefix), e.g. `toggle 1 3 5-8`
+func ParseIDs(args []string) ([]IDRange, error) {
+	var ranges []IDRange
+
+	for _, arg := range args {
+		for _, part := range strings.Split(arg, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			idRange, err := parseIDRange(part)
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, idRange)
+		}
+	}
+
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("at least one ID is required")
+	}
+	return ranges, nil
+}
+
+// parseIDRange parses a single ID, a start-end range or an internal ID prefix
+func parseIDRange(value string) (IDRange, error) {
+	if prefix, ok := strings.CutPrefix(value, "@"); ok {
+		return parseIDPrefix(prefix)
+	}
+	if _, err := strconv.Atoi(value); err != nil && !strings.Contains(value, "-") && idPrefixPattern.MatchString(value) {
+		return parseIDPrefix(value)
+	}
+
+	start, end, isRange := strings.Cut(value, "-")
+	if !isRange || start == "" {
+		id, err := parseID(value)
+		return IDRange{Start: id, End: id}, err
+	}
+
+	startID, err := parseID(start)
+	if err != nil {
+		return IDRange{}, err
+	}
+	endID, err := parseID(end)
+	if err != nil {
+		return IDRange{}, err
+	}
+	if startID > endID {
+		return IDRange{}, fmt.Errorf("invalid ID range: %s (start is after end)", value)
+	}
+
+	return IDRange{Start: startID, End: endID}, nil
+}
+
+// parseIDPrefix validates an internal ID prefix
+func parseIDPrefix(prefix string) (IDRange, error) {
+	if len(prefix) < minIDPrefixLength || !idPrefixPattern.MatchString(prefix) {
+		return IDRange{}, fmt.Errorf("invalid ID: %s must be a number or an internal ID prefix of at least %d hex characters", prefix, minIDPrefixLength)
+	}
+	return IDRange{Prefix: strings.ToLower(prefix)}, nil
+}
+
+// parseID parses a single positive ID
+func parseID(value string) (int, error) {
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ID: %s must be a number or an internal ID prefix of at least %d hex characters", value, minIDPrefixLength)
+	}
+	if id <= 0 {
+		return 0, fmt.Errorf("ID must be greater than 0")
+	}
+	return id, nil
+}
+
+// ResolveIDs expands ranges into unique display IDs in ascending order. Every ID
+// is checked against the list as loaded, so nothing is changed unless all are valid.
+func ResolveIDs(ranges []IDRange, todoList TodoList) ([]int, error) {
+	seen := make(map[int]bool)
+	length := len(todoList)
+	var ids []int
+
+	for _, idRange := range ranges {
+		if idRange.Prefix != "" {
+			id, err := resolveIDPrefix(idRange.Prefix, todoList)
+			if err != nil {
+				return nil, err
+			}
+			idRange.Start, idRange.End = id, id
+		}
+
+		for _, id := range []int{idRange.Start, idRange.End} {
+			if id > length {
+				return nil, fmt.Errorf("invalid ID: %d (valid range: 1-%d)", id, length)
+			}
+		}
+
+		for id := idRange.Start; id <= idRange.End; id++ {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// resolveIDPrefix finds the display ID of the one item whose internal ID starts with prefix
+func resolveIDPrefix(prefix string, todoList TodoList) (int, error) {
+	var matches []int
+	for index, todo := range todoList {
+		if strings.HasPrefix(strings.ToLower(todo.InternalID), prefix) {
+			matches = append(matches, index+1)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return 0, fmt.Errorf("invalid ID: no todo item has an internal ID starting with %s", prefix)
+	case 1:
+		return matches[0], nil
+	default:
+		return 0, fmt.Errorf("ambiguous ID prefix: %s matches %d todo items (IDs %s)", prefix, len(matches), formatIDs(matches))
+	}
+}
+
+// formatIDs joins IDs for messages, e.g. "2, 4, 5"
+func formatIDs(ids []int) string {
+	parts := make([]string, len(ids))
+	for index, id := range ids {
+		parts[index] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// takeItems removes the items with the given 1-based IDs and returns them in ID order.
+// IDs refer to positions before the removal, so later IDs don't shift mid-operation.
+func (todoList *TodoList) takeItems(ids []int) ([]Todo, error) {
+	t := *todoList
+
+	selected := make(map[int]bool)
+	for _, id := range ids {
+		if id < 1 || id > len(t) {
+			return nil, fmt.Errorf("invalid ID: %d (valid range: 1-%d)", id, len(t))
+		}
+		selected[id] = true
+	}
+
+	taken := make([]Todo, 0, len(selected))
+	remaining := make(TodoList, 0, len(t)-len(selected))
+	for index, item := range t {
+		if selected[index+1] {
+			taken = append(taken, item)
+		} else {
+			remaining = append(remaining, item)
+		}
+	}
+
+	*todoList = remaining
+	return taken, nil
+}
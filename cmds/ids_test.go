@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseIDs(t *testing.T) {
+	ranges, err := ParseIDs([]string{"1", "3", "5-8", "2,4", "6,"})
+	if err != nil {
+		t.Fatalf("ParseIDs() error = %v", err)
+	}
+
+	want := []IDRange{{Start: 1, End: 1}, {Start: 3, End: 3}, {Start: 5, End: 8}, {Start: 2, End: 2}, {Start: 4, End: 4}, {Start: 6, End: 6}}
+	if !reflect.DeepEqual(ranges, want) {
+		t.Errorf("ParseIDs() = %v, want %v", ranges, want)
+	}
+}
+
+func TestParseIDs_Errors(t *testing.T) {
+	tests := map[string]struct {
+		args []string
+		want string
+	}{
+		"no ids":         {nil, "at least one ID is required"},
+		"only commas":    {[]string{","}, "at least one ID is required"},
+		"not a number":   {[]string{"abc"}, "invalid ID: abc must be a number"},
+		"zero":           {[]string{"0"}, "ID must be greater than 0"},
+		"negative":       {[]string{"-3"}, "ID must be greater than 0"},
+		"reversed range": {[]string{"8-5"}, "invalid ID range: 8-5"},
+		"open range":     {[]string{"5-"}, "invalid ID:  must be a number"},
+		"short prefix":   {[]string{"3fa"}, "at least 4 hex characters"},
+		"non-hex prefix": {[]string{"@xyz12"}, "invalid ID: xyz12"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseIDs(test.args)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("ParseIDs(%v) error = %v, want %q", test.args, err, test.want)
+			}
+		})
+	}
+}
+
+func TestParseIDs_Prefixes(t *testing.T) {
+	ranges, err := ParseIDs([]string{"3FA9", "@1234", "2"})
+	if err != nil {
+		t.Fatalf("ParseIDs() error = %v", err)
+	}
+
+	want := []IDRange{{Prefix: "3fa9"}, {Prefix: "1234"}, {Start: 2, End: 2}}
+	if !reflect.DeepEqual(ranges, want) {
+		t.Errorf("ParseIDs() = %v, want %v", ranges, want)
+	}
+}
+
+func TestResolveIDs(t *testing.T) {
+	todoList := make(TodoList, 6)
+	ids, err := ResolveIDs([]IDRange{{Start: 5, End: 6}, {Start: 1, End: 1}, {Start: 2, End: 5}}, todoList)
+	if err != nil {
+		t.Fatalf("ResolveIDs() error = %v", err)
+	}
+	if want := []int{1, 2, 3, 4, 5, 6}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ResolveIDs() = %v, want %v", ids, want)
+	}
+
+	if _, err := ResolveIDs([]IDRange{{Start: 1, End: 1}, {Start: 3, End: 9}}, todoList[:4]); err == nil || err.Error() != "invalid ID: 9 (valid range: 1-4)" {
+		t.Errorf("ResolveIDs() error = %v, want out of range error", err)
+	}
+}
+
+func TestResolveIDs_Prefixes(t *testing.T) {
+	todoList := TodoList{
+		{InternalID: "3fa9c0d1e2f3", Task: "one"},
+		{InternalID: "3fb1aa000000", Task: "two"},
+		{InternalID: "9c2e77e1d0aa", Task: "three"},
+	}
+
+	ids, err := ResolveIDs([]IDRange{{Prefix: "9c2e"}, {Prefix: "3fa9"}, {Start: 3, End: 3}}, todoList)
+	if err != nil {
+		t.Fatalf("ResolveIDs() error = %v", err)
+	}
+	if want := []int{1, 3}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ResolveIDs() = %v, want %v", ids, want)
+	}
+
+	if _, err := ResolveIDs([]IDRange{{Prefix: "3f"}}, todoList); err == nil || !strings.Contains(err.Error(), "ambiguous ID prefix: 3f matches 2 todo items (IDs 1, 2)") {
+		t.Errorf("ResolveIDs() error = %v, want ambiguous prefix error", err)
+	}
+	if _, err := ResolveIDs([]IDRange{{Prefix: "ffff"}}, todoList); err == nil || !strings.Contains(err.Error(), "no todo item has an internal ID starting with ffff") {
+		t.Errorf("ResolveIDs() error = %v, want unknown prefix error", err)
+	}
+}
+
+func TestTakeItems(t *testing.T) {
+	todoList := TodoList{{Task: "one"}, {Task: "two"}, {Task: "three"}, {Task: "four"}}
+
+	taken, err := todoList.takeItems([]int{4, 2})
+	if err != nil {
+		t.Fatalf("takeItems() error = %v", err)
+	}
+	if len(taken) != 2 || taken[0].Task != "two" || taken[1].Task != "four" {
+		t.Errorf("takeItems() = %v, want two and four", taken)
+	}
+	if len(todoList) != 2 || todoList[0].Task != "one" || todoList[1].Task != "three" {
+		t.Errorf("remaining list = %v, want one and three", todoList)
+	}
+
+	if _, err := todoList.takeItems([]int{1, 3}); err == nil {
+		t.Errorf("takeItems() should reject an out of range ID")
+	}
+	if len(todoList) != 2 {
+		t.Errorf("a failed takeItems() should leave the list unchanged, got %v", todoList)
+	}
+}
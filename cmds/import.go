@@ -0,0 +1,216 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// AllowedImportFormats lists the formats accepted by import --from
+var AllowedImportFormats = []string{"json", "todotxt", "csv", "tsv", "ics", "taskwarrior"}
+
+// importFormatsByExtension picks the import format when --from isn't given
+var importFormatsByExtension = map[string]string{
+	".json": "json",
+	".txt":  "todotxt",
+	".csv":  "csv",
+	".tsv":  "tsv",
+	".ics":  "ics",
+}
+
+// NewImportCommand creates a new import command for urfave/cli
+func NewImportCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "import",
+		Usage:     "Add the items of a file to the todo list (or the archive with --archive)",
+		ArgsUsage: "<file>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "Format of the file (json, todotxt, csv, tsv, ics, taskwarrior); defaults to the file extension",
+			},
+			&cli.StringFlag{
+				Name:  "map",
+				Usage: "Columns holding each field in csv and tsv files (e.g. task=Title,completed=Done)",
+			},
+			&cli.BoolFlag{
+				Name:  "replace",
+				Usage: "Replace the list with the imported items instead of adding them",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			// Validate archive flag usage
+			if err := ValidateArchiveFlagUsage(c, "import"); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			if c.Args().Len() != 1 {
+				return cli.Exit("a file to import is required", 1)
+			}
+			file := c.Args().First()
+
+			format := c.String("from")
+			if format == "" {
+				format = importFormatFor(file)
+			}
+			if !containsString(AllowedImportFormats, format) {
+				return cli.Exit(fmt.Sprintf("invalid import format: %s. Allowed formats: %s", format, strings.Join(AllowedImportFormats, ", ")), 1)
+			}
+
+			var columnMap map[string]string
+			if c.IsSet("map") {
+				if format != "csv" && format != "tsv" {
+					return cli.Exit("--map is only supported with csv and tsv files", 1)
+				}
+				parsed, err := ParseColumnMap(c.String("map"))
+				if err != nil {
+					return cli.Exit(err.Error(), 1)
+				}
+				columnMap = parsed
+			}
+
+			fileData, err := os.ReadFile(file)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error reading %s: %v", file, err), 1)
+			}
+
+			imported, warnings, err := decodeImport(fileData, format, columnMap)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("invalid %s file %s: %v", format, file, err), 1)
+			}
+
+			// New local lists are only created by 'todo init'
+			if err := RequireTodoFile(GetStorageOptions(c)); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// Hold the list lock until the list is saved
+			lock, err := lockTodoList(c)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to acquire lock: %v", err), 2)
+			}
+			defer lock.Unlock()
+
+			// Get the appropriate storage path for the selected list and archive flag
+			storagePath, err := GetEffectiveStoragePath(GetStorageOptions(c), c.Bool("archive"))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
+			}
+
+			todoList, storage, err := initializeTodoListWithPath(storagePath)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to initialize todo list: %v", err), 2)
+			}
+
+			// Items already in the list (by internal ID) are left alone
+			var added []Todo
+			skipped := 0
+			if c.Bool("replace") {
+				*todoList = TodoList{}
+				added = imported
+			} else {
+				existing := make(map[string]bool)
+				for _, todo := range *todoList {
+					existing[todo.InternalID] = true
+				}
+
+				for _, todo := range imported {
+					if existing[todo.InternalID] {
+						skipped++
+						continue
+					}
+					existing[todo.InternalID] = true
+					added = append(added, todo)
+				}
+			}
+			appendMovedItems(todoList, added)
+
+			if err := storage.Save(*todoList); err != nil {
+				return cli.Exit(fmt.Sprintf("error saving todos: %v", err), 2)
+			}
+
+			// Record the change so it can be undone
+			if err := recordJournalEntry(c, storage); err != nil {
+				return cli.Exit(fmt.Sprintf("error recording undo history: %v", err), 2)
+			}
+
+			for _, warning := range warnings {
+				fmt.Printf("  ! %s\n", warning)
+			}
+			fmt.Printf("Imported %d item(s) from %s\n", len(added), file)
+			if skipped > 0 {
+				fmt.Printf("Skipped %d item(s) already in the list\n", skipped)
+			}
+
+			// Check if --list flag is set and execute list command after import
+			if CheckAndExecuteListFlag(c) {
+				if err := ExecuteListCommand(c); err != nil {
+					return cli.Exit(fmt.Sprintf("error executing list: %v", err), 2)
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+// importFormatFor guesses the import format from a file's extension, defaulting to json
+func importFormatFor(file string) string {
+	if format, ok := importFormatsByExtension[strings.ToLower(filepath.Ext(file))]; ok {
+		return format
+	}
+	return "json"
+}
+
+// decodeImport parses a file in one of the import formats; columnMap names the columns
+// of csv and tsv files. Warnings describe data that couldn't be carried over.
+func decodeImport(fileData []byte, format string, columnMap map[string]string) (TodoList, []string, error) {
+	switch format {
+	case "json":
+		todoList, report, err := migrateTodoData(fileData)
+		if err != nil {
+			return nil, nil, err
+		}
+		return todoList, report.Dropped, nil
+	case "todotxt":
+		return decodeTodoTxt(fileData)
+	case "csv":
+		return decodeDelimited(fileData, ',', columnMap)
+	case "tsv":
+		return decodeDelimited(fileData, '\t', columnMap)
+	case "ics":
+		return decodeICS(fileData)
+	case "taskwarrior":
+		return decodeTaskwarrior(fileData)
+	default:
+		return nil, nil, fmt.Errorf("unknown import format: %s", format)
+	}
+}
+
+// Legacy command struct for backward compatibility
+type ImportCommand struct{}
+
+func init() {
+	RegisterCommand(&ImportCommand{})
+}
+
+func (c *ImportCommand) Name() string {
+	return "import"
+}
+
+func (c *ImportCommand) Description() string {
+	return "Add the items of a file to the todo list"
+}
+
+func (c *ImportCommand) Usage() string {
+	return "todo-cli import <file> [--from <format>] [--map <field=column,...>] [--replace]"
+}
+
+func (c *ImportCommand) Execute(args []string, todoList TodoListInterface) error {
+	// Note: Legacy interface works on an already loaded list
+	return fmt.Errorf("import functionality not supported in legacy interface")
+}
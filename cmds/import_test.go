@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImportFormatFor(t *testing.T) {
+	tests := map[string]string{
+		"todos.json":   "json",
+		"TODOS.JSON":   "json",
+		"todos":        "json",
+		"backup.jsonl": "json",
+		"todo.txt":     "todotxt",
+		"DONE.TXT":     "todotxt",
+		"Calendar.ics": "ics",
+	}
+	for file, want := range tests {
+		if got := importFormatFor(file); got != want {
+			t.Errorf("importFormatFor(%q) = %s, want %s", file, got, want)
+		}
+	}
+}
+
+func TestExportImport_JSONRoundTrip(t *testing.T) {
+	todoList := TodoList{
+		{InternalID: "a1b2c3d4e5f6", Task: "Write docs", Priority: "high", Tags: []string{"docs"}, CreatedAt: "2025-09-01T10:00:00Z", UpdatedAt: "2025-09-02T10:00:00Z"},
+		{InternalID: "b2c3d4e5f6a1", Task: "Ship", Completed: true, CreatedAt: "2025-09-01T10:00:00Z", UpdatedAt: "2025-09-03T10:00:00Z", CompletedAt: "2025-09-03T10:00:00Z", DueAt: "2025-09-05T00:00:00Z"},
+	}
+
+	fileData, err := encodeExport(todoList, "json")
+	if err != nil {
+		t.Fatalf("encodeExport() error = %v", err)
+	}
+
+	imported, warnings, err := decodeImport(fileData, "json", nil)
+	if err != nil {
+		t.Fatalf("decodeImport() error = %v", err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("decodeImport() warnings = %v", warnings)
+	}
+	if !reflect.DeepEqual(imported, todoList) {
+		t.Errorf("decodeImport() = %+v, want %+v", imported, todoList)
+	}
+}
+
+func TestDecodeImport_JSONWarnings(t *testing.T) {
+	imported, warnings, err := decodeImport([]byte(`[{"internal_id":"a1","task":"Legacy","estimate":"2h"}]`), "json", nil)
+	if err != nil {
+		t.Fatalf("decodeImport() error = %v", err)
+	}
+	if len(imported) != 1 || len(warnings) != 1 {
+		t.Errorf("decodeImport() = %+v, warnings %v; want one item and one dropped field", imported, warnings)
+	}
+}
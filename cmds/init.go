@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bennthewolfe/todo-cli/backend"
+	"github.com/urfave/cli/v3"
+)
+
+// NewInitCommand creates a new init command for urfave/cli
+func NewInitCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "init",
+		Usage:     "Create an empty .todos.json and a project config (.todo.toml) in the current directory",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "config-format",
+				Usage: "Format of the project config file (toml, json)",
+				Value: "toml",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			// Validate archive flag usage
+			if err := ValidateArchiveFlagUsage(c, "init"); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			configPath := projectConfigTOML
+			switch c.String("config-format") {
+			case "toml":
+			case "json":
+				configPath = projectConfigJSON
+			default:
+				return cli.Exit(fmt.Sprintf("invalid config format: %s. Allowed formats: toml, json", c.String("config-format")), 1)
+			}
+
+			// Keep an existing config in either format
+			for _, existing := range []string{projectConfigTOML, projectConfigJSON} {
+				if _, err := os.Stat(existing); err == nil {
+					configPath = ""
+				}
+			}
+
+			createList := !backend.Exists(storageBackend, localStorageFile)
+			if !createList && configPath == "" {
+				return cli.Exit(fmt.Sprintf("already initialized: %s and its config already exist in this directory", localStorageFile), 1)
+			}
+
+			// Mention the list this one will shadow for commands run from here
+			parentPath, found, err := findLocalStoragePath()
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
+			}
+
+			if createList {
+				if err := NewStorage[TodoList](localStorageFile).Save(TodoList{}); err != nil {
+					return cli.Exit(fmt.Sprintf("error creating %s: %v", localStorageFile, err), 2)
+				}
+				fmt.Printf("Initialized empty todo list in %s\n", backend.FilePath(storageBackend, localStorageFile))
+				if found {
+					fmt.Printf("Commands run from here now use it instead of %s\n", parentPath)
+				}
+			}
+
+			if configPath != "" {
+				if err := writeProjectConfig(configPath); err != nil {
+					return cli.Exit(fmt.Sprintf("error creating %s: %v", configPath, err), 2)
+				}
+				fmt.Printf("Created project config %s\n", configPath)
+			}
+
+			return nil
+		},
+	}
+}
+
+// Legacy command struct for backward compatibility
+type InitCommand struct{}
+
+func init() {
+	RegisterCommand(&InitCommand{})
+}
+
+func (c *InitCommand) Name() string {
+	return "init"
+}
+
+func (c *InitCommand) Description() string {
+	return "Create an empty .todos.json and a project config in the current directory"
+}
+
+func (c *InitCommand) Usage() string {
+	return "todo-cli init"
+}
+
+func (c *InitCommand) Execute(args []string, todoList TodoListInterface) error {
+	// Note: Legacy interface works on an already loaded list
+	return fmt.Errorf("init functionality not supported in legacy interface")
+}
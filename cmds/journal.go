@@ -0,0 +1,435 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bennthewolfe/todo-cli/backend"
+	"github.com/urfave/cli/v3"
+)
+
+// maxJournalEntries caps the undo history kept next to each todo file
+const maxJournalEntries = 100
+
+// maxJournalBytes caps the size of the undo history as well, since entries hold whole
+// files and every command rewrites the journal. The newest entry is always kept.
+const maxJournalBytes = 1 << 20
+
+// Journal is the undo/redo history of a todo list.
+// Entries before Position are applied and can be undone; the rest can be redone.
+type Journal struct {
+	Entries  []JournalEntry `json:"entries"`
+	Position int            `json:"position"`
+}
+
+// JournalEntry records the file changes made by a single mutating command
+type JournalEntry struct {
+	Command   string          `json:"command"`
+	Timestamp string          `json:"timestamp"`
+	Changes   []JournalChange `json:"changes"`
+}
+
+// JournalChange holds the content of one file before and after a command. Items added
+// to a row backend without loading the file are recorded as Appended instead.
+type JournalChange struct {
+	File     string          `json:"file"`
+	Backend  string          `json:"backend,omitempty"` // backend the file is kept in, json when empty
+	Before   json.RawMessage `json:"before,omitempty"`
+	After    json.RawMessage `json:"after,omitempty"`
+	Appended json.RawMessage `json:"appended,omitempty"`
+
+	// Encrypted is set for plain files written alongside an encrypted list; their
+	// Before and After content is kept encrypted and decrypted to apply the change
+	Encrypted bool `json:"encrypted,omitempty"`
+}
+
+// GetJournalPath returns the undo journal path that sits next to the todo file
+func GetJournalPath(opts StorageOptions) (string, error) {
+	storagePath, err := GetStoragePath(opts)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(storagePath, ".json") + ".journal.json", nil
+}
+
+// recordJournalEntry records the changes saved through the given storages as one undoable entry
+func recordJournalEntry(c *cli.Command, storages ...*Storage[TodoList]) error {
+	return recordJournalFiles(c, nil, storages...)
+}
+
+// recordJournalFiles records the changes saved through the given storages together with
+// changes to plain files written by the same command (see fileJournalChange)
+func recordJournalFiles(c *cli.Command, files []JournalChange, storages ...*Storage[TodoList]) error {
+	var changes []JournalChange
+	encrypted := false
+	for _, storage := range storages {
+		if storage == nil {
+			continue
+		}
+		if isEncryptedData(storage.loaded) || isEncryptedData(storage.saved) {
+			encrypted = true
+		}
+
+		file, err := filepath.Abs(storage.filename)
+		if err != nil {
+			return fmt.Errorf("unable to resolve %s: %w", storage.filename, err)
+		}
+
+		if storage.appended != nil {
+			changes = append(changes, JournalChange{File: file, Backend: storage.kind, Appended: storage.appended})
+			continue
+		}
+		if storage.saved == nil || sameJournalContent(journalContent(storage.loaded), journalContent(storage.saved)) {
+			continue
+		}
+
+		changes = append(changes, JournalChange{
+			File:    file,
+			Backend: storage.kind,
+			Before:  journalContent(storage.loaded),
+			After:   journalContent(storage.saved),
+		})
+	}
+
+	for _, change := range files {
+		if sameJournalContent(change.Before, change.After) {
+			continue
+		}
+
+		// The content of an encrypted list's files isn't kept in the clear
+		if encrypted {
+			var err error
+			if change, err = encryptJournalChange(change); err != nil {
+				return err
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	journalPath, err := GetJournalPath(GetStorageOptions(c))
+	if err != nil {
+		return err
+	}
+
+	journalStorage := NewStorage[Journal](journalPath)
+	journal, err := journalStorage.Load()
+	if err != nil {
+		return fmt.Errorf("error loading journal: %w", err)
+	}
+	journal.clampPosition()
+
+	// A new mutation discards anything that could have been redone
+	journal.Entries = append(journal.Entries[:journal.Position], JournalEntry{
+		Command:   journalCommand(c, encrypted),
+		Timestamp: time.Now().Format(time.RFC3339),
+		Changes:   changes,
+	})
+	journal.trim()
+	journal.Position = len(journal.Entries)
+
+	return journalStorage.Save(journal)
+}
+
+// journalCommand describes the command of an entry. The arguments of a command on an
+// encrypted list can hold task text, which must not be kept unencrypted in the journal,
+// so only the command name and its ID arguments are recorded for it.
+func journalCommand(c *cli.Command, encrypted bool) string {
+	args := c.Args().Slice()
+	if encrypted {
+		var ids []string
+		for _, arg := range args {
+			if idListPattern.MatchString(arg) {
+				ids = append(ids, arg)
+			}
+		}
+		args = ids
+	}
+	return strings.TrimSpace(c.Name + " " + strings.Join(args, " "))
+}
+
+// trim drops the oldest entries beyond maxJournalEntries or maxJournalBytes
+func (journal *Journal) trim() {
+	if len(journal.Entries) > maxJournalEntries {
+		journal.Entries = journal.Entries[len(journal.Entries)-maxJournalEntries:]
+	}
+
+	size, keep := 0, len(journal.Entries)
+	for keep > 0 {
+		encoded, err := json.Marshal(journal.Entries[keep-1])
+		if err == nil && size+len(encoded) > maxJournalBytes && keep < len(journal.Entries) {
+			break
+		}
+		size += len(encoded)
+		keep--
+	}
+	journal.Entries = journal.Entries[keep:]
+}
+
+// clampPosition keeps a hand-edited position within the recorded entries
+func (journal *Journal) clampPosition() {
+	if journal.Position < 0 || journal.Position > len(journal.Entries) {
+		journal.Position = len(journal.Entries)
+	}
+}
+
+// fileJournalChange records a change to a plain file, such as a synced markdown checklist
+func fileJournalChange(filename string, before, after []byte) (JournalChange, error) {
+	file, err := filepath.Abs(filename)
+	if err != nil {
+		return JournalChange{}, fmt.Errorf("unable to resolve %s: %w", filename, err)
+	}
+	return JournalChange{File: file, Before: journalContent(before), After: journalContent(after)}, nil
+}
+
+// encryptJournalChange encrypts the recorded content of a plain file change
+func encryptJournalChange(change JournalChange) (JournalChange, error) {
+	for _, content := range []*json.RawMessage{&change.Before, &change.After} {
+		if string(*content) == "null" {
+			continue
+		}
+		sealed, err := encryptData(journalFileData(*content))
+		if err != nil {
+			return change, fmt.Errorf("error encrypting undo history: %w", err)
+		}
+		*content = journalContent(sealed)
+	}
+	change.Encrypted = true
+	return change, nil
+}
+
+// decryptJournalChange returns a plain file change with its recorded content decrypted
+func decryptJournalChange(change JournalChange) (JournalChange, error) {
+	for _, content := range []*json.RawMessage{&change.Before, &change.After} {
+		if string(*content) == "null" {
+			continue
+		}
+		plain, err := decryptData(journalFileData(*content))
+		if err != nil {
+			return change, fmt.Errorf("unable to decrypt the undo history of %s: %w", change.File, err)
+		}
+		*content = journalContent(plain)
+	}
+	change.Encrypted = false
+	return change, nil
+}
+
+// journalContent converts raw file content into a JSON value, treating an empty file as
+// null. Content that isn't a JSON document (a damaged todo file repaired by doctor, or a
+// plain file such as a markdown checklist) is kept as a string.
+func journalContent(fileData []byte) json.RawMessage {
+	if len(bytes.TrimSpace(fileData)) == 0 {
+		return json.RawMessage("null")
+	}
+	if !json.Valid(fileData) || bytes.HasPrefix(bytes.TrimSpace(fileData), []byte(`"`)) {
+		quoted, _ := json.Marshal(string(fileData))
+		return json.RawMessage(quoted)
+	}
+	return json.RawMessage(fileData)
+}
+
+// journalFileData converts a recorded JSON value back into file content
+func journalFileData(content json.RawMessage) []byte {
+	var damaged string
+	switch {
+	case string(content) == "null":
+		return nil
+	case json.Unmarshal(content, &damaged) == nil:
+		return []byte(damaged)
+	}
+	return []byte(content)
+}
+
+// applyJournalChanges restores each file to one side of its recorded change.
+// Unless force is set, every file must still hold the content on the other side.
+func applyJournalChanges(changes []JournalChange, undo, force bool) error {
+	// Decrypted content is only used here, never written back to the journal
+	changes = append([]JournalChange(nil), changes...)
+
+	stores := make([]backend.Backend, len(changes))
+	for index, change := range changes {
+		if change.Encrypted {
+			var err error
+			if change, err = decryptJournalChange(change); err != nil {
+				return err
+			}
+			changes[index] = change
+		}
+
+		store, err := backend.Open(change.Backend, change.File)
+		if err != nil {
+			return fmt.Errorf("error opening %s: %w", change.File, err)
+		}
+		stores[index] = store
+	}
+
+	// Check all files before writing any of them
+	if !force {
+		for index, change := range changes {
+			if change.Appended != nil {
+				if err := checkAppendedChange(change, stores[index], undo); err != nil {
+					return err
+				}
+				continue
+			}
+
+			expected := change.After
+			if !undo {
+				expected = change.Before
+			}
+
+			current, err := stores[index].Load()
+			if err != nil {
+				return fmt.Errorf("error reading %s: %w", change.File, err)
+			}
+
+			if !sameJournalContent(journalContent(current), expected) {
+				return fmt.Errorf("%s has changed since this entry was recorded (use --force to overwrite it)", change.File)
+			}
+		}
+	}
+
+	for index, change := range changes {
+		if change.Appended != nil {
+			if err := applyAppendedChange(change, stores[index], undo); err != nil {
+				return fmt.Errorf("error writing %s: %w", change.File, err)
+			}
+			continue
+		}
+
+		content, expected := change.Before, change.After
+		if !undo {
+			content, expected = change.After, change.Before
+		}
+
+		fileData := journalFileData(content)
+
+		// Check again while writing, for backends that can do both in one step
+		err := backend.Update(stores[index], func(current []byte) ([]byte, error) {
+			if !force && !sameJournalContent(journalContent(current), expected) {
+				return nil, fmt.Errorf("%s has changed since this entry was recorded (use --force to overwrite it)", change.File)
+			}
+			return fileData, nil
+		})
+		if err != nil {
+			return fmt.Errorf("error writing %s: %w", change.File, err)
+		}
+	}
+
+	return nil
+}
+
+// appendedItems returns the row backend and the internal IDs of an appended change
+func appendedItems(change JournalChange, store backend.Backend) (backend.RowStore, []string, error) {
+	rows, isRowStore := store.(backend.RowStore)
+	if !isRowStore {
+		return nil, nil, fmt.Errorf("%s is not kept in a row backend (%s)", change.File, change.Backend)
+	}
+
+	items, _, err := migrateTodoData(change.Appended)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid journal entry for %s: %w", change.File, err)
+	}
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.InternalID)
+	}
+	return rows, ids, nil
+}
+
+// checkAppendedChange verifies that the appended items are all still there (undo)
+// or all gone (redo)
+func checkAppendedChange(change JournalChange, store backend.Backend, undo bool) error {
+	rows, ids, err := appendedItems(change, store)
+	if err != nil {
+		return err
+	}
+
+	found, err := rows.Query(backend.Query{IDs: ids})
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", change.File, err)
+	}
+
+	expected := 0
+	if undo {
+		expected = len(ids)
+	}
+	if len(found) != expected {
+		return fmt.Errorf("%s has changed since this entry was recorded (use --force to overwrite it)", change.File)
+	}
+	return nil
+}
+
+// applyAppendedChange removes the appended items again (undo) or re-adds them (redo)
+func applyAppendedChange(change JournalChange, store backend.Backend, undo bool) error {
+	rows, ids, err := appendedItems(change, store)
+	if err != nil {
+		return err
+	}
+
+	// Removing first also lets a forced redo replace items that are still there
+	if err := rows.Remove(ids); err != nil || undo {
+		return err
+	}
+	return rows.Append(change.Appended)
+}
+
+// sameJournalContent compares two JSON documents ignoring formatting
+func sameJournalContent(a, b json.RawMessage) bool {
+	var bufferA, bufferB bytes.Buffer
+	if json.Compact(&bufferA, a) != nil || json.Compact(&bufferB, b) != nil {
+		return bytes.Equal(bytes.TrimSpace(a), bytes.TrimSpace(b))
+	}
+	return bytes.Equal(bufferA.Bytes(), bufferB.Bytes())
+}
+
+// stepJournal undoes (or redoes) the next journal entry and returns it
+func stepJournal(opts StorageOptions, undo, force bool) (*JournalEntry, error) {
+	journalPath, err := GetJournalPath(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	journalStorage := NewStorage[Journal](journalPath)
+	journal, err := journalStorage.Load()
+	if err != nil {
+		return nil, fmt.Errorf("error loading journal: %w", err)
+	}
+	journal.clampPosition()
+
+	var entry JournalEntry
+	if undo {
+		if journal.Position == 0 {
+			return nil, nil
+		}
+		entry = journal.Entries[journal.Position-1]
+	} else {
+		if journal.Position >= len(journal.Entries) {
+			return nil, nil
+		}
+		entry = journal.Entries[journal.Position]
+	}
+
+	if err := applyJournalChanges(entry.Changes, undo, force); err != nil {
+		return nil, err
+	}
+
+	if undo {
+		journal.Position--
+	} else {
+		journal.Position++
+	}
+
+	if err := journalStorage.Save(journal); err != nil {
+		return nil, fmt.Errorf("error saving journal: %w", err)
+	}
+
+	return &entry, nil
+}
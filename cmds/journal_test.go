@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bennthewolfe/todo-cli/backend"
+)
+
+func TestApplyJournalChanges(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	file := filepath.Join(tempDir, "todos.json")
+	before := json.RawMessage(`[{"task":"Before"}]`)
+	after := json.RawMessage(`[{"task":"After"}]`)
+	changes := []JournalChange{{File: file, Before: before, After: after}}
+
+	if err := os.WriteFile(file, []byte("[\n  {\n    \"task\": \"After\"\n  }\n]"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	// Undo restores the before content even if formatting differs
+	if err := applyJournalChanges(changes, true, false); err != nil {
+		t.Fatalf("applyJournalChanges() undo error = %v", err)
+	}
+	if content, _ := os.ReadFile(file); string(content) != string(before) {
+		t.Errorf("applyJournalChanges() undo content = %s, want %s", content, before)
+	}
+
+	// Redo refuses to overwrite a file that changed in the meantime
+	os.WriteFile(file, []byte(`[{"task":"Edited by hand"}]`), 0644)
+	err := applyJournalChanges(changes, false, false)
+	if err == nil || !strings.Contains(err.Error(), "has changed since this entry was recorded") {
+		t.Fatalf("applyJournalChanges() redo error = %v, want conflict", err)
+	}
+
+	// Unless forced
+	if err := applyJournalChanges(changes, false, true); err != nil {
+		t.Fatalf("applyJournalChanges() forced redo error = %v", err)
+	}
+	if content, _ := os.ReadFile(file); string(content) != string(after) {
+		t.Errorf("applyJournalChanges() redo content = %s, want %s", content, after)
+	}
+}
+
+func TestApplyJournalChanges_EmptyFile(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	file := filepath.Join(tempDir, "todos.json")
+	os.WriteFile(file, []byte(`[{"task":"Added"}]`), 0644)
+
+	changes := []JournalChange{{File: file, Before: journalContent(nil), After: json.RawMessage(`[{"task":"Added"}]`)}}
+	if err := applyJournalChanges(changes, true, false); err != nil {
+		t.Fatalf("applyJournalChanges() error = %v", err)
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if len(content) != 0 {
+		t.Errorf("applyJournalChanges() content = %q, want empty file", content)
+	}
+}
+
+func TestApplyJournalChanges_Appended(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	useTestBackend(t, backend.DB)
+
+	file := filepath.Join(tempDir, "todos.archive.json")
+	storage := NewStorage[TodoList](file)
+	storage.Save(TodoList{{InternalID: "a1", Task: "Kept"}})
+	appendTodos(storage, []Todo{{InternalID: "b2", Task: "Archived"}})
+
+	changes := []JournalChange{{File: file, Backend: backend.DB, Appended: storage.appended}}
+	load := func() TodoList {
+		todoList, _ := NewStorage[TodoList](file).Load()
+		return todoList
+	}
+
+	// Undo removes only the appended items
+	if err := applyJournalChanges(changes, true, false); err != nil {
+		t.Fatalf("applyJournalChanges() undo error = %v", err)
+	}
+	if todoList := load(); len(todoList) != 1 || todoList[0].InternalID != "a1" {
+		t.Errorf("applyJournalChanges() undo left %+v", todoList)
+	}
+
+	// A second undo finds the items gone
+	if err := applyJournalChanges(changes, true, false); err == nil || !strings.Contains(err.Error(), "has changed since this entry was recorded") {
+		t.Errorf("applyJournalChanges() repeated undo error = %v, want conflict", err)
+	}
+
+	// Redo adds them again
+	if err := applyJournalChanges(changes, false, false); err != nil {
+		t.Fatalf("applyJournalChanges() redo error = %v", err)
+	}
+	if todoList := load(); len(todoList) != 2 || todoList[1].InternalID != "b2" {
+		t.Errorf("applyJournalChanges() redo left %+v", todoList)
+	}
+}
+
+func TestApplyJournalChanges_PlainFile(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	useTestPassphrase(t, "correct horse")
+
+	file := filepath.Join(tempDir, "TODO.md")
+	before := []byte("- [ ] Secret plan\n")
+	after := []byte("- [ ] Secret plan <!-- internal_id: a1b2c3d4e5f6 -->\n")
+
+	change, err := fileJournalChange(file, before, after)
+	if err != nil {
+		t.Fatalf("fileJournalChange() error = %v", err)
+	}
+
+	// Files written for an encrypted list keep their content encrypted in the journal
+	sealed, err := encryptJournalChange(change)
+	if err != nil {
+		t.Fatalf("encryptJournalChange() error = %v", err)
+	}
+	recorded, _ := json.Marshal(sealed)
+	if !sealed.Encrypted || strings.Contains(string(recorded), "Secret plan") {
+		t.Errorf("encrypted change = %s, want no plain content", recorded)
+	}
+
+	for _, recordedChange := range []JournalChange{change, sealed} {
+		os.WriteFile(file, after, 0644)
+		changes := []JournalChange{recordedChange}
+		if err := applyJournalChanges(changes, true, false); err != nil {
+			t.Fatalf("applyJournalChanges() undo error = %v", err)
+		}
+		if content, _ := os.ReadFile(file); string(content) != string(before) {
+			t.Errorf("applyJournalChanges() undo content = %q, want %q", content, before)
+		}
+		if changes[0].Encrypted != recordedChange.Encrypted {
+			t.Errorf("applyJournalChanges() changed the recorded entry")
+		}
+
+		if err := applyJournalChanges(changes, false, false); err != nil {
+			t.Fatalf("applyJournalChanges() redo error = %v", err)
+		}
+		if content, _ := os.ReadFile(file); string(content) != string(after) {
+			t.Errorf("applyJournalChanges() redo content = %q, want %q", content, after)
+		}
+	}
+}
+
+func TestJournalTrim(t *testing.T) {
+	// Entries holding large files are dropped oldest first, keeping at least the newest
+	content, _ := json.Marshal(strings.Repeat("x", maxJournalBytes/3))
+	var journal Journal
+	for index := 0; index < 5; index++ {
+		journal.Entries = append(journal.Entries, JournalEntry{
+			Command: fmt.Sprintf("edit %d", index),
+			Changes: []JournalChange{{File: "todos.json", Before: content, After: content}},
+		})
+	}
+	journal.trim()
+	if len(journal.Entries) != 1 || journal.Entries[0].Command != "edit 4" {
+		t.Errorf("trim() kept %d entries, want only the newest", len(journal.Entries))
+	}
+
+	// Small entries are capped by count
+	journal.Entries = nil
+	for index := 0; index < maxJournalEntries+5; index++ {
+		journal.Entries = append(journal.Entries, JournalEntry{Command: fmt.Sprintf("add %d", index)})
+	}
+	journal.trim()
+	if len(journal.Entries) != maxJournalEntries || journal.Entries[0].Command != "add 5" {
+		t.Errorf("trim() kept %d entries starting with %q", len(journal.Entries), journal.Entries[0].Command)
+	}
+}
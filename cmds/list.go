@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/bennthewolfe/todo-cli/backend"
 	"github.com/urfave/cli/v3"
 )
 
@@ -18,12 +20,45 @@ func NewListCommand() *cli.Command {
 			&cli.StringFlag{
 				Name:    "format",
 				Aliases: []string{"f"},
-				Usage:   "Output format (table, json, pretty, none)",
+				Usage:   "Output format (table, json, pretty, csv, tsv, markdown, markdown-table, none)",
 				Value:   "table",
 			},
 			&cli.BoolFlag{
-				Name:    "filter",
-				Usage:   "Filter out completed tasks",
+				Name:  "filter",
+				Usage: "Filter out completed tasks",
+			},
+			&cli.StringFlag{
+				Name:  "sort",
+				Usage: "Sort items by key (priority, due, created, updated, task)",
+			},
+			&cli.StringFlag{
+				Name:    "where",
+				Aliases: []string{"w"},
+				Usage:   "Show only tasks matching a query (e.g. 'completed=false and tag:backend and task~\"deploy\"')",
+			},
+			&cli.StringSliceFlag{
+				Name:  "tag",
+				Usage: "Show only tasks with a tag, or without it using a '!' prefix (repeatable)",
+			},
+			&cli.BoolFlag{
+				Name:  "overdue",
+				Usage: "Show only incomplete tasks that are past their due date",
+			},
+			&cli.StringFlag{
+				Name:  "due-before",
+				Usage: "Show only tasks due before a date (e.g. 2025-09-01, friday, in 7d)",
+			},
+			&cli.StringFlag{
+				Name:  "due-after",
+				Usage: "Show only tasks due after a date (e.g. 2025-09-01, today)",
+			},
+			&cli.StringFlag{
+				Name:  "columns",
+				Usage: "Comma-separated columns of csv and tsv output (e.g. id,task,completed)",
+			},
+			&cli.BoolFlag{
+				Name:  "show-uid",
+				Usage: "Add the internal ID column to table output (usable in place of the ID)",
 			},
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
@@ -38,30 +73,75 @@ func NewListCommand() *cli.Command {
 				fmt.Println("DEBUG: --list flag detected on list command (no change in behavior)")
 			}
 
+			// Flags override the configured defaults
+			cfg := CurrentConfig()
+			var err error
+
 			format := c.String("format")
+			if !c.IsSet("format") {
+				format = cfg.Format
+			}
+			sortKey := c.String("sort")
+			if !c.IsSet("sort") {
+				sortKey = cfg.Sort
+			}
 
 			// Validate format
-			allowedFormats := []string{"table", "json", "pretty", "none"}
-			valid := false
-			for _, allowedFormat := range allowedFormats {
-				if format == allowedFormat {
-					valid = true
-					break
+			if !containsString(AllowedListFormats, format) {
+				return cli.Exit(fmt.Sprintf("invalid format: %s. Allowed formats: %s", format, strings.Join(AllowedListFormats, ", ")), 1)
+			}
+
+			if c.IsSet("columns") {
+				if listColumns, err = ParseColumns(c.String("columns")); err != nil {
+					return cli.Exit(err.Error(), 1)
 				}
 			}
 
-			if !valid {
-				return cli.Exit(fmt.Sprintf("invalid format: %s. Allowed formats: %s", format, strings.Join(allowedFormats, ", ")), 1)
+			// Parse due date filters before touching storage
+			now := time.Now()
+			var dueBefore, dueAfter time.Time
+			if value := c.String("due-before"); value != "" {
+				if dueBefore, err = ParseDueDate(value, now); err != nil {
+					return cli.Exit(fmt.Sprintf("invalid --due-before value: %v", err), 1)
+				}
+			}
+			if value := c.String("due-after"); value != "" {
+				if dueAfter, err = ParseDueDate(value, now); err != nil {
+					return cli.Exit(fmt.Sprintf("invalid --due-after value: %v", err), 1)
+				}
+			}
+
+			// Parse the query before touching storage
+			var predicate Predicate
+			if where := c.String("where"); where != "" {
+				if predicate, err = ParseQuery(where); err != nil {
+					return cli.Exit(err.Error(), 1)
+				}
 			}
 
-			// Get the appropriate storage path based on global and archive flags
-			storagePath, err := GetEffectiveStoragePath(c.Bool("global"), c.Bool("archive"))
+			// Parse tag filters
+			includeTags, excludeTags, err := ParseTagFilters(c.StringSlice("tag"))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("invalid --tag value: %v", err), 1)
+			}
+
+			// Get the appropriate storage path for the selected list and archive flag
+			storagePath, err := GetEffectiveStoragePath(GetStorageOptions(c), c.Bool("archive"))
 			if err != nil {
 				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
 			}
 
-			// Initialize todo list and storage
-			todoList, _, err := initializeTodoListWithPath(storagePath)
+			// Load the items that may match; backends with indexes skip the others
+			query := backend.Query{Tags: includeTags, DueBefore: dueBefore, DueAfter: dueAfter}
+			if c.Bool("filter") || c.Bool("overdue") {
+				incomplete := false
+				query.Completed = &incomplete
+			}
+			if c.Bool("overdue") {
+				query.DueBefore = now
+			}
+
+			todoList, err := queryTodoList(storagePath, query)
 			if err != nil {
 				return cli.Exit(fmt.Sprintf("failed to initialize todo list: %v", err), 2)
 			}
@@ -71,6 +151,28 @@ func NewListCommand() *cli.Command {
 				todoList.FilterIncomplete()
 			}
 
+			if predicate != nil {
+				todoList.FilterWhere(predicate)
+			}
+
+			if len(includeTags) > 0 || len(excludeTags) > 0 {
+				todoList.FilterTags(includeTags, excludeTags)
+			}
+
+			if c.Bool("overdue") {
+				todoList.FilterOverdue(now)
+			}
+
+			if !dueBefore.IsZero() || !dueAfter.IsZero() {
+				todoList.FilterDueRange(dueBefore, dueAfter)
+			}
+
+			// Apply sort order if requested (display IDs keep matching storage order)
+			if err := todoList.SortBy(sortKey); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			tableShowUID = c.Bool("show-uid")
 			todoList.View(format)
 			return nil
 		},
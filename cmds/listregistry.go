@@ -0,0 +1,253 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Built-in list names that always exist and can't be registered
+const (
+	LocalListName  = "local"
+	GlobalListName = "global"
+)
+
+// listNamePattern restricts list names to something safe to type and to use in file names
+var listNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ListRegistry holds the named todo lists and the one selected with 'todo use'
+type ListRegistry struct {
+	Active string                    `json:"active,omitempty"`
+	Lists  map[string]RegisteredList `json:"lists"`
+}
+
+// RegisteredList is a named todo list stored at an arbitrary path
+type RegisteredList struct {
+	Path        string `json:"path"`
+	ArchivePath string `json:"archive_path,omitempty"`
+}
+
+// NamedList is a registry entry together with its name, used for listing
+type NamedList struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	ArchivePath string `json:"archive_path"`
+	Active      bool   `json:"active"`
+}
+
+// GetRegistryPath returns the file holding the named list registry (~/.todo/lists.json)
+func GetRegistryPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".todo", "lists.json"), nil
+}
+
+// LoadListRegistry loads the named list registry, returning an empty one if none exists yet
+func LoadListRegistry() (*ListRegistry, *Storage[ListRegistry], error) {
+	registryPath, err := GetRegistryPath()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	storage := NewStorage[ListRegistry](registryPath)
+	registry, err := storage.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error loading list registry: %w", err)
+	}
+	if registry.Lists == nil {
+		registry.Lists = make(map[string]RegisteredList)
+	}
+
+	return &registry, storage, nil
+}
+
+// SaveListRegistry writes the registry, creating ~/.todo if needed
+func SaveListRegistry(registry *ListRegistry, storage *Storage[ListRegistry]) error {
+	if err := os.MkdirAll(filepath.Dir(storage.filename), 0755); err != nil {
+		return fmt.Errorf("unable to create todo directory: %w", err)
+	}
+	if err := storage.Save(*registry); err != nil {
+		return fmt.Errorf("error saving list registry: %w", err)
+	}
+	return nil
+}
+
+// ValidateListName checks that a name can be used for a registered list
+func ValidateListName(name string) error {
+	if name == LocalListName || name == GlobalListName {
+		return fmt.Errorf("list name '%s' is reserved", name)
+	}
+	if !listNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid list name: %s (use letters, digits, '.', '_' or '-')", name)
+	}
+	return nil
+}
+
+// Add registers a list under name, storing absolute paths so it works from any directory
+func (registry *ListRegistry) Add(name, storagePath, archivePath string) error {
+	if err := ValidateListName(name); err != nil {
+		return err
+	}
+	if _, exists := registry.Lists[name]; exists {
+		return fmt.Errorf("list '%s' already exists", name)
+	}
+
+	list := RegisteredList{}
+	var err error
+	if list.Path, err = expandListPath(storagePath); err != nil {
+		return err
+	}
+	if archivePath != "" {
+		if list.ArchivePath, err = expandListPath(archivePath); err != nil {
+			return err
+		}
+	}
+
+	registry.Lists[name] = list
+	return nil
+}
+
+// Remove unregisters a list, clearing the active selection if it pointed at it.
+// The list's files are left in place.
+func (registry *ListRegistry) Remove(name string) error {
+	if _, exists := registry.Lists[name]; !exists {
+		return fmt.Errorf("unknown list: %s", name)
+	}
+
+	delete(registry.Lists, name)
+	if registry.Active == name {
+		registry.Active = ""
+	}
+	return nil
+}
+
+// Use makes name the active list; 'local' goes back to the default .todos.json
+func (registry *ListRegistry) Use(name string) error {
+	switch name {
+	case LocalListName:
+		registry.Active = ""
+		return nil
+	case GlobalListName:
+		registry.Active = GlobalListName
+		return nil
+	}
+
+	if _, exists := registry.Lists[name]; !exists {
+		return fmt.Errorf("unknown list: %s (see 'todo lists ls')", name)
+	}
+	registry.Active = name
+	return nil
+}
+
+// ActiveName returns the name of the active list
+func (registry *ListRegistry) ActiveName() string {
+	if registry.Active == "" {
+		return LocalListName
+	}
+	return registry.Active
+}
+
+// NamedLists returns the built-in and registered lists, built-ins first and the rest sorted by name
+func (registry *ListRegistry) NamedLists() ([]NamedList, error) {
+	var lists []NamedList
+	active := registry.ActiveName()
+
+	for _, builtin := range []StorageOptions{{}, {Global: true}} {
+		name := LocalListName
+		if builtin.Global {
+			name = GlobalListName
+		}
+
+		storagePath, err := GetStoragePath(builtin)
+		if err != nil {
+			return nil, err
+		}
+		archivePath, err := GetArchivePath(builtin)
+		if err != nil {
+			return nil, err
+		}
+
+		lists = append(lists, NamedList{Name: name, Path: storagePath, ArchivePath: archivePath, Active: name == active})
+	}
+
+	names := make([]string, 0, len(registry.Lists))
+	for name := range registry.Lists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		list := registry.Lists[name]
+		archivePath := list.ArchivePath
+		if archivePath == "" {
+			archivePath = deriveArchivePath(list.Path)
+		}
+		lists = append(lists, NamedList{Name: name, Path: list.Path, ArchivePath: archivePath, Active: name == active})
+	}
+
+	return lists, nil
+}
+
+// expandListPath expands a leading ~ and makes the path absolute
+func expandListPath(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("list path must not be empty")
+	}
+
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("unable to get user home directory: %w", err)
+		}
+		path = filepath.Join(homeDir, path[1:])
+	}
+
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve %s: %w", path, err)
+	}
+	return absolute, nil
+}
+
+// resolveStorageOptions turns a list name (given or active) into the built-in
+// list it refers to, or the registered list to use instead. An explicit --file
+// bypasses the registry entirely.
+func resolveStorageOptions(opts StorageOptions) (StorageOptions, *RegisteredList, error) {
+	if opts.File != "" && (opts.Global || opts.ListName != "") {
+		return opts, nil, fmt.Errorf("--file cannot be combined with --global or --list-name")
+	}
+	if opts.Global && opts.ListName != "" {
+		return opts, nil, fmt.Errorf("--global cannot be combined with --list-name")
+	}
+	if opts.File != "" || opts.Global || (opts.ListName == "" && !opts.UseActive) {
+		return opts, nil, nil
+	}
+
+	registry, _, err := LoadListRegistry()
+	if err != nil {
+		return opts, nil, err
+	}
+
+	name := opts.ListName
+	if name == "" {
+		name = registry.ActiveName()
+	}
+
+	switch name {
+	case LocalListName:
+		return StorageOptions{ArchiveFile: opts.ArchiveFile}, nil, nil
+	case GlobalListName:
+		return StorageOptions{Global: true, ArchiveFile: opts.ArchiveFile}, nil, nil
+	}
+
+	list, exists := registry.Lists[name]
+	if !exists {
+		return opts, nil, fmt.Errorf("unknown list: %s (see 'todo lists ls')", name)
+	}
+	return StorageOptions{ListName: name, ArchiveFile: opts.ArchiveFile}, &list, nil
+}
@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupTestHome points the user home directory at a temporary directory
+// and runs the test from an empty working directory
+func setupTestHome(t *testing.T) string {
+	t.Chdir(t.TempDir())
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	return home
+}
+
+func TestListRegistry_AddUseRemove(t *testing.T) {
+	home := setupTestHome(t)
+
+	registry, storage, err := LoadListRegistry()
+	if err != nil {
+		t.Fatalf("LoadListRegistry() error = %v", err)
+	}
+
+	if err := registry.Add("work", "~/work/todos.json", ""); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := registry.Use("work"); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+	if err := SaveListRegistry(registry, storage); err != nil {
+		t.Fatalf("SaveListRegistry() error = %v", err)
+	}
+
+	// The active list now drives path resolution
+	opts := StorageOptions{UseActive: true}
+	storagePath, err := GetStoragePath(opts)
+	if err != nil {
+		t.Fatalf("GetStoragePath() error = %v", err)
+	}
+	if want := filepath.Join(home, "work", "todos.json"); storagePath != want {
+		t.Errorf("GetStoragePath() = %s, want %s", storagePath, want)
+	}
+
+	archivePath, err := GetArchivePath(opts)
+	if err != nil {
+		t.Fatalf("GetArchivePath() error = %v", err)
+	}
+	if want := filepath.Join(home, "work", "todos.archive.json"); archivePath != want {
+		t.Errorf("GetArchivePath() = %s, want %s", archivePath, want)
+	}
+
+	// --global still overrides the active list
+	storagePath, err = GetStoragePath(StorageOptions{Global: true, UseActive: true})
+	if err != nil {
+		t.Fatalf("GetStoragePath() error = %v", err)
+	}
+	if want := filepath.Join(home, ".todo", "todos.json"); storagePath != want {
+		t.Errorf("GetStoragePath() with global = %s, want %s", storagePath, want)
+	}
+
+	// Removing the active list falls back to the local list
+	registry, storage, err = LoadListRegistry()
+	if err != nil {
+		t.Fatalf("LoadListRegistry() error = %v", err)
+	}
+	if err := registry.Remove("work"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if err := SaveListRegistry(registry, storage); err != nil {
+		t.Fatalf("SaveListRegistry() error = %v", err)
+	}
+
+	storagePath, err = GetStoragePath(opts)
+	if err != nil {
+		t.Fatalf("GetStoragePath() error = %v", err)
+	}
+	if storagePath != ".todos.json" {
+		t.Errorf("GetStoragePath() after Remove() = %s, want .todos.json", storagePath)
+	}
+}
+
+func TestListRegistry_Errors(t *testing.T) {
+	setupTestHome(t)
+
+	registry, _, err := LoadListRegistry()
+	if err != nil {
+		t.Fatalf("LoadListRegistry() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		run     func() error
+		wantErr string
+	}{
+		{"reserved name", func() error { return registry.Add("global", "x.json", "") }, "reserved"},
+		{"invalid name", func() error { return registry.Add("my list", "x.json", "") }, "invalid list name"},
+		{"empty path", func() error { return registry.Add("work", "", "") }, "must not be empty"},
+		{"use unknown", func() error { return registry.Use("nope") }, "unknown list"},
+		{"remove unknown", func() error { return registry.Remove("nope") }, "unknown list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := registry.Add("work", "work.json", ""); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := registry.Add("work", "other.json", ""); err == nil {
+		t.Errorf("Add() with a duplicate name should fail")
+	}
+}
+
+func TestGetStoragePath_ListName(t *testing.T) {
+	setupTestHome(t)
+
+	if _, err := GetStoragePath(StorageOptions{ListName: "missing"}); err == nil || !strings.Contains(err.Error(), "unknown list") {
+		t.Errorf("GetStoragePath() with an unknown list error = %v, want unknown list", err)
+	}
+	if _, err := GetStoragePath(StorageOptions{Global: true, ListName: "work"}); err == nil {
+		t.Errorf("GetStoragePath() should reject --global combined with --list-name")
+	}
+
+	storagePath, err := GetStoragePath(StorageOptions{ListName: LocalListName})
+	if err != nil {
+		t.Fatalf("GetStoragePath() error = %v", err)
+	}
+	if storagePath != ".todos.json" {
+		t.Errorf("GetStoragePath() for the local list = %s, want .todos.json", storagePath)
+	}
+}
+
+func TestDeriveArchivePath(t *testing.T) {
+	tests := map[string]string{
+		"foo.json":              "foo.archive.json",
+		"/tmp/work/todos.json":  "/tmp/work/todos.archive.json",
+		"todos":                 "todos.archive.json",
+		"/tmp/list.v2/list.txt": "/tmp/list.v2/list.archive.txt",
+	}
+
+	for input, want := range tests {
+		if got := deriveArchivePath(input); got != want {
+			t.Errorf("deriveArchivePath(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
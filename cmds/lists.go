@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aquasecurity/table"
+	"github.com/urfave/cli/v3"
+)
+
+// NewListsCommand creates a new lists command for urfave/cli
+func NewListsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "lists",
+		Usage: "Manage named todo lists",
+		Commands: []*cli.Command{
+			newListsAddCommand(),
+			newListsLsCommand(),
+			newListsRmCommand(),
+		},
+	}
+}
+
+// newListsAddCommand creates the 'lists add' subcommand
+func newListsAddCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "add",
+		Usage:     "Register a named todo list stored at the given path",
+		ArgsUsage: "<name> <path>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "archive-path",
+				Usage: "Archive file for the list (defaults to <path> with .archive before the extension)",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if c.Args().Len() != 2 {
+				return cli.Exit("a list name and path are required", 1)
+			}
+			name := c.Args().Get(0)
+
+			registry, storage, err := LoadListRegistry()
+			if err != nil {
+				return cli.Exit(err.Error(), 2)
+			}
+
+			if err := registry.Add(name, c.Args().Get(1), c.String("archive-path")); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// Make sure the list's directory exists so the first command can create the file
+			list := registry.Lists[name]
+			if err := os.MkdirAll(filepath.Dir(list.Path), 0755); err != nil {
+				return cli.Exit(fmt.Sprintf("unable to create list directory: %v", err), 2)
+			}
+
+			if err := SaveListRegistry(registry, storage); err != nil {
+				return cli.Exit(err.Error(), 2)
+			}
+
+			fmt.Printf("Added list '%s': %s\n", name, list.Path)
+			return nil
+		},
+	}
+}
+
+// newListsLsCommand creates the 'lists ls' subcommand
+func newListsLsCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "ls",
+		Aliases:   []string{"list"},
+		Usage:     "Show the built-in and registered todo lists",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "Output format (table, json)",
+				Value:   "table",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			format := c.String("format")
+			if format != "table" && format != "json" {
+				return cli.Exit(fmt.Sprintf("invalid format: %s. Allowed formats: table, json", format), 1)
+			}
+
+			registry, _, err := LoadListRegistry()
+			if err != nil {
+				return cli.Exit(err.Error(), 2)
+			}
+
+			lists, err := registry.NamedLists()
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
+			}
+
+			if format == "json" {
+				jsonOutput, err := json.Marshal(lists)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("error marshaling JSON: %v", err), 2)
+				}
+				fmt.Println(string(jsonOutput))
+				return nil
+			}
+
+			t := table.New(os.Stdout)
+			t.SetRowLines(false)
+			t.SetHeaders("Active", "Name", "Path", "Archive")
+			for _, list := range lists {
+				active := ""
+				if list.Active {
+					active = "*"
+				}
+				t.AddRow(active, list.Name, list.Path, list.ArchivePath)
+			}
+			t.Render()
+
+			return nil
+		},
+	}
+}
+
+// newListsRmCommand creates the 'lists rm' subcommand
+func newListsRmCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "rm",
+		Aliases:   []string{"remove"},
+		Usage:     "Unregister a named todo list (its files are kept)",
+		ArgsUsage: "<name>",
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if c.Args().Len() != 1 {
+				return cli.Exit("a list name is required", 1)
+			}
+			name := c.Args().Get(0)
+
+			registry, storage, err := LoadListRegistry()
+			if err != nil {
+				return cli.Exit(err.Error(), 2)
+			}
+
+			wasActive := registry.Active == name
+			if err := registry.Remove(name); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			if err := SaveListRegistry(registry, storage); err != nil {
+				return cli.Exit(err.Error(), 2)
+			}
+
+			fmt.Printf("Removed list '%s'\n", name)
+			if wasActive {
+				fmt.Printf("Active list reset to '%s'\n", LocalListName)
+			}
+			return nil
+		},
+	}
+}
+
+// Legacy command struct for backward compatibility
+type ListsCommand struct{}
+
+func init() {
+	RegisterCommand(&ListsCommand{})
+}
+
+func (c *ListsCommand) Name() string {
+	return "lists"
+}
+
+func (c *ListsCommand) Description() string {
+	return "Manage named todo lists"
+}
+
+func (c *ListsCommand) Usage() string {
+	return "todo-cli lists <add|ls|rm>"
+}
+
+func (c *ListsCommand) Execute(args []string, todoList TodoListInterface) error {
+	// Note: Legacy interface doesn't manage list registrations
+	return fmt.Errorf("lists functionality not supported in legacy interface")
+}
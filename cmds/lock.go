@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+// DefaultLockTimeout is how long a command waits for another todo process to finish
+const DefaultLockTimeout = 5 * time.Second
+
+// lockRetryInterval is how often a busy lock is retried
+const lockRetryInterval = 50 * time.Millisecond
+
+// FileLock is an advisory cross-process lock held on a .lock file.
+// The lock is released automatically by the OS if the process dies.
+type FileLock struct {
+	file *os.File
+}
+
+// acquireFileLock locks path, retrying until the timeout expires
+func acquireFileLock(path string, timeout time.Duration) (*FileLock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLockFile(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("error locking %s: %w", path, err)
+		}
+		if locked {
+			return &FileLock{file: file}, nil
+		}
+
+		if !time.Now().Before(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("timed out after %s waiting for %s; another todo command may still be running (adjust with --lock-timeout)", timeout, path)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// Unlock releases the lock
+func (l *FileLock) Unlock() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	unlockErr := unlockFile(l.file)
+	closeErr := l.file.Close()
+	l.file = nil
+	if unlockErr != nil {
+		return unlockErr
+	}
+	return closeErr
+}
+
+// GetLockPath returns the lock file guarding a list's todo, archive and journal files:
+// ~/.todo/locks/<file>-<hash>.lock, named after the todo file and a short hash of its
+// absolute path, so no lock file is left next to a project's list
+func GetLockPath(opts StorageOptions) (string, error) {
+	storagePath, err := GetStoragePath(opts)
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(storagePath)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve %s: %w", storagePath, err)
+	}
+
+	todoDir, err := getTodoDir()
+	if err != nil {
+		return "", err
+	}
+	lockDir := filepath.Join(todoDir, "locks")
+	if err := os.MkdirAll(lockDir, 0755); err != nil {
+		return "", fmt.Errorf("unable to create lock directory: %w", err)
+	}
+
+	// The lock file is kept after Unlock: removing it could let two processes
+	// lock different files for the same list
+	sum := sha256.Sum256([]byte(absPath))
+	name := strings.TrimPrefix(filepath.Base(absPath), ".")
+	return filepath.Join(lockDir, fmt.Sprintf("%s-%x.lock", name, sum[:6])), nil
+}
+
+// lockTodoList acquires the lock for the list selected by the command flags.
+// Mutating commands hold it from loading their files until the last save.
+func lockTodoList(c *cli.Command) (*FileLock, error) {
+	lockPath, err := GetLockPath(GetStorageOptions(c))
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := DefaultLockTimeout
+	if c.IsSet("lock-timeout") {
+		timeout = c.Duration("lock-timeout")
+	}
+
+	if c.Bool("debug") {
+		fmt.Printf("DEBUG: Acquiring lock %s (timeout %s)\n", lockPath, timeout)
+	}
+
+	return acquireFileLock(lockPath, timeout)
+}
@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package commands
+
+import "os"
+
+// tryLockFile always succeeds on platforms without advisory file locks
+func tryLockFile(file *os.File) (bool, error) {
+	return true, nil
+}
+
+// unlockFile is a no-op on platforms without advisory file locks
+func unlockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcquireFileLock(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "todos.json.lock")
+
+	lock, err := acquireFileLock(lockPath, time.Second)
+	if err != nil {
+		t.Fatalf("acquireFileLock() error = %v", err)
+	}
+
+	// A second holder times out while the lock is held
+	start := time.Now()
+	_, err = acquireFileLock(lockPath, 200*time.Millisecond)
+	if err == nil {
+		t.Fatalf("acquireFileLock() expected timeout while lock is held")
+	}
+	if !strings.Contains(err.Error(), "timed out after 200ms") {
+		t.Errorf("acquireFileLock() error = %v, want timeout message", err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("acquireFileLock() gave up after %v, want at least 200ms", elapsed)
+	}
+
+	// Once released the lock can be taken again
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+
+	lock, err = acquireFileLock(lockPath, 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("acquireFileLock() after Unlock() error = %v", err)
+	}
+	lock.Unlock()
+}
+
+func TestAcquireFileLock_WaitsForRelease(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "todos.json.lock")
+
+	lock, err := acquireFileLock(lockPath, time.Second)
+	if err != nil {
+		t.Fatalf("acquireFileLock() error = %v", err)
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		lock.Unlock()
+	}()
+
+	second, err := acquireFileLock(lockPath, 2*time.Second)
+	if err != nil {
+		t.Fatalf("acquireFileLock() should succeed once the holder releases, error = %v", err)
+	}
+	second.Unlock()
+}
+
+func TestGetLockPath(t *testing.T) {
+	home := setupTestHome(t)
+	cwd, _ := os.Getwd()
+
+	// Locks live under ~/.todo/locks, one per todo file path
+	first, err := GetLockPath(StorageOptions{File: filepath.Join(cwd, "a", ".todos.json")})
+	if err != nil {
+		t.Fatalf("GetLockPath() error = %v", err)
+	}
+	second, _ := GetLockPath(StorageOptions{File: filepath.Join(cwd, "b", ".todos.json")})
+	again, _ := GetLockPath(StorageOptions{File: filepath.Join(cwd, "a", ".todos.json")})
+
+	if filepath.Dir(first) != filepath.Join(home, ".todo", "locks") || !strings.HasPrefix(filepath.Base(first), "todos.json-") {
+		t.Errorf("GetLockPath() = %s, want a todos.json lock in ~/.todo/locks", first)
+	}
+	if first == second || first != again {
+		t.Errorf("GetLockPath() = %s, %s, %s; want one lock per todo file", first, second, again)
+	}
+	if entries, _ := os.ReadDir(cwd); len(entries) != 0 {
+		t.Errorf("GetLockPath() created %d entries next to the list", len(entries))
+	}
+}
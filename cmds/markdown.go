@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Markdown checklists hold one item per line, such as
+// "- [x] Write docs +docs <!-- internal_id: 3f2a9c1b7d4e -->", with the item's internal
+// ID in an HTML comment so 'todo sync-md' can match lines to items.
+var (
+	markdownItemPattern  = regexp.MustCompile(`^(\s*[-*+]\s+\[)([ xX])(\]\s+)(.*?)\s*$`)
+	markdownIDPattern    = regexp.MustCompile(`\s*<!--\s*internal_id:\s*(\S+?)\s*-->`)
+	markdownColorPattern = regexp.MustCompile(`</?[a-z]+>`)
+)
+
+// MarkdownSync counts the changes made by syncing a markdown checklist with a list
+type MarkdownSync struct {
+	AddedToList   int // new lines in the file added as items
+	AddedToFile   int // items missing from the file added as lines
+	UpdatedInList int // items whose completion was changed to match the file
+	UpdatedInFile int // checkboxes changed to match the list
+	Removed       int // lines of items that are no longer in the list
+}
+
+// Changed reports whether the sync changed the list
+func (s MarkdownSync) Changed() bool {
+	return s.AddedToList > 0 || s.UpdatedInList > 0
+}
+
+// viewMarkdown prints the list as a markdown checklist
+func (todoList *TodoList) viewMarkdown() {
+	for _, todo := range *todoList {
+		fmt.Println(markdownLine(todo))
+	}
+}
+
+// markdownLine formats an item as a checklist line with its internal ID marker
+func markdownLine(todo Todo) string {
+	checkbox := " "
+	if todo.Completed {
+		checkbox = "x"
+	}
+
+	words := []string{"- [" + checkbox + "]", todo.Task}
+	for _, tag := range todo.Tags {
+		if strings.HasPrefix(tag, "@") {
+			words = append(words, tag)
+		} else {
+			words = append(words, "+"+tag)
+		}
+	}
+	return strings.Join(words, " ") + markdownIDMarker(todo.InternalID)
+}
+
+// markdownIDMarker is the HTML comment that links a checklist line to an item
+func markdownIDMarker(internalID string) string {
+	return fmt.Sprintf(" <!-- internal_id: %s -->", internalID)
+}
+
+// markdownCell formats a markdown table cell: tml color tags are dropped and pipes escaped
+func markdownCell(format string, args ...any) string {
+	cell := fmt.Sprintf(markdownColorPattern.ReplaceAllString(format, ""), args...)
+	return strings.ReplaceAll(cell, "|", `\|`)
+}
+
+// syncMarkdown reconciles a markdown checklist with a list and returns the updated file.
+// Lines without an ID marker become new items, and items missing from the file are
+// appended as lines. When a checkbox and its item disagree, whichever changed last wins:
+// the item if it was updated after modified (the file's modification time), else the file.
+// Lines of items that are no longer in the list are removed; other content is kept as is.
+func syncMarkdown(document string, todoList *TodoList, modified, now time.Time) (string, MarkdownSync) {
+	var result MarkdownSync
+
+	positions := make(map[string]int)
+	for index, todo := range *todoList {
+		positions[todo.InternalID] = index
+	}
+	seen := make(map[string]bool)
+
+	var lines []string
+	if document != "" {
+		lines = strings.Split(strings.TrimSuffix(document, "\n"), "\n")
+	}
+
+	var kept []string
+	fenced := false
+	for _, line := range lines {
+		// Lines keep a trailing \r so files with Windows line endings stay that way
+		body, ending := strings.TrimSuffix(line, "\r"), ""
+		if len(body) < len(line) {
+			ending = "\r"
+		}
+
+		trimmed := strings.TrimSpace(body)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+		}
+		matches := markdownItemPattern.FindStringSubmatch(body)
+		if fenced || matches == nil {
+			kept = append(kept, line)
+			continue
+		}
+
+		prefix, checkbox, separator, text := matches[1], matches[2], matches[3], matches[4]
+		checked := checkbox != " "
+
+		marker := markdownIDPattern.FindStringSubmatch(text)
+		if marker == nil {
+			task, tags := ExtractTags(text)
+			if task == "" {
+				kept = append(kept, line)
+				continue
+			}
+
+			todo := Todo{
+				InternalID: generateShortGUID(),
+				Task:       task,
+				Tags:       tags,
+				Completed:  checked,
+				CreatedAt:  now.Format(time.RFC3339),
+				UpdatedAt:  now.Format(time.RFC3339),
+			}
+			if checked {
+				todo.CompletedAt = now.Format(time.RFC3339)
+			}
+			*todoList = append(*todoList, todo)
+			positions[todo.InternalID] = len(*todoList) - 1
+			seen[todo.InternalID] = true
+			result.AddedToList++
+
+			kept = append(kept, prefix+checkbox+separator+text+markdownIDMarker(todo.InternalID)+ending)
+			continue
+		}
+
+		id := marker[1]
+		index, found := positions[id]
+		if !found || seen[id] {
+			result.Removed++
+			continue
+		}
+		seen[id] = true
+
+		todo := &(*todoList)[index]
+		if checked != todo.Completed {
+			if updatedAt, err := time.Parse(time.RFC3339, todo.UpdatedAt); err == nil && updatedAt.After(modified) {
+				checkbox = " "
+				if todo.Completed {
+					checkbox = "x"
+				}
+				result.UpdatedInFile++
+			} else {
+				todo.Completed = checked
+				todo.CompletedAt = ""
+				if checked {
+					todo.CompletedAt = now.Format(time.RFC3339)
+				}
+				todo.UpdatedAt = now.Format(time.RFC3339)
+				result.UpdatedInList++
+			}
+		}
+		kept = append(kept, prefix+checkbox+separator+text+ending)
+	}
+
+	ending := ""
+	if strings.Contains(document, "\r\n") {
+		ending = "\r"
+	}
+	for _, todo := range *todoList {
+		if !seen[todo.InternalID] {
+			kept = append(kept, markdownLine(todo)+ending)
+			result.AddedToFile++
+		}
+	}
+
+	if len(kept) == 0 {
+		return "", result
+	}
+	return strings.Join(kept, "\n") + "\n", result
+}
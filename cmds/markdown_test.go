@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarkdownLine(t *testing.T) {
+	tests := []struct {
+		todo Todo
+		want string
+	}{
+		{Todo{InternalID: "a1b2c3d4e5f6", Task: "Write docs", Tags: []string{"docs", "@office"}}, "- [ ] Write docs +docs @office <!-- internal_id: a1b2c3d4e5f6 -->"},
+		{Todo{InternalID: "b2c3d4e5f6a1", Task: "Ship", Completed: true}, "- [x] Ship <!-- internal_id: b2c3d4e5f6a1 -->"},
+	}
+	for _, test := range tests {
+		if got := markdownLine(test.todo); got != test.want {
+			t.Errorf("markdownLine() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestMarkdownCell(t *testing.T) {
+	if got := markdownCell("<red><bold>%s</bold></red>", "a|b"); got != `a\|b` {
+		t.Errorf("markdownCell() = %q, want %q", got, `a\|b`)
+	}
+}
+
+func TestSyncMarkdown(t *testing.T) {
+	synced := time.Date(2025, 9, 10, 12, 0, 0, 0, time.UTC)
+	now := synced.Add(time.Hour)
+	before := synced.Add(-time.Hour).Format(time.RFC3339)
+	after := synced.Add(time.Minute).Format(time.RFC3339)
+
+	todoList := TodoList{
+		{InternalID: "a1b2c3d4e5f6", Task: "Checked in the file", CreatedAt: before, UpdatedAt: before},
+		{InternalID: "b2c3d4e5f6a1", Task: "Toggled in the list", Completed: true, CreatedAt: before, UpdatedAt: after, CompletedAt: after},
+		{InternalID: "c3d4e5f6a1b2", Task: "Only in the list", CreatedAt: before, UpdatedAt: before},
+	}
+
+	document := strings.Join([]string{
+		"# TODO",
+		"",
+		"- [x] Checked in the file <!-- internal_id: a1b2c3d4e5f6 -->",
+		"  - [ ] Toggled in the list <!-- internal_id: b2c3d4e5f6a1 -->",
+		"- [ ] Deleted from the list <!-- internal_id: d4e5f6a1b2c3 -->",
+		"* [X] Written in the file +docs",
+		"```",
+		"- [ ] Example in a code block",
+		"```",
+		"- [ ] ",
+	}, "\n") + "\n"
+
+	output, result := syncMarkdown(document, &todoList, synced, now)
+
+	want := MarkdownSync{AddedToList: 1, AddedToFile: 1, UpdatedInList: 1, UpdatedInFile: 1, Removed: 1}
+	if result != want {
+		t.Errorf("syncMarkdown() result = %+v, want %+v", result, want)
+	}
+
+	if !todoList[0].Completed || todoList[0].CompletedAt != now.Format(time.RFC3339) || todoList[0].UpdatedAt != now.Format(time.RFC3339) {
+		t.Errorf("the file's checkbox should complete the item, got %+v", todoList[0])
+	}
+	if !todoList[1].Completed || todoList[1].UpdatedAt != after {
+		t.Errorf("the newer item should be kept, got %+v", todoList[1])
+	}
+	if len(todoList) != 4 || todoList[3].Task != "Written in the file" || !todoList[3].Completed || len(todoList[3].Tags) != 1 || todoList[3].Tags[0] != "docs" {
+		t.Fatalf("the new line should be added as an item, got %+v", todoList)
+	}
+
+	wantOutput := strings.Join([]string{
+		"# TODO",
+		"",
+		"- [x] Checked in the file <!-- internal_id: a1b2c3d4e5f6 -->",
+		"  - [x] Toggled in the list <!-- internal_id: b2c3d4e5f6a1 -->",
+		"* [X] Written in the file +docs <!-- internal_id: " + todoList[3].InternalID + " -->",
+		"```",
+		"- [ ] Example in a code block",
+		"```",
+		"- [ ] ",
+		"- [ ] Only in the list <!-- internal_id: c3d4e5f6a1b2 -->",
+	}, "\n") + "\n"
+	if output != wantOutput {
+		t.Errorf("syncMarkdown() output =\n%s\nwant\n%s", output, wantOutput)
+	}
+
+	// Syncing again changes nothing
+	again, result := syncMarkdown(output, &todoList, now, now)
+	if again != output || result != (MarkdownSync{}) {
+		t.Errorf("second sync = %+v, changed output: %t", result, again != output)
+	}
+}
+
+func TestSyncMarkdown_NewFile(t *testing.T) {
+	todoList := TodoList{{InternalID: "a1b2c3d4e5f6", Task: "First", CreatedAt: "2025-09-01T10:00:00Z", UpdatedAt: "2025-09-01T10:00:00Z"}}
+
+	output, result := syncMarkdown("", &todoList, time.Time{}, time.Now())
+	if output != "- [ ] First <!-- internal_id: a1b2c3d4e5f6 -->\n" || result.AddedToFile != 1 || result.Changed() {
+		t.Errorf("syncMarkdown() = %q, %+v", output, result)
+	}
+}
@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+)
+
+// NewMigrateCommand creates a new migrate command for urfave/cli
+func NewMigrateCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "migrate",
+		Usage:     fmt.Sprintf("Upgrade the todo and archive files to schema version %d", CurrentSchemaVersion),
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Report what would change without writing any file",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			// Validate archive flag usage
+			if err := ValidateArchiveFlagUsage(c, "migrate"); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// New local lists are only created by 'todo init'
+			if err := RequireTodoFile(GetStorageOptions(c)); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// Hold the list lock until every file is saved
+			lock, err := lockTodoList(c)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to acquire lock: %v", err), 2)
+			}
+			defer lock.Unlock()
+
+			// Get the appropriate storage paths for the selected list
+			storagePath, err := GetStoragePath(GetStorageOptions(c))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
+			}
+
+			archivePath, err := GetArchivePath(GetStorageOptions(c))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting archive path: %v", err), 2)
+			}
+
+			dryRun := c.Bool("dry-run")
+			var storages []*Storage[TodoList]
+			for _, path := range []string{storagePath, archivePath} {
+				todoList, storage, err := initializeTodoListWithPath(path)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("failed to initialize %s: %v", path, err), 2)
+				}
+
+				// A missing file (usually an archive that was never written) has nothing to migrate
+				if storage.loaded == nil {
+					continue
+				}
+
+				_, report, err := migrateTodoData(storage.content)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("failed to migrate %s: %v", path, err), 2)
+				}
+				printMigrationReport(path, report)
+				storage.dropped = nil // reported above
+
+				// Current files are still saved when items had to be given an internal_id
+				if len(report.Changes) == 0 || dryRun {
+					continue
+				}
+				if err := storage.Save(*todoList); err != nil {
+					return cli.Exit(fmt.Sprintf("error saving %s: %v", path, err), 2)
+				}
+				storages = append(storages, storage)
+			}
+
+			if dryRun {
+				fmt.Println("Dry run: no files were changed.")
+				return nil
+			}
+
+			// Record the change so it can be undone
+			if err := recordJournalEntry(c, storages...); err != nil {
+				return cli.Exit(fmt.Sprintf("error recording undo history: %v", err), 2)
+			}
+
+			return nil
+		},
+	}
+}
+
+// printMigrationReport prints the changes a migration makes to one file
+func printMigrationReport(path string, report MigrationReport) {
+	if !report.NeedsMigration() {
+		fmt.Printf("%s: already at version %d\n", path, report.ToVersion)
+	} else {
+		fmt.Printf("%s: version %d -> %d\n", path, report.FromVersion, report.ToVersion)
+	}
+	for _, change := range report.Changes {
+		fmt.Printf("  - %s\n", change)
+	}
+
+	for _, dropped := range report.Dropped {
+		fmt.Printf("  ! %s\n", dropped)
+	}
+}
+
+// Legacy command struct for backward compatibility
+type MigrateCommand struct{}
+
+func init() {
+	RegisterCommand(&MigrateCommand{})
+}
+
+func (c *MigrateCommand) Name() string {
+	return "migrate"
+}
+
+func (c *MigrateCommand) Description() string {
+	return "Upgrade the todo and archive files to the current schema version"
+}
+
+func (c *MigrateCommand) Usage() string {
+	return "todo-cli migrate [--dry-run]"
+}
+
+func (c *MigrateCommand) Execute(args []string, todoList TodoListInterface) error {
+	// Note: Legacy interface works on an already loaded list
+	return fmt.Errorf("migrate functionality not supported in legacy interface")
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Priority levels supported by todo items
+const (
+	PriorityHigh   = "high"
+	PriorityMedium = "medium"
+	PriorityLow    = "low"
+)
+
+// ParsePriority normalizes a user supplied priority value.
+// An empty string or "none" clears the priority.
+func ParsePriority(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "none":
+		return "", nil
+	case "high", "h", "1":
+		return PriorityHigh, nil
+	case "medium", "med", "m", "2":
+		return PriorityMedium, nil
+	case "low", "l", "3":
+		return PriorityLow, nil
+	default:
+		return "", fmt.Errorf("invalid priority: %s (allowed: high, medium, low, none)", value)
+	}
+}
+
+// priorityRank returns a sortable rank for a priority, lower ranks sort first
+func priorityRank(priority string) int {
+	switch priority {
+	case PriorityHigh:
+		return 0
+	case PriorityMedium:
+		return 1
+	case PriorityLow:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// priorityColorFormat returns the tml format that colors a priority in table output.
+// The priority is passed as an argument, so a hand-edited value can't act as a format.
+func priorityColorFormat(priority string) string {
+	switch priority {
+	case PriorityHigh:
+		return "<red>%s</red>"
+	case PriorityMedium:
+		return "<yellow>%s</yellow>"
+	case PriorityLow:
+		return "<blue>%s</blue>"
+	default:
+		return "%s"
+	}
+}
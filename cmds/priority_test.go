@@ -0,0 +1,62 @@
+package commands
+
+import "testing"
+
+func TestParsePriority(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{input: "high", expected: PriorityHigh},
+		{input: "H", expected: PriorityHigh},
+		{input: "1", expected: PriorityHigh},
+		{input: "medium", expected: PriorityMedium},
+		{input: "med", expected: PriorityMedium},
+		{input: "Low", expected: PriorityLow},
+		{input: "", expected: ""},
+		{input: "none", expected: ""},
+		{input: "urgent", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePriority(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePriority(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParsePriority(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTodoList_SetPriority(t *testing.T) {
+	todoList := &TodoList{}
+	todoList.Add("Task")
+
+	if err := todoList.SetPriority(0, "h"); err != nil {
+		t.Fatalf("SetPriority() error = %v", err)
+	}
+	if (*todoList)[0].Priority != PriorityHigh {
+		t.Errorf("SetPriority() priority = %q, want %q", (*todoList)[0].Priority, PriorityHigh)
+	}
+
+	if err := todoList.SetPriority(0, "bogus"); err == nil {
+		t.Errorf("SetPriority() expected error for invalid priority")
+	}
+
+	if err := todoList.SetPriority(5, "low"); err == nil {
+		t.Errorf("SetPriority() expected error for invalid index")
+	}
+}
+
+func TestPriorityColorFormat(t *testing.T) {
+	// Hand-edited priorities are printed as they are, never read as a format
+	for _, priority := range []string{PriorityHigh, PriorityLow, "", "50%d", "%s%s"} {
+		if got := markdownCell(priorityColorFormat(priority), priority); got != priority {
+			t.Errorf("priority cell for %q = %q, want it unchanged", priority, got)
+		}
+	}
+}
@@ -0,0 +1,455 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Predicate is a parsed --where expression that can be evaluated against a todo
+type Predicate interface {
+	Matches(todo Todo) bool
+}
+
+// QueryFields lists the fields available in --where expressions
+var QueryFields = []string{"task", "completed", "priority", "tag", "due", "created", "updated"}
+
+// ParseQuery parses a --where expression such as
+//
+//	completed=false and tag:backend and created>2025-08-01 and task~"deploy"
+//
+// Comparisons can be combined with and, or, not and parentheses.
+func ParseQuery(input string) (Predicate, error) {
+	return parseQuery(input, time.Now())
+}
+
+// FilterWhere keeps only items matching the predicate
+func (todoList *TodoList) FilterWhere(predicate Predicate) {
+	todoList.filter(predicate.Matches)
+}
+
+// QueryError describes a syntax or semantic error in a --where expression
+type QueryError struct {
+	Position int // 1-based column of the offending token
+	Message  string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("invalid --where expression at position %d: %s", e.Position, e.Message)
+}
+
+// Predicate tree nodes
+type andPredicate struct{ left, right Predicate }
+type orPredicate struct{ left, right Predicate }
+type notPredicate struct{ inner Predicate }
+type matchPredicate struct{ match func(Todo) bool }
+
+func (p andPredicate) Matches(todo Todo) bool   { return p.left.Matches(todo) && p.right.Matches(todo) }
+func (p orPredicate) Matches(todo Todo) bool    { return p.left.Matches(todo) || p.right.Matches(todo) }
+func (p notPredicate) Matches(todo Todo) bool   { return !p.inner.Matches(todo) }
+func (p matchPredicate) Matches(todo Todo) bool { return p.match(todo) }
+
+type queryTokenKind int
+
+const (
+	queryEOF queryTokenKind = iota
+	queryWord
+	queryString
+	queryOperator
+	queryLParen
+	queryRParen
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	pos  int
+}
+
+// queryOperators is ordered so two-character operators match first
+var queryOperators = []string{"!=", "!~", "<=", ">=", "=", "<", ">", "~", ":"}
+
+// tokenizeQuery splits an expression into tokens. The token following an
+// operator is read as a value, so values like RFC3339 timestamps need no quoting.
+func tokenizeQuery(input string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(input)
+	i := 0
+
+	for i < len(runes) {
+		r := runes[i]
+		start := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, queryToken{queryLParen, "(", start})
+			i++
+			continue
+		case r == ')':
+			tokens = append(tokens, queryToken{queryRParen, ")", start})
+			i++
+			continue
+		case r == '"' || r == '\'':
+			value, next, err := readQuotedQueryValue(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, queryToken{queryString, value, start})
+			i = next
+			continue
+		}
+
+		if op := matchQueryOperator(runes[i:]); op != "" {
+			tokens = append(tokens, queryToken{queryOperator, op, start})
+			i += len(op)
+
+			// Read the value right after the operator
+			for i < len(runes) && unicode.IsSpace(runes[i]) {
+				i++
+			}
+			if i < len(runes) && runes[i] != '"' && runes[i] != '\'' && runes[i] != '(' && runes[i] != ')' {
+				valueStart := i
+				for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != ')' && runes[i] != '(' {
+					i++
+				}
+				tokens = append(tokens, queryToken{queryWord, string(runes[valueStart:i]), valueStart + 1})
+			}
+			continue
+		}
+
+		// Bare word: a field name or keyword
+		wordStart := i
+		for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("()\"'", runes[i]) && matchQueryOperator(runes[i:]) == "" {
+			i++
+		}
+		tokens = append(tokens, queryToken{queryWord, string(runes[wordStart:i]), start})
+	}
+
+	tokens = append(tokens, queryToken{queryEOF, "", len(runes) + 1})
+	return tokens, nil
+}
+
+// readQuotedQueryValue reads a quoted string starting at runes[start]
+func readQuotedQueryValue(runes []rune, start int) (string, int, error) {
+	quote := runes[start]
+	var value strings.Builder
+
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				value.WriteRune(runes[i])
+			}
+		case quote:
+			return value.String(), i + 1, nil
+		default:
+			value.WriteRune(runes[i])
+		}
+	}
+
+	return "", 0, &QueryError{Position: start + 1, Message: "unterminated quoted string"}
+}
+
+// matchQueryOperator returns the operator at the start of runes, if any
+func matchQueryOperator(runes []rune) string {
+	for _, op := range queryOperators {
+		if strings.HasPrefix(string(runes[:min(len(runes), 2)]), op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// queryParser is a recursive descent parser over the token stream:
+//
+//	expr       := andExpr { "or" andExpr }
+//	andExpr    := unary { "and" unary }
+//	unary      := "not" unary | "(" expr ")" | comparison
+//	comparison := field operator value
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+	now    time.Time
+}
+
+func parseQuery(input string, now time.Time) (Predicate, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, &QueryError{Position: 1, Message: "expression is empty"}
+	}
+
+	tokens, err := tokenizeQuery(input)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &queryParser{tokens: tokens, now: now}
+	predicate, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if token := parser.peek(); token.kind != queryEOF {
+		return nil, &QueryError{Position: token.pos, Message: fmt.Sprintf("unexpected %q, expected 'and', 'or' or end of expression", token.text)}
+	}
+
+	return predicate, nil
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	token := p.tokens[p.pos]
+	if token.kind != queryEOF {
+		p.pos++
+	}
+	return token
+}
+
+// isKeyword reports whether the next token is the given keyword
+func (p *queryParser) isKeyword(keyword string) bool {
+	token := p.peek()
+	return token.kind == queryWord && strings.EqualFold(token.text, keyword)
+}
+
+func (p *queryParser) parseOr() (Predicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orPredicate{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (Predicate, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andPredicate{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (Predicate, error) {
+	if p.isKeyword("not") {
+		p.next()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notPredicate{inner}, nil
+	}
+
+	if p.peek().kind == queryLParen {
+		open := p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if token := p.next(); token.kind != queryRParen {
+			return nil, &QueryError{Position: open.pos, Message: "missing closing parenthesis"}
+		}
+		return inner, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *queryParser) parseComparison() (Predicate, error) {
+	field := p.next()
+	switch field.kind {
+	case queryEOF:
+		return nil, &QueryError{Position: field.pos, Message: "unexpected end of expression, expected a comparison"}
+	case queryWord:
+	default:
+		return nil, &QueryError{Position: field.pos, Message: fmt.Sprintf("unexpected %q, expected a field name", field.text)}
+	}
+
+	op := p.next()
+	if op.kind != queryOperator {
+		return nil, &QueryError{Position: op.pos, Message: fmt.Sprintf("expected an operator after %q (one of %s)", field.text, strings.Join(queryOperators, " "))}
+	}
+
+	value := p.next()
+	if value.kind != queryWord && value.kind != queryString {
+		return nil, &QueryError{Position: value.pos, Message: fmt.Sprintf("expected a value after %q", field.text+op.text)}
+	}
+
+	match, err := buildQueryMatcher(strings.ToLower(field.text), op.text, value.text, p.now)
+	if err != nil {
+		return nil, &QueryError{Position: field.pos, Message: err.Error()}
+	}
+
+	return matchPredicate{match}, nil
+}
+
+// buildQueryMatcher validates a single comparison and returns its evaluation function
+func buildQueryMatcher(field, op, value string, now time.Time) (func(Todo) bool, error) {
+	switch field {
+	case "task":
+		needle := strings.ToLower(value)
+		switch op {
+		case "=":
+			return func(todo Todo) bool { return strings.EqualFold(todo.Task, value) }, nil
+		case "!=":
+			return func(todo Todo) bool { return !strings.EqualFold(todo.Task, value) }, nil
+		case "~", ":":
+			return func(todo Todo) bool { return strings.Contains(strings.ToLower(todo.Task), needle) }, nil
+		case "!~":
+			return func(todo Todo) bool { return !strings.Contains(strings.ToLower(todo.Task), needle) }, nil
+		}
+
+	case "completed", "done":
+		var want bool
+		switch strings.ToLower(value) {
+		case "true", "yes", "1":
+			want = true
+		case "false", "no", "0":
+			want = false
+		default:
+			return nil, fmt.Errorf("invalid value %q for %s, expected true or false", value, field)
+		}
+		switch op {
+		case "=", ":":
+			return func(todo Todo) bool { return todo.Completed == want }, nil
+		case "!=":
+			return func(todo Todo) bool { return todo.Completed != want }, nil
+		}
+
+	case "priority":
+		priority, err := ParsePriority(value)
+		if err != nil {
+			return nil, err
+		}
+		// Compare on importance: high > medium > low > none
+		level := -priorityRank(priority)
+		if compare := compareOperator(op); compare != nil {
+			return func(todo Todo) bool { return compare(-priorityRank(todo.Priority) - level) }, nil
+		}
+
+	case "tag", "tags":
+		tag, err := NormalizeTag(value)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "=", ":":
+			return func(todo Todo) bool { return todo.HasTag(tag) }, nil
+		case "!=":
+			return func(todo Todo) bool { return !todo.HasTag(tag) }, nil
+		case "~":
+			return func(todo Todo) bool { return anyTagContains(todo.Tags, tag) }, nil
+		case "!~":
+			return func(todo Todo) bool { return !anyTagContains(todo.Tags, tag) }, nil
+		}
+
+	case "due", "created", "updated":
+		return buildDateMatcher(field, op, value, now)
+
+	default:
+		return nil, fmt.Errorf("unknown field %q (available: %s)", field, strings.Join(QueryFields, ", "))
+	}
+
+	return nil, fmt.Errorf("operator %q is not supported for %s", op, field)
+}
+
+// buildDateMatcher compares a timestamp field against an absolute or relative date.
+// Whole-day values such as 2025-08-01 or "today" compare by calendar day.
+func buildDateMatcher(field, op, value string, now time.Time) (func(Todo) bool, error) {
+	fieldValue := func(todo Todo) string {
+		switch field {
+		case "due":
+			return todo.DueAt
+		case "created":
+			return todo.CreatedAt
+		default:
+			return todo.UpdatedAt
+		}
+	}
+
+	if strings.EqualFold(value, "none") {
+		switch op {
+		case "=", ":":
+			return func(todo Todo) bool { return fieldValue(todo) == "" }, nil
+		case "!=":
+			return func(todo Todo) bool { return fieldValue(todo) != "" }, nil
+		}
+		return nil, fmt.Errorf("operator %q is not supported with none", op)
+	}
+
+	target, err := ParseDueDate(value, now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date for %s: %q", field, value)
+	}
+	wholeDay := target.Equal(endOfDay(target))
+	targetDay := target.Format("2006-01-02")
+
+	compare := compareOperator(op)
+	if compare == nil {
+		return nil, fmt.Errorf("operator %q is not supported for %s", op, field)
+	}
+
+	return func(todo Todo) bool {
+		timestamp, err := time.Parse(time.RFC3339, fieldValue(todo))
+		if err != nil {
+			return false
+		}
+		if wholeDay {
+			return compare(strings.Compare(timestamp.In(target.Location()).Format("2006-01-02"), targetDay))
+		}
+		return compare(timestamp.Compare(target))
+	}, nil
+}
+
+// compareOperator maps an ordering operator onto the result of a three-way comparison
+func compareOperator(op string) func(int) bool {
+	switch op {
+	case "=", ":":
+		return func(c int) bool { return c == 0 }
+	case "!=":
+		return func(c int) bool { return c != 0 }
+	case "<":
+		return func(c int) bool { return c < 0 }
+	case "<=":
+		return func(c int) bool { return c <= 0 }
+	case ">":
+		return func(c int) bool { return c > 0 }
+	case ">=":
+		return func(c int) bool { return c >= 0 }
+	}
+	return nil
+}
+
+// anyTagContains reports whether any tag contains the substring
+func anyTagContains(tags []string, substring string) bool {
+	for _, tag := range tags {
+		if strings.Contains(tag, substring) {
+			return true
+		}
+	}
+	return false
+}
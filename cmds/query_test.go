@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseQuery_Matches(t *testing.T) {
+	now := time.Date(2025, 8, 10, 12, 0, 0, 0, time.UTC)
+
+	deploy := Todo{
+		Task:      "Deploy backend",
+		Priority:  PriorityHigh,
+		Tags:      []string{"backend", "release"},
+		CreatedAt: "2025-08-05T09:00:00Z",
+		UpdatedAt: "2025-08-09T09:00:00Z",
+		DueAt:     "2025-08-12T23:59:59Z",
+	}
+	docs := Todo{
+		Task:        "Write docs",
+		Completed:   true,
+		Tags:        []string{"docs"},
+		CreatedAt:   "2025-07-20T09:00:00Z",
+		UpdatedAt:   "2025-08-01T09:00:00Z",
+		CompletedAt: "2025-08-01T09:00:00Z",
+	}
+
+	tests := []struct {
+		query       string
+		matchDeploy bool
+		matchDocs   bool
+	}{
+		{`completed=false`, true, false},
+		{`done=true`, false, true},
+		{`tag:backend`, true, false},
+		{`tag!=backend`, false, true},
+		{`tag~rel`, true, false},
+		{`task~"DEPLOY"`, true, false},
+		{`task!~deploy`, false, true},
+		{`task="write docs"`, false, true},
+		{`priority=high`, true, false},
+		{`priority>=medium`, true, false},
+		{`priority<low`, false, true},
+		{`created>2025-08-01`, true, false},
+		{`created<2025-08-05`, false, true},
+		{`created=2025-08-05`, true, false},
+		{`updated<=2025-08-01T09:00:00Z`, false, true},
+		{`due<today`, false, false},
+		{`due<="in 2d"`, true, false},
+		{`due=none`, false, true},
+		{`due!=none`, true, false},
+		{`completed=false and tag:backend and created>2025-08-01 and task~"deploy"`, true, false},
+		{`tag:docs or priority=high`, true, true},
+		{`not (tag:docs or priority=high)`, false, false},
+		{`NOT done=true AND tag:release`, true, false},
+		{`tag:docs or tag:backend and priority=low`, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			predicate, err := parseQuery(tt.query, now)
+			if err != nil {
+				t.Fatalf("parseQuery(%q) error = %v", tt.query, err)
+			}
+			if got := predicate.Matches(deploy); got != tt.matchDeploy {
+				t.Errorf("parseQuery(%q) matches deploy = %v, want %v", tt.query, got, tt.matchDeploy)
+			}
+			if got := predicate.Matches(docs); got != tt.matchDocs {
+				t.Errorf("parseQuery(%q) matches docs = %v, want %v", tt.query, got, tt.matchDocs)
+			}
+		})
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	tests := []struct {
+		query    string
+		position int
+	}{
+		{``, 1},
+		{`completed`, 10},
+		{`task~`, 6},
+		{`color=red`, 1},
+		{`completed=maybe`, 1},
+		{`priority>urgent`, 1},
+		{`tag<docs`, 1},
+		{`created>someday`, 1},
+		{`(done=true`, 1},
+		{`done=true task=x`, 11},
+		{`done=true and`, 14},
+		{`task="unterminated`, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := ParseQuery(tt.query)
+			if err == nil {
+				t.Fatalf("ParseQuery(%q) expected error", tt.query)
+			}
+
+			var queryErr *QueryError
+			if !errors.As(err, &queryErr) {
+				t.Fatalf("ParseQuery(%q) error type = %T, want *QueryError", tt.query, err)
+			}
+			if queryErr.Position != tt.position {
+				t.Errorf("ParseQuery(%q) error position = %d, want %d (%v)", tt.query, queryErr.Position, tt.position, err)
+			}
+		})
+	}
+}
+
+func TestTodoList_FilterWhere(t *testing.T) {
+	todoList := &TodoList{
+		{Task: "Open", Tags: []string{"backend"}},
+		{Task: "Done", Completed: true, Tags: []string{"backend"}},
+	}
+
+	predicate, err := ParseQuery("completed=true and tag:backend")
+	if err != nil {
+		t.Fatalf("ParseQuery() error = %v", err)
+	}
+	todoList.FilterWhere(predicate)
+
+	if len(*todoList) != 1 || (*todoList)[0].Task != "Done" {
+		t.Fatalf("FilterWhere() = %v, want only 'Done'", *todoList)
+	}
+	if id := (*todoList)[0].displayID(0); id != 2 {
+		t.Errorf("FilterWhere() display ID = %d, want 2", id)
+	}
+}
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+)
+
+// NewRedoCommand creates a new redo command for urfave/cli
+func NewRedoCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "redo",
+		Usage:     "Redo the last undone change to the todo list",
+		Aliases:   []string{"r"},
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "Redo even if the files changed since the entry was recorded",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			// Validate archive flag usage
+			if err := ValidateArchiveFlagUsage(c, "redo"); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// New local lists are only created by 'todo init'
+			if err := RequireTodoFile(GetStorageOptions(c)); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// Hold the list lock until every file is saved
+			lock, err := lockTodoList(c)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to acquire lock: %v", err), 2)
+			}
+			defer lock.Unlock()
+
+			entry, err := stepJournal(GetStorageOptions(c), false, c.Bool("force"))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to redo: %v", err), 2)
+			}
+
+			if entry == nil {
+				fmt.Println("Nothing to redo.")
+				return nil
+			}
+
+			fmt.Printf("Redid: %s\n", entry.Command)
+
+			// Check if --list flag is set and execute list command after redo
+			if CheckAndExecuteListFlag(c) {
+				if err := ExecuteListCommand(c); err != nil {
+					return cli.Exit(fmt.Sprintf("error executing list: %v", err), 2)
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+// Legacy command struct for backward compatibility
+type RedoCommand struct{}
+
+func init() {
+	RegisterCommand(&RedoCommand{})
+}
+
+func (c *RedoCommand) Name() string {
+	return "redo"
+}
+
+func (c *RedoCommand) Description() string {
+	return "Redo the last undone change to the todo list"
+}
+
+func (c *RedoCommand) Usage() string {
+	return "todo-cli redo [--force]"
+}
+
+func (c *RedoCommand) Execute(args []string, todoList TodoListInterface) error {
+	// Note: Legacy interface doesn't keep undo history
+	return fmt.Errorf("redo functionality not supported in legacy interface")
+}
@@ -0,0 +1,227 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// CurrentSchemaVersion is the todo file version written by Save.
+//
+//	1: bare array of the legacy root package shape, with a positional "id"
+//	2: bare array of items carrying an "internal_id"
+//	3: {"version":3,"items":[...]} envelope
+const CurrentSchemaVersion = 3
+
+// todoFileEnvelope is the on-disk shape of todo and archive files from version 3 on
+type todoFileEnvelope struct {
+	Version int    `json:"version"`
+	Items   []Todo `json:"items"`
+}
+
+// rawTodo is a stored item before migration; unknown keys survive until the final decode
+type rawTodo map[string]json.RawMessage
+
+// migration upgrades items from one schema version to the next
+type migration struct {
+	from        int
+	description string
+	apply       func(items []rawTodo) []string
+}
+
+// migrations run in order, starting with the one matching the file version
+var migrations = []migration{
+	{from: 1, description: "replace positional ids with internal_ids", apply: migrateV1ToV2},
+	{from: 2, description: "wrap items in a versioned envelope", apply: migrateV2ToV3},
+}
+
+// MigrationReport describes what loading a todo file changed to reach the current version
+type MigrationReport struct {
+	FromVersion int
+	ToVersion   int
+	Changes     []string // what the migrations did to the items
+	Backfilled  []int    // items (numbered from 1) that had no internal_id, at any version
+	Dropped     []string // fields this version does not know and will not keep
+}
+
+// NeedsMigration reports whether the file is older than the current version
+func (r MigrationReport) NeedsMigration() bool {
+	return r.FromVersion != r.ToVersion
+}
+
+// MarshalJSON writes the list in the current versioned envelope
+func (todoList TodoList) MarshalJSON() ([]byte, error) {
+	items := []Todo(todoList)
+	if items == nil {
+		items = []Todo{}
+	}
+	return json.Marshal(todoFileEnvelope{Version: CurrentSchemaVersion, Items: items})
+}
+
+// UnmarshalJSON reads any supported file version, migrating it in memory
+func (todoList *TodoList) UnmarshalJSON(data []byte) error {
+	items, _, err := migrateTodoData(data)
+	if err != nil {
+		return err
+	}
+	*todoList = items
+	return nil
+}
+
+// migrateTodoData decodes todo file content of any supported version and upgrades it
+func migrateTodoData(data []byte) (TodoList, MigrationReport, error) {
+	report := MigrationReport{ToVersion: CurrentSchemaVersion}
+
+	items, version, err := decodeRawTodos(data)
+	if err != nil {
+		return nil, report, err
+	}
+	report.FromVersion = version
+
+	for _, step := range migrations {
+		if step.from >= version {
+			report.Changes = append(report.Changes, step.apply(items)...)
+		}
+	}
+
+	// Items of any version can lack an internal_id after a hand edit or a merge
+	for index, item := range items {
+		if hasInternalID(item) {
+			continue
+		}
+		item["internal_id"] = backfilledInternalID(index, item)
+		report.Backfilled = append(report.Backfilled, index+1)
+		report.Changes = append(report.Changes, fmt.Sprintf("item %d: added internal_id %s", index+1, rawString(item["internal_id"])))
+	}
+	report.Dropped = unknownTodoFields(items)
+
+	// Decode the upgraded items through JSON so every field keeps its usual handling
+	upgraded, err := json.Marshal(items)
+	if err != nil {
+		return nil, report, err
+	}
+	var todos []Todo
+	if err := json.Unmarshal(upgraded, &todos); err != nil {
+		return nil, report, err
+	}
+
+	return TodoList(todos), report, nil
+}
+
+// decodeRawTodos splits file content into raw items and detects its schema version
+func decodeRawTodos(data []byte) ([]rawTodo, int, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, CurrentSchemaVersion, nil
+	}
+
+	var items []rawTodo
+	if trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, 0, err
+		}
+
+		// Only the legacy root package stored a positional id
+		for _, item := range items {
+			if _, ok := item["id"]; ok {
+				return items, 1, nil
+			}
+		}
+		return items, 2, nil
+	}
+
+	var envelope struct {
+		Version int       `json:"version"`
+		Items   []rawTodo `json:"items"`
+	}
+	if err := json.Unmarshal(trimmed, &envelope); err != nil {
+		return nil, 0, err
+	}
+	switch {
+	case envelope.Version == 0:
+		return nil, 0, fmt.Errorf("todo file has no schema version")
+	case envelope.Version > CurrentSchemaVersion:
+		return nil, 0, fmt.Errorf("todo file version %d is newer than the supported version %d; please upgrade todo", envelope.Version, CurrentSchemaVersion)
+	case envelope.Version < CurrentSchemaVersion:
+		return nil, 0, fmt.Errorf("todo file version %d cannot use the envelope format", envelope.Version)
+	}
+
+	return envelope.Items, envelope.Version, nil
+}
+
+// migrateV1ToV2 drops positional ids, which display order already provides, and gives
+// each item an internal_id
+func migrateV1ToV2(items []rawTodo) []string {
+	var changes []string
+	for index, item := range items {
+		if _, ok := item["id"]; !ok {
+			continue
+		}
+		delete(item, "id")
+		if !hasInternalID(item) {
+			item["internal_id"] = backfilledInternalID(index, item)
+		}
+		changes = append(changes, fmt.Sprintf("item %d: replaced positional id with internal_id %s", index+1, rawString(item["internal_id"])))
+	}
+	return changes
+}
+
+// migrateV2ToV3 only reports the envelope, which is written by Save. Missing
+// internal_ids are backfilled for every version by migrateTodoData.
+func migrateV2ToV3(items []rawTodo) []string {
+	return []string{fmt.Sprintf("wrapped %d item(s) in a version %d envelope", len(items), CurrentSchemaVersion)}
+}
+
+// hasInternalID reports whether a raw item carries a non-empty internal_id
+func hasInternalID(item rawTodo) bool {
+	return rawString(item["internal_id"]) != ""
+}
+
+// backfilledInternalID derives an internal ID from the item's position and content,
+// so unmigrated files give the same IDs on every load until they are saved
+func backfilledInternalID(index int, item rawTodo) json.RawMessage {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d\x00%s\x00%s", index, item["created_at"], item["task"])))
+	id, _ := json.Marshal(hex.EncodeToString(sum[:6]))
+	return id
+}
+
+// rawString decodes a raw JSON string, returning "" for anything else
+func rawString(raw json.RawMessage) string {
+	var value string
+	if json.Unmarshal(raw, &value) != nil {
+		return ""
+	}
+	return value
+}
+
+// unknownTodoFields lists fields Todo has no place for, one entry per field name
+func unknownTodoFields(items []rawTodo) []string {
+	known := make(map[string]bool)
+	todoType := reflect.TypeOf(Todo{})
+	for i := 0; i < todoType.NumField(); i++ {
+		if tag := todoType.Field(i).Tag.Get("json"); tag != "" {
+			known[strings.Split(tag, ",")[0]] = true
+		}
+	}
+
+	counts := make(map[string]int)
+	for _, item := range items {
+		for key := range item {
+			if !known[key] {
+				counts[key]++
+			}
+		}
+	}
+
+	var dropped []string
+	for key, count := range counts {
+		dropped = append(dropped, fmt.Sprintf("field %q on %d item(s) is not supported and will be dropped", key, count))
+	}
+	sort.Strings(dropped)
+	return dropped
+}
@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateTodoData_Versions(t *testing.T) {
+	tests := map[string]struct {
+		input       string
+		fromVersion int
+	}{
+		"v1 legacy array": {`[{"id":1,"task":"Old","completed":true,"created_at":"2025-01-01T00:00:00Z"}]`, 1},
+		"v2 array":        {`[{"internal_id":"a1b2c3d4e5f6","task":"Old","completed":true}]`, 2},
+		"v3 envelope":     {`{"version":3,"items":[{"internal_id":"a1b2c3d4e5f6","task":"Old","completed":true}]}`, 3},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			todos, report, err := migrateTodoData([]byte(test.input))
+			if err != nil {
+				t.Fatalf("migrateTodoData() error = %v", err)
+			}
+			if report.FromVersion != test.fromVersion || report.ToVersion != CurrentSchemaVersion {
+				t.Errorf("report versions = %d -> %d, want %d -> %d", report.FromVersion, report.ToVersion, test.fromVersion, CurrentSchemaVersion)
+			}
+			if len(todos) != 1 || todos[0].Task != "Old" || !todos[0].Completed || todos[0].InternalID == "" {
+				t.Errorf("migrateTodoData() = %+v", todos)
+			}
+			if len(report.Dropped) != 0 {
+				t.Errorf("report.Dropped = %v, want none", report.Dropped)
+			}
+		})
+	}
+}
+
+func TestMigrateTodoData_Backfill(t *testing.T) {
+	input := []byte(`[{"task":"One","created_at":"2025-01-01T00:00:00Z"},{"internal_id":"a1b2c3d4e5f6","task":"Two"},{"task":"One","created_at":"2025-01-01T00:00:00Z"}]`)
+
+	first, report, err := migrateTodoData(input)
+	if err != nil {
+		t.Fatalf("migrateTodoData() error = %v", err)
+	}
+	if len(report.Changes) != 3 {
+		t.Errorf("report.Changes = %v, want two backfills and the envelope", report.Changes)
+	}
+	if first[1].InternalID != "a1b2c3d4e5f6" {
+		t.Errorf("existing internal_id changed to %s", first[1].InternalID)
+	}
+	if first[0].InternalID == first[2].InternalID {
+		t.Errorf("identical items got the same internal_id %s", first[0].InternalID)
+	}
+
+	// Unsaved files must keep the same IDs on every load
+	second, _, _ := migrateTodoData(input)
+	if first[0].InternalID != second[0].InternalID {
+		t.Errorf("backfilled internal_id is not stable: %s then %s", first[0].InternalID, second[0].InternalID)
+	}
+}
+
+func TestMigrateTodoData_Errors(t *testing.T) {
+	tests := map[string]string{
+		"newer version": `{"version":99,"items":[]}`,
+		"no version":    `{"items":[]}`,
+		"malformed":     `[{"task":`,
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := migrateTodoData([]byte(input)); err == nil {
+				t.Errorf("migrateTodoData(%s) should fail", input)
+			}
+		})
+	}
+}
+
+func TestMigrateTodoData_ReportsDroppedFields(t *testing.T) {
+	_, report, err := migrateTodoData([]byte(`[{"internal_id":"a1b2c3d4e5f6","task":"One","estimate":3}]`))
+	if err != nil {
+		t.Fatalf("migrateTodoData() error = %v", err)
+	}
+	if len(report.Dropped) != 1 || !strings.Contains(report.Dropped[0], `"estimate"`) {
+		t.Errorf("report.Dropped = %v, want the estimate field", report.Dropped)
+	}
+}
+
+func TestTodoList_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal(TodoList(nil))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != `{"version":3,"items":[]}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+
+	var todoList TodoList
+	if err := json.Unmarshal([]byte(`{"version":3,"items":[{"internal_id":"a1b2c3d4e5f6","task":"One"}]}`), &todoList); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(todoList) != 1 || todoList[0].Task != "One" {
+		t.Errorf("Unmarshal() = %+v", todoList)
+	}
+}
+
+func TestMigrateTodoData_BackfillsCurrentVersion(t *testing.T) {
+	input := []byte(`{"version":3,"items":[{"internal_id":"a1b2c3d4e5f6","task":"One"},{"task":"Merged by hand"}]}`)
+
+	todos, report, err := migrateTodoData(input)
+	if err != nil {
+		t.Fatalf("migrateTodoData() error = %v", err)
+	}
+	if report.NeedsMigration() || len(report.Backfilled) != 1 || report.Backfilled[0] != 2 || len(report.Changes) != 1 {
+		t.Errorf("report = %+v, want item 2 backfilled without a migration", report)
+	}
+	if todos[1].InternalID == "" {
+		t.Errorf("item without internal_id was not backfilled")
+	}
+}
+
+func TestStorageLoad_WarnsAboutDroppedFields(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	path := filepath.Join(tempDir, "todos.json")
+	os.WriteFile(path, []byte(`{"version":3,"items":[{"internal_id":"a1b2c3d4e5f6","task":"One","estimate":3}]}`), 0644)
+
+	storage := NewStorage[TodoList](path)
+	todoList, err := storage.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(storage.dropped) != 1 || !strings.Contains(storage.dropped[0], `"estimate"`) {
+		t.Errorf("dropped = %v, want the estimate field to be warned about on save", storage.dropped)
+	}
+
+	// The warning is given once, by the save that drops the field
+	if err := storage.Save(todoList); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if storage.dropped != nil {
+		t.Errorf("dropped = %v after Save(), want it cleared", storage.dropped)
+	}
+}
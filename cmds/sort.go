@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AllowedSortKeys lists the keys accepted by list --sort
+var AllowedSortKeys = []string{"priority", "due", "created", "updated", "task"}
+
+// SortBy orders the todo list by the given key while keeping display IDs stable
+func (todoList *TodoList) SortBy(key string) error {
+	return todoList.sortBy(key)
+}
+
+func (todoList *TodoList) sortBy(key string) error {
+	var less func(a, b Todo) bool
+
+	switch key {
+	case "":
+		return nil
+	case "priority":
+		less = func(a, b Todo) bool {
+			return priorityRank(a.Priority) < priorityRank(b.Priority)
+		}
+	case "due":
+		// Items without a due date sort last
+		less = func(a, b Todo) bool {
+			return timestampLess(a.DueAt, b.DueAt)
+		}
+	case "created":
+		less = func(a, b Todo) bool {
+			return timestampLess(a.CreatedAt, b.CreatedAt)
+		}
+	case "updated":
+		less = func(a, b Todo) bool {
+			return timestampLess(a.UpdatedAt, b.UpdatedAt)
+		}
+	case "task":
+		less = func(a, b Todo) bool {
+			return strings.ToLower(a.Task) < strings.ToLower(b.Task)
+		}
+	default:
+		return fmt.Errorf("invalid sort key: %s. Allowed keys: %s", key, strings.Join(AllowedSortKeys, ", "))
+	}
+
+	// Remember the original positions so IDs still match the stored order
+	todoList.assignPositions()
+
+	t := *todoList
+	sort.SliceStable(t, func(i, j int) bool {
+		return less(t[i], t[j])
+	})
+
+	return nil
+}
+
+// assignPositions records each item's 1-based storage position before the list
+// is reordered or filtered, so views keep showing the IDs other commands accept
+func (todoList *TodoList) assignPositions() {
+	for index := range *todoList {
+		if (*todoList)[index].position == 0 {
+			(*todoList)[index].position = index + 1
+		}
+	}
+}
+
+// displayID returns the ID shown for the item at the given index of the view
+func (todo Todo) displayID(index int) int {
+	if todo.position > 0 {
+		return todo.position
+	}
+	return index + 1
+}
+
+// timestampLess orders two RFC 3339 timestamps by the time they name, so
+// different offsets compare correctly; empty or unparseable values sort last
+func timestampLess(a, b string) bool {
+	timeA, errA := time.Parse(time.RFC3339, a)
+	timeB, errB := time.Parse(time.RFC3339, b)
+	if errA != nil || errB != nil {
+		return errA == nil && errB != nil
+	}
+	return timeA.Before(timeB)
+}
@@ -0,0 +1,85 @@
+package commands
+
+import "testing"
+
+func TestTodoList_SortByPriority(t *testing.T) {
+	todoList := &TodoList{
+		{Task: "No priority"},
+		{Task: "Low", Priority: PriorityLow},
+		{Task: "High", Priority: PriorityHigh},
+		{Task: "Medium", Priority: PriorityMedium},
+	}
+
+	if err := todoList.SortBy("priority"); err != nil {
+		t.Fatalf("SortBy() error = %v", err)
+	}
+
+	expected := []struct {
+		task string
+		id   int
+	}{
+		{"High", 3},
+		{"Medium", 4},
+		{"Low", 2},
+		{"No priority", 1},
+	}
+
+	for index, want := range expected {
+		todo := (*todoList)[index]
+		if todo.Task != want.task {
+			t.Errorf("SortBy() position %d task = %q, want %q", index, todo.Task, want.task)
+		}
+		if id := todo.displayID(index); id != want.id {
+			t.Errorf("SortBy() position %d display ID = %d, want %d", index, id, want.id)
+		}
+	}
+}
+
+func TestTodoList_SortByInvalidKey(t *testing.T) {
+	todoList := &TodoList{{Task: "Task"}}
+
+	if err := todoList.SortBy("color"); err == nil {
+		t.Errorf("SortBy() expected error for invalid key")
+	}
+
+	if err := todoList.SortBy(""); err != nil {
+		t.Errorf("SortBy(\"\") error = %v, want nil", err)
+	}
+}
+
+func TestTodoList_FilterIncompleteKeepsDisplayIDs(t *testing.T) {
+	todoList := &TodoList{
+		{Task: "Done", Completed: true},
+		{Task: "Open"},
+	}
+
+	todoList.FilterIncomplete()
+
+	if len(*todoList) != 1 {
+		t.Fatalf("FilterIncomplete() length = %d, want 1", len(*todoList))
+	}
+	if id := (*todoList)[0].displayID(0); id != 2 {
+		t.Errorf("FilterIncomplete() display ID = %d, want 2", id)
+	}
+}
+
+func TestTodoList_SortByTimestamps(t *testing.T) {
+	// As strings "2025-01-01T09:00:00+02:00" sorts after the UTC time it precedes
+	todoList := &TodoList{
+		{Task: "Missing"},
+		{Task: "Later", CreatedAt: "2025-01-01T08:00:00Z"},
+		{Task: "Invalid", CreatedAt: "yesterday"},
+		{Task: "Earlier", CreatedAt: "2025-01-01T09:00:00+02:00"},
+	}
+
+	if err := todoList.SortBy("created"); err != nil {
+		t.Fatalf("SortBy() error = %v", err)
+	}
+
+	expected := []string{"Earlier", "Later", "Missing", "Invalid"}
+	for index, want := range expected {
+		if task := (*todoList)[index].Task; task != want {
+			t.Errorf("SortBy() position %d task = %q, want %q", index, task, want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bennthewolfe/todo-cli/backend"
+	"github.com/urfave/cli/v3"
+)
+
+// defaultMarkdownFile is the checklist synced when sync-md isn't given a file
+const defaultMarkdownFile = "TODO.md"
+
+// NewSyncMDCommand creates a new sync-md command for urfave/cli
+func NewSyncMDCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "sync-md",
+		Usage:     fmt.Sprintf("Sync checkboxes and new items between a markdown checklist (%s) and the todo list", defaultMarkdownFile),
+		ArgsUsage: "[file]",
+		Action: func(ctx context.Context, c *cli.Command) error {
+			// Validate archive flag usage
+			if err := ValidateArchiveFlagUsage(c, "sync-md"); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			if c.Args().Len() > 1 {
+				return cli.Exit("only one markdown file can be synced at a time", 1)
+			}
+			file := defaultMarkdownFile
+			if c.Args().Len() == 1 {
+				file = c.Args().First()
+			}
+
+			// New local lists are only created by 'todo init'
+			if err := RequireTodoFile(GetStorageOptions(c)); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// Hold the list lock until the list is saved
+			lock, err := lockTodoList(c)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to acquire lock: %v", err), 2)
+			}
+			defer lock.Unlock()
+
+			// A missing file is created from the list
+			var document []byte
+			var modified time.Time
+			if info, err := os.Stat(file); err == nil {
+				modified = info.ModTime()
+				if document, err = os.ReadFile(file); err != nil {
+					return cli.Exit(fmt.Sprintf("error reading %s: %v", file, err), 1)
+				}
+			} else if !os.IsNotExist(err) {
+				return cli.Exit(fmt.Sprintf("error reading %s: %v", file, err), 1)
+			}
+
+			// Get the appropriate storage path for the selected list
+			storagePath, err := GetStoragePath(GetStorageOptions(c))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
+			}
+
+			todoList, storage, err := initializeTodoListWithPath(storagePath)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to initialize todo list: %v", err), 2)
+			}
+
+			synced, result := syncMarkdown(string(document), todoList, modified, time.Now())
+
+			if result.Changed() {
+				if err := storage.Save(*todoList); err != nil {
+					return cli.Exit(fmt.Sprintf("error saving todos: %v", err), 2)
+				}
+			}
+
+			if synced != string(document) {
+				if err := backend.WriteFileAtomic(file, []byte(synced), 0644); err != nil {
+					// Put the list back, so the list and the file don't drift apart unrecorded
+					if result.Changed() {
+						if restoreErr := storage.writeRaw(storage.loaded); restoreErr != nil {
+							return cli.Exit(fmt.Sprintf("error writing %s: %v (and restoring the list failed: %v)", file, err, restoreErr), 2)
+						}
+					}
+					return cli.Exit(fmt.Sprintf("error writing %s: %v", file, err), 2)
+				}
+			}
+
+			// Record the list and the markdown file together, so undo leaves no
+			// marker lines for items it removes from the list
+			markdownChange, err := fileJournalChange(file, document, []byte(synced))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error recording undo history: %v", err), 2)
+			}
+			if err := recordJournalFiles(c, []JournalChange{markdownChange}, storage); err != nil {
+				return cli.Exit(fmt.Sprintf("error recording undo history: %v", err), 2)
+			}
+
+			fmt.Printf("Synced %s with the todo list\n", file)
+			fmt.Printf("  List: %d item(s) added, %d item(s) updated\n", result.AddedToList, result.UpdatedInList)
+			fmt.Printf("  %s: %d line(s) added, %d checkbox(es) updated, %d line(s) removed\n", file, result.AddedToFile, result.UpdatedInFile, result.Removed)
+
+			// Check if --list flag is set and execute list command after syncing
+			if CheckAndExecuteListFlag(c) {
+				if err := ExecuteListCommand(c); err != nil {
+					return cli.Exit(fmt.Sprintf("error executing list: %v", err), 2)
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+// Legacy command struct for backward compatibility
+type SyncMDCommand struct{}
+
+func init() {
+	RegisterCommand(&SyncMDCommand{})
+}
+
+func (c *SyncMDCommand) Name() string {
+	return "sync-md"
+}
+
+func (c *SyncMDCommand) Description() string {
+	return "Sync a markdown checklist with the todo list"
+}
+
+func (c *SyncMDCommand) Usage() string {
+	return "todo-cli sync-md [file]"
+}
+
+func (c *SyncMDCommand) Execute(args []string, todoList TodoListInterface) error {
+	// Note: Legacy interface works on an already loaded list
+	return fmt.Errorf("sync-md functionality not supported in legacy interface")
+}
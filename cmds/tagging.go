@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NormalizeTag lowercases a tag and strips a leading '+' marker
+func NormalizeTag(tag string) (string, error) {
+	normalized := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "+"))
+	if normalized == "" {
+		return "", fmt.Errorf("tag must not be empty")
+	}
+	if strings.ContainsAny(normalized, " \t+!,") {
+		return "", fmt.Errorf("invalid tag: %s (tags cannot contain spaces, '+', '!' or ',')", tag)
+	}
+	return normalized, nil
+}
+
+// ExtractTags removes +tag tokens from a task description and returns the
+// remaining text along with the normalized tags found
+func ExtractTags(task string) (string, []string) {
+	var words []string
+	var tags []string
+
+	for _, word := range strings.Fields(task) {
+		if len(word) > 1 && strings.HasPrefix(word, "+") {
+			if tag, err := NormalizeTag(word); err == nil {
+				tags = appendUniqueTags(tags, tag)
+				continue
+			}
+		}
+		words = append(words, word)
+	}
+
+	return strings.Join(words, " "), tags
+}
+
+// ParseTagFilters splits tag filter values into included and excluded tags.
+// Values prefixed with '!' are excluded.
+func ParseTagFilters(values []string) ([]string, []string, error) {
+	var include, exclude []string
+
+	for _, value := range values {
+		negate := strings.HasPrefix(value, "!")
+		tag, err := NormalizeTag(strings.TrimPrefix(value, "!"))
+		if err != nil {
+			return nil, nil, err
+		}
+		if negate {
+			exclude = appendUniqueTags(exclude, tag)
+		} else {
+			include = appendUniqueTags(include, tag)
+		}
+	}
+
+	return include, exclude, nil
+}
+
+// appendUniqueTags appends tags that aren't already present
+func appendUniqueTags(tags []string, extra ...string) []string {
+	for _, tag := range extra {
+		if !containsTag(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// containsTag reports whether tags contains tag
+func containsTag(tags []string, tag string) bool {
+	for _, existing := range tags {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTag reports whether the todo carries the given tag
+func (todo Todo) HasTag(tag string) bool {
+	return containsTag(todo.Tags, tag)
+}
+
+// AddTags adds tags to the item at index, ignoring duplicates
+func (todoList *TodoList) AddTags(index int, tags []string) error {
+	return todoList.addTags(index, tags)
+}
+
+// RemoveTags removes tags from the item at index
+func (todoList *TodoList) RemoveTags(index int, tags []string) error {
+	return todoList.removeTags(index, tags)
+}
+
+// FilterTags keeps only items that have every included tag and none of the excluded ones
+func (todoList *TodoList) FilterTags(include, exclude []string) {
+	todoList.filter(func(todo Todo) bool {
+		for _, tag := range include {
+			if !todo.HasTag(tag) {
+				return false
+			}
+		}
+		for _, tag := range exclude {
+			if todo.HasTag(tag) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// TagCount holds the number of open and completed items for a tag
+type TagCount struct {
+	Tag       string `json:"tag"`
+	Open      int    `json:"open"`
+	Completed int    `json:"completed"`
+}
+
+// CountTags returns every tag in the list with its open/completed counts, sorted by tag
+func (todoList *TodoList) CountTags() []TagCount {
+	counts := make(map[string]*TagCount)
+
+	for _, todo := range *todoList {
+		for _, tag := range todo.Tags {
+			count, exists := counts[tag]
+			if !exists {
+				count = &TagCount{Tag: tag}
+				counts[tag] = count
+			}
+			if todo.Completed {
+				count.Completed++
+			} else {
+				count.Open++
+			}
+		}
+	}
+
+	result := make([]TagCount, 0, len(counts))
+	for _, count := range counts {
+		result = append(result, *count)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Tag < result[j].Tag
+	})
+
+	return result
+}
+
+func (todoList *TodoList) addTags(index int, tags []string) error {
+	t := *todoList
+
+	// Validate the index before attempting to update
+	if err := t.validateIndex(index); err != nil {
+		return err
+	}
+
+	t[index].Tags = appendUniqueTags(t[index].Tags, tags...)
+	t[index].UpdatedAt = time.Now().Format(time.RFC3339)
+
+	return nil
+}
+
+func (todoList *TodoList) removeTags(index int, tags []string) error {
+	t := *todoList
+
+	// Validate the index before attempting to update
+	if err := t.validateIndex(index); err != nil {
+		return err
+	}
+
+	var remaining []string
+	for _, tag := range t[index].Tags {
+		if !containsTag(tags, tag) {
+			remaining = append(remaining, tag)
+		}
+	}
+
+	t[index].Tags = remaining
+	t[index].UpdatedAt = time.Now().Format(time.RFC3339)
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractTags(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedTask string
+		expectedTags []string
+	}{
+		{"Deploy +backend service", "Deploy service", []string{"backend"}},
+		{"+Release notes +docs +release", "notes", []string{"release", "docs"}},
+		{"Learn C++ basics", "Learn C++ basics", nil},
+		{"Sum 1 + 2", "Sum 1 + 2", nil},
+		{"No tags here", "No tags here", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			task, tags := ExtractTags(tt.input)
+			if task != tt.expectedTask {
+				t.Errorf("ExtractTags(%q) task = %q, want %q", tt.input, task, tt.expectedTask)
+			}
+			if !reflect.DeepEqual(tags, tt.expectedTags) {
+				t.Errorf("ExtractTags(%q) tags = %v, want %v", tt.input, tags, tt.expectedTags)
+			}
+		})
+	}
+}
+
+func TestParseTagFilters(t *testing.T) {
+	include, exclude, err := ParseTagFilters([]string{"backend", "!docs", "+Release"})
+	if err != nil {
+		t.Fatalf("ParseTagFilters() error = %v", err)
+	}
+	if !reflect.DeepEqual(include, []string{"backend", "release"}) {
+		t.Errorf("ParseTagFilters() include = %v", include)
+	}
+	if !reflect.DeepEqual(exclude, []string{"docs"}) {
+		t.Errorf("ParseTagFilters() exclude = %v", exclude)
+	}
+
+	if _, _, err := ParseTagFilters([]string{"!"}); err == nil {
+		t.Errorf("ParseTagFilters() expected error for empty tag")
+	}
+}
+
+func TestTodoList_FilterTags(t *testing.T) {
+	todoList := &TodoList{
+		{Task: "Backend docs", Tags: []string{"backend", "docs"}},
+		{Task: "Backend", Tags: []string{"backend"}},
+		{Task: "Untagged"},
+	}
+
+	todoList.FilterTags([]string{"backend"}, []string{"docs"})
+
+	if len(*todoList) != 1 || (*todoList)[0].Task != "Backend" {
+		t.Fatalf("FilterTags() = %v, want only 'Backend'", *todoList)
+	}
+	if id := (*todoList)[0].displayID(0); id != 2 {
+		t.Errorf("FilterTags() display ID = %d, want 2", id)
+	}
+}
+
+func TestTodoList_AddRemoveTags(t *testing.T) {
+	todoList := &TodoList{{Task: "Task", Tags: []string{"backend"}}}
+
+	if err := todoList.AddTags(0, []string{"backend", "release"}); err != nil {
+		t.Fatalf("AddTags() error = %v", err)
+	}
+	if !reflect.DeepEqual((*todoList)[0].Tags, []string{"backend", "release"}) {
+		t.Errorf("AddTags() tags = %v", (*todoList)[0].Tags)
+	}
+
+	if err := todoList.RemoveTags(0, []string{"backend"}); err != nil {
+		t.Fatalf("RemoveTags() error = %v", err)
+	}
+	if !reflect.DeepEqual((*todoList)[0].Tags, []string{"release"}) {
+		t.Errorf("RemoveTags() tags = %v", (*todoList)[0].Tags)
+	}
+
+	if err := todoList.AddTags(3, []string{"x"}); err == nil {
+		t.Errorf("AddTags() expected error for invalid index")
+	}
+}
+
+func TestTodoList_CountTags(t *testing.T) {
+	todoList := &TodoList{
+		{Task: "A", Tags: []string{"backend", "docs"}},
+		{Task: "B", Tags: []string{"backend"}, Completed: true},
+		{Task: "C"},
+	}
+
+	expected := []TagCount{
+		{Tag: "backend", Open: 1, Completed: 1},
+		{Tag: "docs", Open: 1, Completed: 0},
+	}
+
+	if got := todoList.CountTags(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("CountTags() = %v, want %v", got, expected)
+	}
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aquasecurity/table"
+	"github.com/urfave/cli/v3"
+)
+
+// NewTagsCommand creates a new tags command for urfave/cli
+func NewTagsCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "tags",
+		Usage:     "List all tags with open and completed counts",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "Output format (table, json)",
+				Value:   "table",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			// Validate archive flag usage
+			if err := ValidateArchiveFlagUsage(c, "tags"); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			format := c.String("format")
+			if format != "table" && format != "json" {
+				return cli.Exit(fmt.Sprintf("invalid format: %s. Allowed formats: table, json", format), 1)
+			}
+
+			// Get the appropriate storage path for the selected list
+			storagePath, err := GetStoragePath(GetStorageOptions(c))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
+			}
+
+			// Initialize todo list and storage
+			todoList, _, err := initializeTodoListWithPath(storagePath)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to initialize todo list: %v", err), 2)
+			}
+
+			counts := todoList.CountTags()
+
+			if format == "json" {
+				jsonOutput, err := json.Marshal(counts)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("error marshaling JSON: %v", err), 2)
+				}
+				fmt.Println(string(jsonOutput))
+				return nil
+			}
+
+			if len(counts) == 0 {
+				fmt.Println("No tags found.")
+				return nil
+			}
+
+			t := table.New(os.Stdout)
+			t.SetRowLines(false)
+			t.SetHeaders("Tag", "Open", "Completed")
+			for _, count := range counts {
+				t.AddRow(count.Tag, fmt.Sprintf("%d", count.Open), fmt.Sprintf("%d", count.Completed))
+			}
+			t.Render()
+
+			return nil
+		},
+	}
+}
+
+// Legacy command struct for backward compatibility
+type TagsCommand struct{}
+
+func init() {
+	RegisterCommand(&TagsCommand{})
+}
+
+func (c *TagsCommand) Name() string {
+	return "tags"
+}
+
+func (c *TagsCommand) Description() string {
+	return "List all tags with open and completed counts"
+}
+
+func (c *TagsCommand) Usage() string {
+	return "todo-cli tags"
+}
+
+func (c *TagsCommand) Execute(args []string, todoList TodoListInterface) error {
+	// Note: Legacy interface doesn't expose tags
+	return fmt.Errorf("tags functionality not supported in legacy interface")
+}
@@ -0,0 +1,341 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Taskwarrior's 'task export' writes a JSON array of tasks such as
+//
+//	{"id":1,"uuid":"5f0c…","description":"Fix the gate","status":"pending","entry":"20250901T100000Z",
+//	 "modified":"20250902T100000Z","due":"20250905T220000Z","priority":"H","project":"Home.Garden",
+//	 "tags":["diy"],"annotations":[{"entry":"20250902T100000Z","description":"Buy hinges"}],"urgency":9.1}
+//
+// Projects become a "project:<name>" tag and annotations extra lines of the task text, so
+// exporting the list gives them back. Taskwarrior needs a UUID for every task, so items
+// keep their internal ID in an internal_id attribute, which 'task import' preserves.
+const (
+	taskwarriorTimeLayout = "20060102T150405Z"
+
+	taskwarriorProjectPrefix = "project:"
+)
+
+// taskwarriorPriorities maps priorities to Taskwarrior's H, M and L
+var taskwarriorPriorities = map[string]string{
+	PriorityHigh:   "H",
+	PriorityMedium: "M",
+	PriorityLow:    "L",
+}
+
+// taskwarriorDerivedFields are computed by Taskwarrior and have nothing to carry over
+var taskwarriorDerivedFields = map[string]bool{
+	"id":      true,
+	"urgency": true,
+}
+
+var taskwarriorUUIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// taskwarriorTask holds the fields of a Taskwarrior task that map onto an item
+type taskwarriorTask struct {
+	UUID        string                  `json:"uuid"`
+	Description string                  `json:"description"`
+	Status      string                  `json:"status"`
+	Entry       string                  `json:"entry,omitempty"`
+	Modified    string                  `json:"modified,omitempty"`
+	End         string                  `json:"end,omitempty"`
+	Due         string                  `json:"due,omitempty"`
+	Priority    string                  `json:"priority,omitempty"`
+	Project     string                  `json:"project,omitempty"`
+	Tags        []string                `json:"tags,omitempty"`
+	Annotations []taskwarriorAnnotation `json:"annotations,omitempty"`
+	InternalID  string                  `json:"internal_id,omitempty"`
+}
+
+// taskwarriorAnnotation is a timestamped note on a task
+type taskwarriorAnnotation struct {
+	Entry       string `json:"entry,omitempty"`
+	Description string `json:"description"`
+}
+
+// encodeTaskwarrior writes a list as a Taskwarrior JSON array, one task per line like 'task export'
+func encodeTaskwarrior(todoList TodoList) ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString("[\n")
+	for index, todo := range todoList {
+		line, err := json.Marshal(taskwarriorFromTodo(todo))
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling data to JSON: %w", err)
+		}
+		buffer.Write(line)
+		if index < len(todoList)-1 {
+			buffer.WriteByte(',')
+		}
+		buffer.WriteByte('\n')
+	}
+	buffer.WriteString("]\n")
+	return buffer.Bytes(), nil
+}
+
+// taskwarriorFromTodo converts an item to a Taskwarrior task
+func taskwarriorFromTodo(todo Todo) taskwarriorTask {
+	lines := strings.Split(todo.Task, "\n")
+	task := taskwarriorTask{
+		UUID:        taskwarriorUUID(todo.InternalID),
+		Description: lines[0],
+		Status:      "pending",
+		Entry:       taskwarriorTime(todo.CreatedAt),
+		Modified:    taskwarriorTime(todo.UpdatedAt),
+		Priority:    taskwarriorPriorities[todo.Priority],
+	}
+	if task.UUID != todo.InternalID {
+		task.InternalID = todo.InternalID
+	}
+	if todo.Completed {
+		task.Status = "completed"
+		task.End = taskwarriorTime(todo.CompletedAt)
+		if task.End == "" {
+			task.End = task.Modified
+		}
+	}
+
+	// Taskwarrior keeps a due date as local midnight, 'todo add --due' as the end of the day
+	if due, err := time.Parse(time.RFC3339, todo.DueAt); err == nil {
+		if local := due.In(time.Local); endOfDay(local).Equal(due) {
+			due = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.Local)
+		}
+		task.Due = due.UTC().Format(taskwarriorTimeLayout)
+	}
+
+	for _, tag := range todo.Tags {
+		if project, found := strings.CutPrefix(tag, taskwarriorProjectPrefix); found && task.Project == "" && project != "" {
+			task.Project = project
+			continue
+		}
+		task.Tags = append(task.Tags, tag)
+	}
+	for _, line := range lines[1:] {
+		if line = strings.TrimSpace(line); line != "" {
+			task.Annotations = append(task.Annotations, taskwarriorAnnotation{Entry: task.Modified, Description: line})
+		}
+	}
+
+	return task
+}
+
+// taskwarriorUUID returns an internal ID that is already a UUID, or a UUID derived from
+// it with SHA-1 so that every export gives an item the same uuid
+func taskwarriorUUID(internalID string) string {
+	if taskwarriorUUIDPattern.MatchString(internalID) {
+		return strings.ToLower(internalID)
+	}
+
+	sum := sha1.Sum([]byte(internalID))
+	sum[6] = sum[6]&0x0f | 0x50 // version 5, name-based with SHA-1
+	sum[8] = sum[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// taskwarriorTime converts a stored RFC3339 timestamp to Taskwarrior's UTC format
+func taskwarriorTime(timestamp string) string {
+	parsed, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return ""
+	}
+	return parsed.UTC().Format(taskwarriorTimeLayout)
+}
+
+// decodeTaskwarrior reads 'task export' output: a JSON array, or one task object per
+// line as older versions wrote it. Deleted tasks and recurring templates are skipped.
+// Warnings name the fields and values that weren't imported.
+func decodeTaskwarrior(fileData []byte) (TodoList, []string, error) {
+	objects, err := splitTaskwarriorExport(fileData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var todoList TodoList
+	var warnings []string
+	unmapped := make(map[string]int)
+	for index, object := range objects {
+		number := index + 1
+
+		var task taskwarriorTask
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(object, &task); err != nil {
+			return nil, nil, fmt.Errorf("task %d: %w", number, err)
+		}
+		if err := json.Unmarshal(object, &fields); err != nil {
+			return nil, nil, fmt.Errorf("task %d: %w", number, err)
+		}
+
+		todo, taskWarnings, keep := todoFromTaskwarrior(task)
+		for _, warning := range taskWarnings {
+			warnings = append(warnings, fmt.Sprintf("task %d: %s", number, warning))
+		}
+		if !keep {
+			continue
+		}
+		todoList = append(todoList, todo)
+
+		// Note fields that have no place in an item
+		for key := range fields {
+			if !taskwarriorDerivedFields[key] && !isTaskwarriorField(key) {
+				unmapped[key]++
+			}
+		}
+	}
+
+	var dropped []string
+	for key, count := range unmapped {
+		dropped = append(dropped, fmt.Sprintf("field %q on %d task(s) not imported", key, count))
+	}
+	sort.Strings(dropped)
+
+	return todoList, append(warnings, dropped...), nil
+}
+
+// splitTaskwarriorExport splits an export into its task objects
+func splitTaskwarriorExport(fileData []byte) ([]json.RawMessage, error) {
+	var objects []json.RawMessage
+	if trimmed := bytes.TrimSpace(fileData); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &objects); err != nil {
+			return nil, err
+		}
+		return objects, nil
+	}
+
+	// Older versions wrote one object per line, separated by commas
+	for number, line := range bytes.Split(fileData, []byte("\n")) {
+		line = bytes.TrimSuffix(bytes.TrimSpace(line), []byte(","))
+		if len(line) == 0 {
+			continue
+		}
+		if !json.Valid(line) {
+			return nil, fmt.Errorf("line %d: not a JSON task", number+1)
+		}
+		objects = append(objects, json.RawMessage(line))
+	}
+	return objects, nil
+}
+
+// isTaskwarriorField reports whether a field is read into items
+func isTaskwarriorField(key string) bool {
+	switch key {
+	case "uuid", "description", "status", "entry", "modified", "end", "due", "priority", "project", "tags", "annotations", "internal_id":
+		return true
+	}
+	return false
+}
+
+// todoFromTaskwarrior converts a Taskwarrior task, reporting whether it should be imported
+func todoFromTaskwarrior(task taskwarriorTask) (Todo, []string, bool) {
+	var warnings []string
+
+	switch task.Status {
+	case "pending", "waiting", "completed":
+	case "deleted":
+		return Todo{}, []string{"skipped, it was deleted"}, false
+	case "recurring":
+		return Todo{}, []string{"skipped the recurring template (its pending instances are imported)"}, false
+	default:
+		warnings = append(warnings, fmt.Sprintf("unknown status %q imported as pending", task.Status))
+	}
+
+	todo := Todo{
+		InternalID: task.InternalID,
+		Task:       strings.TrimSpace(task.Description),
+		Completed:  task.Status == "completed",
+	}
+	if todo.Task == "" {
+		return Todo{}, append(warnings, "skipped, it has no description"), false
+	}
+	if todo.InternalID == "" {
+		todo.InternalID = task.UUID
+	}
+	if todo.InternalID == "" {
+		todo.InternalID = generateShortGUID()
+	}
+
+	times := []struct {
+		field  string
+		value  string
+		target *string
+	}{{"entry", task.Entry, &todo.CreatedAt}, {"modified", task.Modified, &todo.UpdatedAt}, {"end", task.End, &todo.CompletedAt}, {"due", task.Due, &todo.DueAt}}
+	for _, timestamp := range times {
+		if timestamp.value == "" {
+			continue
+		}
+		parsed, err := parseTaskwarriorTime(timestamp.value)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("invalid %s: %s", timestamp.field, timestamp.value))
+			continue
+		}
+		*timestamp.target = parsed.In(time.Local).Format(time.RFC3339)
+	}
+
+	// A due date without a time is local midnight in Taskwarrior and the end of the day here
+	if due, err := time.Parse(time.RFC3339, todo.DueAt); err == nil && due.Hour() == 0 && due.Minute() == 0 && due.Second() == 0 {
+		todo.DueAt = endOfDay(due).Format(time.RFC3339)
+	}
+
+	if task.Priority != "" {
+		for priority, letter := range taskwarriorPriorities {
+			if strings.EqualFold(task.Priority, letter) {
+				todo.Priority = priority
+			}
+		}
+		if todo.Priority == "" {
+			warnings = append(warnings, fmt.Sprintf("unknown priority %q dropped", task.Priority))
+		}
+	}
+
+	if task.Project != "" {
+		if tag, err := NormalizeTag(taskwarriorProjectPrefix + strings.Join(strings.Fields(task.Project), "-")); err == nil {
+			todo.Tags = appendUniqueTags(todo.Tags, tag)
+		} else {
+			warnings = append(warnings, fmt.Sprintf("project %q not imported: %v", task.Project, err))
+		}
+	}
+	for _, tag := range task.Tags {
+		normalized, err := NormalizeTag(tag)
+		if err != nil {
+			warnings = append(warnings, err.Error())
+			continue
+		}
+		todo.Tags = appendUniqueTags(todo.Tags, normalized)
+	}
+
+	for _, annotation := range task.Annotations {
+		if note := strings.TrimSpace(annotation.Description); note != "" {
+			todo.Task += "\n" + note
+		}
+	}
+
+	if todo.CreatedAt == "" {
+		todo.CreatedAt = time.Now().Format(time.RFC3339)
+	}
+	if todo.UpdatedAt == "" {
+		todo.UpdatedAt = todo.CreatedAt
+	}
+	if !todo.Completed {
+		todo.CompletedAt = ""
+	} else if todo.CompletedAt == "" {
+		todo.CompletedAt = todo.UpdatedAt
+	}
+
+	return todo, warnings, true
+}
+
+// parseTaskwarriorTime reads Taskwarrior's UTC format, or RFC3339 as some tools write it
+func parseTaskwarriorTime(value string) (time.Time, error) {
+	if parsed, err := time.Parse(taskwarriorTimeLayout, value); err == nil {
+		return parsed, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportImport_TaskwarriorRoundTrip(t *testing.T) {
+	endOfDue := endOfDay(time.Date(2025, 9, 5, 0, 0, 0, 0, time.Local)).Format(time.RFC3339)
+	local := func(timestamp string) string {
+		parsed, _ := time.Parse(time.RFC3339, timestamp)
+		return parsed.In(time.Local).Format(time.RFC3339)
+	}
+
+	todoList := TodoList{
+		{InternalID: "a1b2c3d4e5f6", Task: "Write docs\nStart with the README", Priority: "high", Tags: []string{"docs", "project:site"}, CreatedAt: local("2025-09-01T10:00:00Z"), UpdatedAt: local("2025-09-02T10:00:00Z"), DueAt: endOfDue},
+		{InternalID: "b2c3d4e5f6a1", Task: "Ship", Priority: "low", Completed: true, CreatedAt: local("2025-09-01T12:00:00+02:00"), UpdatedAt: local("2025-09-03T10:00:00Z"), CompletedAt: local("2025-09-03T10:00:00Z"), DueAt: local("2025-09-05T12:30:00Z")},
+		{InternalID: "5f0c6b3e-8a52-4c1e-9d3a-2b7f1e4c9a10", Task: "Imported from Taskwarrior", Tags: []string{"@office"}, CreatedAt: local("2025-09-04T08:00:00Z"), UpdatedAt: local("2025-09-04T08:00:00Z")},
+	}
+
+	fileData, err := encodeExport(todoList, "taskwarrior")
+	if err != nil {
+		t.Fatalf("encodeExport() error = %v", err)
+	}
+
+	imported, warnings, err := decodeImport(fileData, "taskwarrior", nil)
+	if err != nil {
+		t.Fatalf("decodeImport() error = %v", err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("decodeImport() warnings = %v", warnings)
+	}
+
+	// Projects are exported after the tags
+	todoList[0].Tags = []string{"project:site", "docs"}
+	if !reflect.DeepEqual(imported, todoList) {
+		t.Errorf("decodeImport() = %+v\nwant %+v\nfrom:\n%s", imported, todoList, fileData)
+	}
+}
+
+func TestTaskwarriorFromTodo(t *testing.T) {
+	midnight := time.Date(2025, 9, 5, 0, 0, 0, 0, time.Local)
+	todo := Todo{
+		InternalID: "a1b2c3d4e5f6",
+		Task:       "Write docs\nStart with the README",
+		Priority:   "medium",
+		Tags:       []string{"docs", "project:site"},
+		DueAt:      endOfDay(midnight).Format(time.RFC3339),
+		CreatedAt:  "2025-09-01T10:00:00Z",
+		UpdatedAt:  "2025-09-02T10:00:00Z",
+	}
+
+	task := taskwarriorFromTodo(todo)
+	want := taskwarriorTask{
+		UUID:        taskwarriorUUID("a1b2c3d4e5f6"),
+		Description: "Write docs",
+		Status:      "pending",
+		Entry:       "20250901T100000Z",
+		Modified:    "20250902T100000Z",
+		Due:         midnight.UTC().Format(taskwarriorTimeLayout),
+		Priority:    "M",
+		Project:     "site",
+		Tags:        []string{"docs"},
+		Annotations: []taskwarriorAnnotation{{Entry: "20250902T100000Z", Description: "Start with the README"}},
+		InternalID:  "a1b2c3d4e5f6",
+	}
+	if !reflect.DeepEqual(task, want) {
+		t.Errorf("taskwarriorFromTodo() = %+v\nwant %+v", task, want)
+	}
+
+	if !taskwarriorUUIDPattern.MatchString(task.UUID) || task.UUID[14] != '5' {
+		t.Errorf("taskwarriorUUID() = %s, want a version 5 UUID", task.UUID)
+	}
+	if again := taskwarriorUUID("a1b2c3d4e5f6"); again != task.UUID {
+		t.Errorf("taskwarriorUUID() = %s, then %s; want the same UUID", task.UUID, again)
+	}
+}
+
+func TestDecodeTaskwarrior_Export(t *testing.T) {
+	fileData, err := os.ReadFile(filepath.Join("testdata", "taskwarrior.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	todoList, warnings, err := decodeTaskwarrior(fileData)
+	if err != nil {
+		t.Fatalf("decodeTaskwarrior() error = %v", err)
+	}
+
+	local := func(year int, month time.Month, day, hour int) string {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.UTC).In(time.Local).Format(time.RFC3339)
+	}
+	want := TodoList{
+		{
+			InternalID: "5f0c6b3e-8a52-4c1e-9d3a-2b7f1e4c9a10",
+			Task:       "Fix the garden gate\nBuy new hinges",
+			Priority:   PriorityHigh,
+			DueAt:      local(2025, 9, 5, 22),
+			Tags:       []string{"project:home.garden", "diy", "weekend"},
+			CreatedAt:  local(2025, 9, 1, 10),
+			UpdatedAt:  local(2025, 9, 2, 10),
+		},
+		{
+			InternalID:  "7a1d2c3b-4e5f-4a6b-8c7d-9e0f1a2b3c4d",
+			Task:        "Renew passport",
+			Completed:   true,
+			CreatedAt:   local(2025, 8, 1, 7),
+			UpdatedAt:   local(2025, 8, 20, 7),
+			CompletedAt: local(2025, 8, 20, 7),
+		},
+		{
+			InternalID: "8b2e3d4c-5f6a-4b7c-9d8e-0f1a2b3c4d5e",
+			Task:       "Call the bank",
+			CreatedAt:  local(2025, 9, 3, 12),
+			UpdatedAt:  local(2025, 9, 3, 12),
+		},
+	}
+
+	// A due time that is local midnight stands for the end of that day
+	if due, _ := time.Parse(time.RFC3339, want[0].DueAt); due.Hour() == 0 && due.Minute() == 0 {
+		want[0].DueAt = endOfDay(due).Format(time.RFC3339)
+	}
+
+	if !reflect.DeepEqual(todoList, want) {
+		t.Errorf("decodeTaskwarrior() = %+v\nwant %+v", todoList, want)
+	}
+
+	wantWarnings := []string{
+		"task 4: skipped, it was deleted",
+		"task 5: skipped the recurring template (its pending instances are imported)",
+		`field "scheduled" on 1 task(s) not imported`,
+		`field "wait" on 1 task(s) not imported`,
+	}
+	if !reflect.DeepEqual(warnings, wantWarnings) {
+		t.Errorf("decodeTaskwarrior() warnings = %q\nwant %q", warnings, wantWarnings)
+	}
+}
+
+func TestDecodeTaskwarrior_OlderFormat(t *testing.T) {
+	fileData := []byte(`{"description":"First","entry":"20250901T100000Z","status":"pending","uuid":"5f0c6b3e-8a52-4c1e-9d3a-2b7f1e4c9a10","priority":"X"},
+{"description":"Second","entry":"20250901T100000Z","status":"pending","tags":["bad tag"]}
+`)
+
+	todoList, warnings, err := decodeTaskwarrior(fileData)
+	if err != nil {
+		t.Fatalf("decodeTaskwarrior() error = %v", err)
+	}
+	if len(todoList) != 2 || todoList[0].Task != "First" || todoList[1].Task != "Second" || todoList[1].InternalID == "" {
+		t.Errorf("decodeTaskwarrior() = %+v", todoList)
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[0], `task 1: unknown priority "X"`) || !strings.Contains(warnings[1], "task 2: invalid tag") {
+		t.Errorf("decodeTaskwarrior() warnings = %q", warnings)
+	}
+
+	if _, _, err := decodeTaskwarrior([]byte("not json\n")); err == nil {
+		t.Error("decodeTaskwarrior() should reject a file that isn't JSON")
+	}
+}
@@ -0,0 +1,335 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// todo.txt lines look like
+//
+//	x 2025-09-03 2025-09-01 Ship the release +work @office pri:A due:2025-09-05 uid:3f2a9c1b7d4e
+//	(B) 2025-09-01 Write docs +docs
+//
+// Completed items start with "x" and their completion date, open items with an (A)-(Z)
+// priority; the creation date follows. +project and @context tokens become tags (contexts
+// keep their '@'). The key:value extensions below carry what the format has no place for.
+const (
+	todoTxtDateLayout = "2006-01-02"
+
+	todoTxtKeyID        = "uid"       // internal ID
+	todoTxtKeyDue       = "due"       // due date, or the full due time when it isn't the end of that day
+	todoTxtKeyPriority  = "pri"       // priority of a completed item
+	todoTxtKeyCreated   = "created"   // creation time when it isn't midnight of the creation date
+	todoTxtKeyCompleted = "completed" // completion time when it isn't midnight of the completion date
+	todoTxtKeyUpdated   = "updated"   // last update when it isn't the completion or creation time
+)
+
+// todoTxtPriorities maps priorities to todo.txt letters; D-Z import as low
+var todoTxtPriorities = map[string]string{
+	PriorityHigh:   "A",
+	PriorityMedium: "B",
+	PriorityLow:    "C",
+}
+
+var (
+	todoTxtPriorityPattern = regexp.MustCompile(`^\(([A-Z])\)$`)
+	todoTxtDatePattern     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// encodeTodoTxt writes a list in todo.txt format, one item per line
+func encodeTodoTxt(todoList TodoList) []byte {
+	var buffer bytes.Buffer
+	for _, todo := range todoList {
+		buffer.WriteString(todoTxtLine(todo))
+		buffer.WriteByte('\n')
+	}
+	return buffer.Bytes()
+}
+
+// todoTxtLine formats one item as a todo.txt line
+func todoTxtLine(todo Todo) string {
+	var words []string
+	var extensions []string
+
+	letter := todoTxtPriorities[todo.Priority]
+	if todo.Completed {
+		words = append(words, "x")
+		if todo.CompletedAt != "" {
+			words = append(words, todoTxtDate(todo.CompletedAt))
+		}
+		if letter != "" {
+			extensions = append(extensions, todoTxtKeyPriority+":"+letter)
+		}
+	} else if letter != "" {
+		words = append(words, "("+letter+")")
+	}
+
+	// A lone date after "x" is the completion date, so completed items without
+	// one keep their creation time in an extension instead
+	createdDate := todo.CreatedAt != "" && (!todo.Completed || todo.CompletedAt != "")
+	if createdDate {
+		words = append(words, todoTxtDate(todo.CreatedAt))
+	}
+
+	// todo.txt is line based, so line breaks in the text become spaces
+	for index, word := range strings.Fields(todo.Task) {
+		words = append(words, todoTxtEscape(word, index == 0))
+	}
+
+	for _, tag := range todo.Tags {
+		if strings.HasPrefix(tag, "@") {
+			words = append(words, tag)
+		} else {
+			words = append(words, "+"+tag)
+		}
+	}
+
+	if todo.DueAt != "" {
+		due := todo.DueAt
+		if date := todoTxtDate(due); todoTxtDueTime(date) == due {
+			due = date
+		}
+		extensions = append(extensions, todoTxtKeyDue+":"+due)
+	}
+	if todo.CreatedAt != "" && (!createdDate || todoTxtDateTime(todoTxtDate(todo.CreatedAt)) != todo.CreatedAt) {
+		extensions = append(extensions, todoTxtKeyCreated+":"+todo.CreatedAt)
+	}
+	if todo.CompletedAt != "" && todoTxtDateTime(todoTxtDate(todo.CompletedAt)) != todo.CompletedAt {
+		extensions = append(extensions, todoTxtKeyCompleted+":"+todo.CompletedAt)
+	}
+	if todo.UpdatedAt != "" && todo.UpdatedAt != todoTxtImpliedUpdate(todo) {
+		extensions = append(extensions, todoTxtKeyUpdated+":"+todo.UpdatedAt)
+	}
+	if todo.InternalID != "" {
+		extensions = append(extensions, todoTxtKeyID+":"+todo.InternalID)
+	}
+
+	return strings.Join(append(words, extensions...), " ")
+}
+
+// todoTxtEscape protects a word of the task text that would be read back as a tag or an
+// extension, or at the start of the text as a completion mark, priority or date, with a
+// leading backslash. Words that already start with a backslash get another one.
+func todoTxtEscape(word string, first bool) string {
+	protect := strings.HasPrefix(word, `\`)
+	if len(word) > 1 && (word[0] == '+' || word[0] == '@') {
+		if _, err := NormalizeTag(word); err == nil {
+			protect = true
+		}
+	}
+	if key, value, found := strings.Cut(word, ":"); found && value != "" && isTodoTxtKey(key) {
+		protect = true
+	}
+	if first && (word == "x" || todoTxtPriorityPattern.MatchString(word) || todoTxtDatePattern.MatchString(word)) {
+		protect = true
+	}
+
+	if protect {
+		return `\` + word
+	}
+	return word
+}
+
+// isTodoTxtKey reports whether an extension key is read into an item field
+func isTodoTxtKey(key string) bool {
+	switch key {
+	case todoTxtKeyID, todoTxtKeyDue, todoTxtKeyPriority, todoTxtKeyCreated, todoTxtKeyCompleted, todoTxtKeyUpdated:
+		return true
+	}
+	return false
+}
+
+// decodeTodoTxt parses a todo.txt file. Items without a uid get a new internal ID and
+// timestamps taken from their dates. Warnings describe lines that couldn't be used as is.
+func decodeTodoTxt(fileData []byte) (TodoList, []string, error) {
+	var todoList TodoList
+	var warnings []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(fileData))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		todo, lineWarnings := parseTodoTxtLine(line)
+		for _, warning := range lineWarnings {
+			warnings = append(warnings, fmt.Sprintf("line %d: %s", lineNumber, warning))
+		}
+		if todo.Task == "" {
+			warnings = append(warnings, fmt.Sprintf("line %d: skipped, it has no task text", lineNumber))
+			continue
+		}
+		todoList = append(todoList, todo)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return todoList, warnings, nil
+}
+
+// parseTodoTxtLine reads one todo.txt line into an item. Extensions with values that
+// can't be read stay part of the text, with a warning.
+func parseTodoTxtLine(line string) (Todo, []string) {
+	var todo Todo
+	var warnings []string
+	words := strings.Fields(line)
+
+	if len(words) > 0 && words[0] == "x" {
+		todo.Completed = true
+		words = words[1:]
+		if len(words) > 0 && todoTxtDatePattern.MatchString(words[0]) {
+			todo.CompletedAt = todoTxtDateTime(words[0])
+			words = words[1:]
+		}
+	} else if len(words) > 0 {
+		if matches := todoTxtPriorityPattern.FindStringSubmatch(words[0]); matches != nil {
+			todo.Priority, warnings = todoTxtPriority(matches[1], warnings)
+			words = words[1:]
+		}
+	}
+
+	if len(words) > 0 && todoTxtDatePattern.MatchString(words[0]) {
+		todo.CreatedAt = todoTxtDateTime(words[0])
+		words = words[1:]
+	}
+
+	var text []string
+	var updatedAt string
+	for _, word := range words {
+		// Words escaped by todoTxtEscape are text
+		if len(word) > 1 && word[0] == '\\' {
+			text = append(text, word[1:])
+			continue
+		}
+
+		if len(word) > 1 && (word[0] == '+' || word[0] == '@') {
+			if normalized, err := NormalizeTag(word); err == nil {
+				todo.Tags = appendUniqueTags(todo.Tags, normalized)
+				continue
+			}
+		}
+
+		key, value, found := strings.Cut(word, ":")
+		if !found || value == "" {
+			text = append(text, word)
+			continue
+		}
+
+		var err error
+		switch key {
+		case todoTxtKeyID:
+			todo.InternalID = value
+		case todoTxtKeyPriority:
+			todo.Priority, warnings = todoTxtPriority(value, warnings)
+		case todoTxtKeyDue:
+			todo.DueAt, err = todoTxtDue(value)
+		case todoTxtKeyCreated:
+			todo.CreatedAt, err = todoTxtTimestamp(key, value)
+		case todoTxtKeyCompleted:
+			todo.CompletedAt, err = todoTxtTimestamp(key, value)
+		case todoTxtKeyUpdated:
+			updatedAt, err = todoTxtTimestamp(key, value)
+		default:
+			// Other extensions (t:, rec:, links, ...) stay part of the text
+			text = append(text, word)
+		}
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%v, kept in the text", err))
+			text = append(text, word)
+		}
+	}
+	todo.Task = strings.Join(text, " ")
+
+	if todo.InternalID == "" {
+		todo.InternalID = generateShortGUID()
+	}
+	if todo.CreatedAt == "" {
+		todo.CreatedAt = todo.CompletedAt
+	}
+	if todo.CreatedAt == "" {
+		todo.CreatedAt = time.Now().Format(time.RFC3339)
+	}
+	todo.UpdatedAt = updatedAt
+	if todo.UpdatedAt == "" {
+		todo.UpdatedAt = todoTxtImpliedUpdate(todo)
+	}
+
+	return todo, warnings
+}
+
+// todoTxtPriority converts a todo.txt priority letter, noting letters past C
+func todoTxtPriority(letter string, warnings []string) (string, []string) {
+	for priority, mapped := range todoTxtPriorities {
+		if mapped == letter {
+			return priority, warnings
+		}
+	}
+	if len(letter) == 1 && letter[0] >= 'D' && letter[0] <= 'Z' {
+		return PriorityLow, append(warnings, fmt.Sprintf("priority (%s) imported as low", letter))
+	}
+	return "", append(warnings, fmt.Sprintf("unknown priority %q dropped", letter))
+}
+
+// todoTxtDue parses a due: value, a date (the end of that day) or a full time
+func todoTxtDue(value string) (string, error) {
+	if todoTxtDatePattern.MatchString(value) {
+		return todoTxtDueTime(value), nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return "", fmt.Errorf("invalid due date: %s", value)
+	}
+	return parsed.Format(time.RFC3339), nil
+}
+
+// todoTxtTimestamp parses an RFC3339 extension value
+func todoTxtTimestamp(key, value string) (string, error) {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s time: %s", key, value)
+	}
+	return parsed.Format(time.RFC3339), nil
+}
+
+// todoTxtDate returns the date part of a stored timestamp, as written in the todo.txt date fields
+func todoTxtDate(timestamp string) string {
+	if len(timestamp) >= len(todoTxtDateLayout) && todoTxtDatePattern.MatchString(timestamp[:len(todoTxtDateLayout)]) {
+		return timestamp[:len(todoTxtDateLayout)]
+	}
+	return timestamp
+}
+
+// todoTxtDateTime is the timestamp a todo.txt date stands for: midnight local time
+func todoTxtDateTime(date string) string {
+	parsed, err := time.ParseInLocation(todoTxtDateLayout, date, time.Local)
+	if err != nil {
+		return ""
+	}
+	return parsed.Format(time.RFC3339)
+}
+
+// todoTxtDueTime is the due time a due: date stands for, matching 'todo add --due'
+func todoTxtDueTime(date string) string {
+	parsed, err := time.ParseInLocation(todoTxtDateLayout, date, time.Local)
+	if err != nil {
+		return ""
+	}
+	return endOfDay(parsed).Format(time.RFC3339)
+}
+
+// todoTxtImpliedUpdate is the update time assumed when a line has no updated: value
+func todoTxtImpliedUpdate(todo Todo) string {
+	if todo.CompletedAt != "" {
+		return todo.CompletedAt
+	}
+	return todo.CreatedAt
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportImport_TodoTxtRoundTrip(t *testing.T) {
+	midnight := time.Date(2025, 9, 1, 0, 0, 0, 0, time.Local).Format(time.RFC3339)
+	endOfDue := endOfDay(time.Date(2025, 9, 5, 0, 0, 0, 0, time.Local)).Format(time.RFC3339)
+
+	todoList := TodoList{
+		{InternalID: "a1b2c3d4e5f6", Task: "Write docs", Priority: "high", Tags: []string{"docs", "@office"}, CreatedAt: "2025-09-01T10:00:00Z", UpdatedAt: "2025-09-02T10:00:00Z", DueAt: endOfDue},
+		{InternalID: "b2c3d4e5f6a1", Task: "Ship", Priority: "low", Completed: true, CreatedAt: midnight, UpdatedAt: "2025-09-03T10:00:00Z", CompletedAt: "2025-09-03T10:00:00Z", DueAt: "2025-09-05T12:30:00Z"},
+		{InternalID: "c3d4e5f6a1b2", Task: "Done long ago", Completed: true, CreatedAt: "2025-08-01T08:00:00+02:00", UpdatedAt: "2025-08-01T08:00:00+02:00"},
+		{InternalID: "d4e5f6a1b2c3", Task: "See https://example.com t:2025-10-01", Priority: "medium", CreatedAt: midnight, UpdatedAt: midnight},
+	}
+
+	fileData, err := encodeExport(todoList, "todotxt")
+	if err != nil {
+		t.Fatalf("encodeExport() error = %v", err)
+	}
+
+	imported, warnings, err := decodeImport(fileData, "todotxt", nil)
+	if err != nil {
+		t.Fatalf("decodeImport() error = %v", err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("decodeImport() warnings = %v", warnings)
+	}
+	if !reflect.DeepEqual(imported, todoList) {
+		t.Errorf("decodeImport() = %+v\nwant %+v\nfrom:\n%s", imported, todoList, fileData)
+	}
+}
+
+func TestTodoTxtLine(t *testing.T) {
+	midnight := time.Date(2025, 9, 1, 0, 0, 0, 0, time.Local).Format(time.RFC3339)
+
+	tests := []struct {
+		todo Todo
+		want string
+	}{
+		{
+			Todo{InternalID: "a1", Task: "Call mom", Priority: "high", Tags: []string{"family", "@phone"}, CreatedAt: midnight, UpdatedAt: midnight},
+			"(A) 2025-09-01 Call mom +family @phone uid:a1",
+		},
+		{
+			Todo{InternalID: "b2", Task: "Pay rent", Priority: "medium", Completed: true, CreatedAt: midnight, UpdatedAt: midnight, CompletedAt: midnight},
+			"x 2025-09-01 2025-09-01 Pay rent pri:B uid:b2",
+		},
+	}
+	for _, test := range tests {
+		if got := todoTxtLine(test.todo); got != test.want {
+			t.Errorf("todoTxtLine() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestDecodeTodoTxt(t *testing.T) {
+	fileData := []byte(`(A) Thank Mom for the meatballs @phone
+(B) 2011-03-02 Schedule Goodwill pickup +GarageSale @phone due:2011-03-05
+
+x 2011-03-03 2011-03-01 Review Tim's pull request +TodoTxtTouch @github
+(F) Someday maybe
++OnlyAProject
+`)
+
+	todoList, warnings, err := decodeTodoTxt(fileData)
+	if err != nil {
+		t.Fatalf("decodeTodoTxt() error = %v", err)
+	}
+	if len(todoList) != 4 {
+		t.Fatalf("decodeTodoTxt() = %d items, want 4", len(todoList))
+	}
+
+	first := todoList[0]
+	if first.Task != "Thank Mom for the meatballs" || first.Priority != PriorityHigh || !reflect.DeepEqual(first.Tags, []string{"@phone"}) {
+		t.Errorf("first item = %+v", first)
+	}
+	if first.InternalID == "" || first.CreatedAt == "" || first.UpdatedAt != first.CreatedAt {
+		t.Errorf("first item should get an internal ID and timestamps, got %+v", first)
+	}
+
+	second := todoList[1]
+	if second.Task != "Schedule Goodwill pickup" || second.Priority != PriorityMedium || !reflect.DeepEqual(second.Tags, []string{"garagesale", "@phone"}) {
+		t.Errorf("second item = %+v", second)
+	}
+	if !strings.HasPrefix(second.CreatedAt, "2011-03-02T00:00:00") || !strings.HasPrefix(second.DueAt, "2011-03-05T23:59:59") {
+		t.Errorf("second item dates = %s, due %s", second.CreatedAt, second.DueAt)
+	}
+
+	third := todoList[2]
+	if !third.Completed || !strings.HasPrefix(third.CompletedAt, "2011-03-03") || !strings.HasPrefix(third.CreatedAt, "2011-03-01") || third.UpdatedAt != third.CompletedAt {
+		t.Errorf("third item = %+v", third)
+	}
+
+	if todoList[3].Priority != PriorityLow {
+		t.Errorf("(F) should import as low, got %q", todoList[3].Priority)
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "line 5: priority (F) imported as low") || !strings.Contains(warnings[1], "line 6: skipped") {
+		t.Errorf("decodeTodoTxt() warnings = %v", warnings)
+	}
+
+	// A value that can't be read stays in the text instead of failing the file
+	todoList, warnings, err = decodeTodoTxt([]byte("Broken due:someday\nFine\n"))
+	if err != nil || len(todoList) != 2 || todoList[0].Task != "Broken due:someday" || todoList[0].DueAt != "" {
+		t.Errorf("decodeTodoTxt() = %+v, %v; want the due value kept in the text", todoList, err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "line 1: invalid due date: someday, kept in the text") {
+		t.Errorf("decodeTodoTxt() warnings = %v", warnings)
+	}
+}
+
+func TestExportImport_TodoTxtProtectsText(t *testing.T) {
+	midnight := time.Date(2025, 9, 1, 0, 0, 0, 0, time.Local).Format(time.RFC3339)
+
+	tasks := []string{
+		"Ask about due:friday at the meeting",
+		"Email @bob see +notes first",
+		"x marks the spot",
+		"(A) is not a priority here",
+		"2025-10-01 is the launch date",
+		`Keep \this backslash and uid:abc pri:A`,
+	}
+	var todoList TodoList
+	for index, task := range tasks {
+		todoList = append(todoList, Todo{InternalID: fmt.Sprintf("a1b2c3d4e5f%d", index), Task: task, Tags: []string{"work"}, CreatedAt: midnight, UpdatedAt: midnight})
+	}
+
+	fileData, err := encodeExport(todoList, "todotxt")
+	if err != nil {
+		t.Fatalf("encodeExport() error = %v", err)
+	}
+	imported, warnings, err := decodeImport(fileData, "todotxt", nil)
+	if err != nil {
+		t.Fatalf("decodeImport() error = %v", err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("decodeImport() warnings = %v", warnings)
+	}
+	if !reflect.DeepEqual(imported, todoList) {
+		t.Errorf("decodeImport() = %+v\nwant %+v\nfrom:\n%s", imported, todoList, fileData)
+	}
+}
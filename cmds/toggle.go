@@ -14,28 +14,32 @@ func NewToggleCommand() *cli.Command {
 		Name:      "toggle",
 		Usage:     "Toggle completion status of a todo item by ID",
 		Aliases:   []string{"t", "complete"},
-		ArgsUsage: "<id>",
+		ArgsUsage: "<id...>",
 		Action: func(ctx context.Context, c *cli.Command) error {
 			// Validate archive flag usage
 			if err := ValidateArchiveFlagUsage(c, "toggle"); err != nil {
 				return cli.Exit(err.Error(), 1)
 			}
 
-			if c.Args().Len() != 1 {
-				return cli.Exit("exactly one ID is required", 1)
+			ranges, err := ParseIDs(c.Args().Slice())
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
 			}
 
-			id, err := strconv.Atoi(c.Args().First())
-			if err != nil {
-				return cli.Exit(fmt.Sprintf("invalid ID: %s must be a number", c.Args().First()), 1)
+			// New local lists are only created by 'todo init'
+			if err := RequireTodoFile(GetStorageOptions(c)); err != nil {
+				return cli.Exit(err.Error(), 1)
 			}
 
-			if id <= 0 {
-				return cli.Exit("ID must be greater than 0", 1)
+			// Hold the list lock until every file is saved
+			lock, err := lockTodoList(c)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to acquire lock: %v", err), 2)
 			}
+			defer lock.Unlock()
 
-			// Get the appropriate storage path based on global flag
-			storagePath, err := GetStoragePath(c.Bool("global"))
+			// Get the appropriate storage path for the selected list and archive flag
+			storagePath, err := GetEffectiveStoragePath(GetStorageOptions(c), c.Bool("archive"))
 			if err != nil {
 				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
 			}
@@ -46,17 +50,34 @@ func NewToggleCommand() *cli.Command {
 				return cli.Exit(fmt.Sprintf("failed to initialize todo list: %v", err), 2)
 			}
 
-			// Toggle the item
-			if err := todoList.Toggle(id); err != nil { // toggle method expects 1-based index
+			// Validate every ID before anything is toggled
+			ids, err := ResolveIDs(ranges, *todoList)
+			if err != nil {
 				return cli.Exit(fmt.Sprintf("failed to toggle task: %v", err), 1)
 			}
 
+			// Toggle the items
+			for _, id := range ids {
+				if err := todoList.Toggle(id); err != nil { // toggle method expects 1-based index
+					return cli.Exit(fmt.Sprintf("failed to toggle task: %v", err), 1)
+				}
+			}
+
 			// Save the updated todo list
 			if err := storage.Save(*todoList); err != nil {
 				return cli.Exit(fmt.Sprintf("error saving todos: %v", err), 2)
 			}
 
-			fmt.Printf("Toggled completion status for todo item with ID: %d\n", id)
+			// Record the change so it can be undone
+			if err := recordJournalEntry(c, storage); err != nil {
+				return cli.Exit(fmt.Sprintf("error recording undo history: %v", err), 2)
+			}
+
+			if len(ids) == 1 {
+				fmt.Printf("Toggled completion status for todo item with ID: %d\n", ids[0])
+			} else {
+				fmt.Printf("Toggled completion status for todo items with IDs: %s\n", formatIDs(ids))
+			}
 
 			// Check if --list flag is set and execute list command after toggle
 			if CheckAndExecuteListFlag(c) {
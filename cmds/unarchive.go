@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+)
+
+// NewUnarchiveCommand creates a new unarchive command for urfave/cli
+func NewUnarchiveCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "unarchive",
+		Usage:     "Restore archived todo items by ID (as shown by 'todo --archive list')",
+		Aliases:   []string{"ua"},
+		ArgsUsage: "<id...>",
+		Action: func(ctx context.Context, c *cli.Command) error {
+			// Validate archive flag usage
+			if err := ValidateArchiveFlagUsage(c, "unarchive"); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			ranges, err := ParseIDs(c.Args().Slice())
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// New local lists are only created by 'todo init'
+			if err := RequireTodoFile(GetStorageOptions(c)); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// Hold the list lock until every file is saved
+			lock, err := lockTodoList(c)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to acquire lock: %v", err), 2)
+			}
+			defer lock.Unlock()
+
+			// Get the appropriate storage paths for the selected list
+			storagePath, err := GetStoragePath(GetStorageOptions(c))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
+			}
+
+			archivePath, err := GetArchivePath(GetStorageOptions(c))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("error getting archive path: %v", err), 2)
+			}
+
+			// Initialize todo list and storage
+			todoList, storage, err := initializeTodoListWithPath(storagePath)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to initialize todo list: %v", err), 2)
+			}
+
+			// Initialize archive list and storage
+			archiveList, archiveStorage, err := initializeTodoListWithPath(archivePath)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to initialize archive list: %v", err), 2)
+			}
+
+			// Resolve IDs against the archive as currently listed
+			ids, err := ResolveIDs(ranges, *archiveList)
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			restored, err := archiveList.takeItems(ids)
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// Add back to the main list (preserving IDs, timestamps and completion status)
+			appendMovedItems(todoList, restored)
+
+			// Save both lists
+			if err := storage.Save(*todoList); err != nil {
+				return cli.Exit(fmt.Sprintf("error saving todos: %v", err), 2)
+			}
+
+			if err := archiveStorage.Save(*archiveList); err != nil {
+				return cli.Exit(fmt.Sprintf("error saving archive: %v", err), 2)
+			}
+
+			// Record the change so it can be undone
+			if err := recordJournalEntry(c, storage, archiveStorage); err != nil {
+				return cli.Exit(fmt.Sprintf("error recording undo history: %v", err), 2)
+			}
+
+			for _, item := range restored {
+				fmt.Printf("Restored todo item: %s\n", item.Task)
+			}
+
+			// Check if --list flag is set and execute list command after unarchive
+			if CheckAndExecuteListFlag(c) {
+				if err := ExecuteListCommand(c); err != nil {
+					return cli.Exit(fmt.Sprintf("error executing list: %v", err), 2)
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+// Legacy command struct for backward compatibility
+type UnarchiveCommand struct{}
+
+func init() {
+	RegisterCommand(&UnarchiveCommand{})
+}
+
+func (c *UnarchiveCommand) Name() string {
+	return "unarchive"
+}
+
+func (c *UnarchiveCommand) Description() string {
+	return "Restore archived todo items by ID"
+}
+
+func (c *UnarchiveCommand) Usage() string {
+	return "todo-cli unarchive <id...>"
+}
+
+func (c *UnarchiveCommand) Execute(args []string, todoList TodoListInterface) error {
+	// Note: Legacy interface doesn't expose the archive
+	return fmt.Errorf("unarchive functionality not supported in legacy interface")
+}
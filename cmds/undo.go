@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+)
+
+// NewUndoCommand creates a new undo command for urfave/cli
+func NewUndoCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "undo",
+		Usage:     "Undo the last change to the todo list",
+		Aliases:   []string{"u"},
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "Undo even if the files changed since the entry was recorded",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			// Validate archive flag usage
+			if err := ValidateArchiveFlagUsage(c, "undo"); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// New local lists are only created by 'todo init'
+			if err := RequireTodoFile(GetStorageOptions(c)); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			// Hold the list lock until every file is saved
+			lock, err := lockTodoList(c)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to acquire lock: %v", err), 2)
+			}
+			defer lock.Unlock()
+
+			entry, err := stepJournal(GetStorageOptions(c), true, c.Bool("force"))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to undo: %v", err), 2)
+			}
+
+			if entry == nil {
+				fmt.Println("Nothing to undo.")
+				return nil
+			}
+
+			fmt.Printf("Undid: %s\n", entry.Command)
+
+			// Check if --list flag is set and execute list command after undo
+			if CheckAndExecuteListFlag(c) {
+				if err := ExecuteListCommand(c); err != nil {
+					return cli.Exit(fmt.Sprintf("error executing list: %v", err), 2)
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+// Legacy command struct for backward compatibility
+type UndoCommand struct{}
+
+func init() {
+	RegisterCommand(&UndoCommand{})
+}
+
+func (c *UndoCommand) Name() string {
+	return "undo"
+}
+
+func (c *UndoCommand) Description() string {
+	return "Undo the last change to the todo list"
+}
+
+func (c *UndoCommand) Usage() string {
+	return "todo-cli undo [--force]"
+}
+
+func (c *UndoCommand) Execute(args []string, todoList TodoListInterface) error {
+	// Note: Legacy interface doesn't keep undo history
+	return fmt.Errorf("undo functionality not supported in legacy interface")
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+)
+
+// NewUseCommand creates a new use command for urfave/cli
+func NewUseCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "use",
+		Usage:     "Select the todo list used by default ('local' for ./.todos.json, 'global', or a registered name)",
+		ArgsUsage: "<name>",
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if c.Args().Len() != 1 {
+				return cli.Exit("a list name is required", 1)
+			}
+			name := c.Args().Get(0)
+
+			registry, storage, err := LoadListRegistry()
+			if err != nil {
+				return cli.Exit(err.Error(), 2)
+			}
+
+			if err := registry.Use(name); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			if err := SaveListRegistry(registry, storage); err != nil {
+				return cli.Exit(err.Error(), 2)
+			}
+
+			fmt.Printf("Now using list '%s'\n", registry.ActiveName())
+			return nil
+		},
+	}
+}
+
+// Legacy command struct for backward compatibility
+type UseCommand struct{}
+
+func init() {
+	RegisterCommand(&UseCommand{})
+}
+
+func (c *UseCommand) Name() string {
+	return "use"
+}
+
+func (c *UseCommand) Description() string {
+	return "Select the todo list used by default"
+}
+
+func (c *UseCommand) Usage() string {
+	return "todo-cli use <name>"
+}
+
+func (c *UseCommand) Execute(args []string, todoList TodoListInterface) error {
+	// Note: Legacy interface doesn't manage list registrations
+	return fmt.Errorf("use functionality not supported in legacy interface")
+}
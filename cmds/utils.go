@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"bufio"
+	"bytes"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
@@ -8,56 +10,166 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/aquasecurity/table"
+	"github.com/bennthewolfe/todo-cli/backend"
 	"github.com/liamg/tml"
 	"github.com/urfave/cli/v3"
 )
 
+// storageBackend is the backend todo lists are kept in, set from the backend setting
+var storageBackend = backend.JSON
+
 // Storage represents the storage interface for TodoList
 type Storage[T any] struct {
 	filename string
+	kind     string          // backend kind, recorded in the undo history
+	backend  backend.Backend // where the data is kept
+	loaded   []byte          // file content as read by the last Load, used for undo history
+	saved    []byte          // file content as written by the last Save
+	appended []byte          // items added by appendTodos without a Load, used for undo history
+	content  []byte          // decrypted content of the last Load
+	dropped  []string        // fields of the loaded list Save can't keep, warned about on Save
+	encrypt  encryptionMode  // whether Save encrypts the file
 }
 
-// NewStorage creates a new storage instance
+// encryptionMode decides whether a storage encrypts the files it saves
+type encryptionMode int
+
+const (
+	encryptAsStored encryptionMode = iota // keep the stored file's state (plain for new files)
+	encryptOn
+	encryptOff
+)
+
+// NewStorage creates a new storage instance. Todo lists use the configured
+// backend; everything else (journal, registry, ...) is a plain JSON file.
 func NewStorage[T any](filename string) *Storage[T] {
-	return &Storage[T]{filename: filename}
+	var data T
+	if _, isTodoList := any(data).(TodoList); isTodoList {
+		return newBackendStorage[T](storageBackend, filename)
+	}
+	return newBackendStorage[T](backend.JSON, filename)
 }
 
-// Save saves data to the storage file
+// newBackendStorage creates a storage instance kept in the given backend
+func newBackendStorage[T any](kind, filename string) *Storage[T] {
+	store, err := backend.Open(kind, filename)
+	if err != nil {
+		// The backend setting is validated when the config is loaded
+		kind, store = backend.JSON, backend.NewJSONFile(filename)
+	}
+	return &Storage[T]{filename: filename, kind: kind, backend: store}
+}
+
+// Save saves data to the storage file. For todo lists the file being replaced
+// is first kept as a rotating backup (see backup.go).
 func (s *Storage[T]) Save(data T) error {
 	fileData, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
 		return fmt.Errorf("error marshaling data to JSON: %w", err)
 	}
-	return os.WriteFile(s.filename, fileData, 0644)
+	if _, isTodoList := any(data).(TodoList); isTodoList {
+		// Row backends only write the rows that changed, so the whole stored list
+		// isn't read back; they can't be encrypted and rely on undo instead of backups
+		var current []byte
+		if _, isRowStore := s.backend.(backend.RowStore); !isRowStore {
+			if current, err = s.backend.Load(); err != nil {
+				return err
+			}
+		}
+		if fileData, err = s.encode(fileData, current); err != nil {
+			return err
+		}
+		if s.keepsBackups() {
+			if err := backupTodoFile(s.filename, current); err != nil {
+				return fmt.Errorf("error backing up %s: %w", s.filename, err)
+			}
+		}
+
+		// Fields this version doesn't know are lost now, so say so once
+		for _, dropped := range s.dropped {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", s.filename, dropped)
+		}
+		s.dropped = nil
+	}
+	if err := s.writeRaw(fileData); err != nil {
+		return err
+	}
+	s.saved = fileData
+	return nil
+}
+
+// keepsBackups reports whether saving a todo list first backs up the stored one
+func (s *Storage[T]) keepsBackups() bool {
+	_, isRowStore := s.backend.(backend.RowStore)
+	return backupPolicy.Count > 0 && !isRowStore
+}
+
+// encode encrypts a todo list document when the storage encrypts its file. Unchanged
+// content keeps the loaded file as is, so saving it again isn't recorded as a change.
+func (s *Storage[T]) encode(content, current []byte) ([]byte, error) {
+	encrypt := s.encrypt == encryptOn || (s.encrypt == encryptAsStored && isEncryptedData(current))
+	if !encrypt {
+		return content, nil
+	}
+	if s.kind != backend.JSON {
+		return nil, fmt.Errorf("encryption is only supported with the %s backend", backend.JSON)
+	}
+	if isEncryptedData(s.loaded) && bytes.Equal(content, s.content) {
+		return s.loaded, nil
+	}
+	return encryptData(content)
+}
+
+// writeRaw writes already encoded data to the backend
+func (s *Storage[T]) writeRaw(fileData []byte) error {
+	return s.backend.Save(fileData)
 }
 
 // Load loads data from the storage file
 func (s *Storage[T]) Load() (T, error) {
 	var data T
 
-	// Check if the file exists, if not create an empty one
-	if _, err := os.Stat(s.filename); os.IsNotExist(err) {
-		emptyFile, err := os.Create(s.filename)
-		if err != nil {
-			return data, fmt.Errorf("error creating file: %w", err)
-		}
-		emptyFile.Close()
-	}
-
-	// Read the file content
-	fileData, err := os.ReadFile(s.filename)
+	// A missing file loads as empty; it is only created by the first Save
+	fileData, err := s.backend.Load()
 	if err != nil {
-		return data, fmt.Errorf("error reading file: %w", err)
+		return data, err
 	}
+	if fileData == nil {
+		return data, nil
+	}
+	s.loaded = fileData
 
 	// Check if the file is empty
 	if len(fileData) == 0 {
 		return data, nil
 	}
 
+	// Encrypted files are decrypted here and encrypted again by Save
+	if isEncryptedData(fileData) {
+		if fileData, err = decryptData(fileData); err != nil {
+			return data, fmt.Errorf("unable to decrypt %s: %w", s.filename, err)
+		}
+		if s.encrypt == encryptAsStored {
+			s.encrypt = encryptOn
+		}
+	}
+	s.content = fileData
+
+	// Todo lists are migrated here, keeping the fields Save will drop
+	if todoList, isTodoList := any(&data).(*TodoList); isTodoList {
+		items, report, err := migrateTodoData(fileData)
+		if err != nil {
+			return data, fmt.Errorf("error unmarshaling JSON data: %w", err)
+		}
+		*todoList = items
+		s.dropped = report.Dropped
+		return data, nil
+	}
+
 	if err := json.Unmarshal(fileData, &data); err != nil {
 		return data, fmt.Errorf("error unmarshaling JSON data: %w", err)
 	}
@@ -74,14 +186,25 @@ func generateShortGUID() string {
 
 // Todo represents a single todo item
 type Todo struct {
-	InternalID  string `json:"internal_id"` // Hidden GUID for internal tracking
-	Task        string `json:"task"`
-	Completed   bool   `json:"completed"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
-	CompletedAt string `json:"completed_at,omitempty"`
+	InternalID  string   `json:"internal_id"` // Hidden GUID for internal tracking
+	Task        string   `json:"task"`
+	Priority    string   `json:"priority,omitempty"`
+	DueAt       string   `json:"due_at,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Completed   bool     `json:"completed"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+	CompletedAt string   `json:"completed_at,omitempty"`
+
+	position int // 1-based storage position, set when the list is reordered for display
 }
 
+// tableDateFormat is the layout for dates in table output, set from the date_format setting
+var tableDateFormat = "2006-01-02"
+
+// tableShowUID adds the internal ID column to table output, set by 'list --show-uid'
+var tableShowUID = false
+
 // TodoList type for the commands package
 type TodoList []Todo
 
@@ -102,6 +225,10 @@ func (todoList *TodoList) Toggle(index int) error {
 	return todoList.toggle(index)
 }
 
+func (todoList *TodoList) SetPriority(index int, priority string) error {
+	return todoList.setPriority(index, priority)
+}
+
 func (todoList *TodoList) View(format string) {
 	todoList.view(format)
 }
@@ -186,6 +313,25 @@ func (todoList *TodoList) update(index int, task string) error {
 	return nil
 }
 
+func (todoList *TodoList) setPriority(index int, priority string) error {
+	t := *todoList
+
+	// Validate the index before attempting to update
+	if err := t.validateIndex(index); err != nil {
+		return err
+	}
+
+	normalized, err := ParsePriority(priority)
+	if err != nil {
+		return err
+	}
+
+	t[index].Priority = normalized
+	t[index].UpdatedAt = time.Now().Format(time.RFC3339)
+
+	return nil
+}
+
 func (todoList *TodoList) view(format string) {
 	t := *todoList
 
@@ -197,7 +343,19 @@ func (todoList *TodoList) view(format string) {
 		t.viewJSON("pretty")
 		return
 	case "table":
-		t.viewTable()
+		t.viewTable(false)
+		return
+	case "markdown":
+		t.viewMarkdown()
+		return
+	case "markdown-table":
+		t.viewTable(true)
+		return
+	case "csv":
+		t.viewDelimited(',')
+		return
+	case "tsv":
+		t.viewDelimited('\t')
 		return
 	case "none":
 		return
@@ -218,23 +376,31 @@ func (todoList *TodoList) viewJSON(style string) {
 
 	// Create display version with index-based IDs
 	type DisplayTodo struct {
-		ID          int    `json:"id"`
-		Task        string `json:"task"`
-		Completed   bool   `json:"completed"`
-		CreatedAt   string `json:"created_at"`
-		UpdatedAt   string `json:"updated_at"`
-		CompletedAt string `json:"completed_at,omitempty"`
+		ID          int      `json:"id"`
+		Task        string   `json:"task"`
+		Priority    string   `json:"priority,omitempty"`
+		DueAt       string   `json:"due_at,omitempty"`
+		Tags        []string `json:"tags,omitempty"`
+		Completed   bool     `json:"completed"`
+		CreatedAt   string   `json:"created_at"`
+		UpdatedAt   string   `json:"updated_at"`
+		CompletedAt string   `json:"completed_at,omitempty"`
+		InternalID  string   `json:"internal_id"`
 	}
 
 	displayTodos := make([]DisplayTodo, len(t))
 	for index, todo := range t {
 		displayTodos[index] = DisplayTodo{
-			ID:          index + 1, // Use array index + 1 as display ID
+			ID:          todo.displayID(index), // Use storage position as display ID
 			Task:        todo.Task,
+			Priority:    todo.Priority,
+			DueAt:       todo.DueAt,
+			Tags:        todo.Tags,
 			Completed:   todo.Completed,
 			CreatedAt:   todo.CreatedAt,
 			UpdatedAt:   todo.UpdatedAt,
 			CompletedAt: todo.CompletedAt,
+			InternalID:  todo.InternalID,
 		}
 	}
 
@@ -255,23 +421,29 @@ func (todoList *TodoList) viewJSON(style string) {
 	fmt.Println(string(jsonOutput))
 }
 
-func (todoList *TodoList) viewTable() {
+// viewTable prints the list as a table; markdown uses plain markdown table dividers
+// without colors, so the output can be pasted into a document
+func (todoList *TodoList) viewTable(markdown bool) {
 	if len(*todoList) == 0 {
 		fmt.Println("No todos found.")
 		return
 	}
 
 	todoType := reflect.TypeOf(Todo{})
-	timeFormat := "2006-01-02"
+	timeFormat := tableDateFormat
+	now := time.Now()
 
 	// Dynamically generate headers, but skip InternalID and add ID at the beginning
 	var headers []string
 	headers = append(headers, "ID") // Add ID as first column
+	if tableShowUID {
+		headers = append(headers, "UID")
+	}
 
 	for i := 0; i < todoType.NumField(); i++ {
 		field := todoType.Field(i)
-		// Skip the InternalID field since it's for internal use only
-		if field.Name != "InternalID" {
+		// Skip the InternalID field and unexported bookkeeping fields since they're for internal use only
+		if field.Name != "InternalID" && field.IsExported() {
 			headers = append(headers, field.Name)
 		}
 	}
@@ -280,16 +452,22 @@ func (todoList *TodoList) viewTable() {
 
 	// Table options
 	t.SetRowLines(false)
-	// t.SetDividers(table.MarkdownDividers)
+	colorize := tml.Sprintf
+	if markdown {
+		t.SetDividers(table.MarkdownDividers)
+		t.SetBorderTop(false)
+		t.SetBorderBottom(false)
+		colorize = markdownCell
+	}
 
 	t.SetHeaders(headers...)
 
 	for index, todo := range *todoList {
-		// Use index + 1 as the display ID
-		displayID := index + 1
+		// Use the storage position as the display ID
+		displayID := todo.displayID(index)
 
 		// Handle all time fields consistently
-		var createdAtStr, updatedAtStr, completedAtStr string
+		var createdAtStr, updatedAtStr, completedAtStr, dueAtStr string
 
 		// CreatedAt
 		if createdAt, err := time.Parse(time.RFC3339, todo.CreatedAt); err == nil {
@@ -316,6 +494,21 @@ func (todoList *TodoList) viewTable() {
 			completedAtStr = ""
 		}
 
+		// DueAt (overdue items are highlighted)
+		if todo.DueAt != "" {
+			if dueAt, err := time.Parse(time.RFC3339, todo.DueAt); err == nil {
+				dueAtStr = dueAt.Format(timeFormat)
+				if todo.IsOverdue(now) {
+					dueAtStr = colorize("<red><bold>%s</bold></red>", dueAtStr)
+				}
+			} else {
+				dueAtStr = "Invalid"
+			}
+		}
+
+		// Priority (colored by level)
+		priorityStr := colorize(priorityColorFormat(todo.Priority), todo.Priority)
+
 		// Completion to emoji
 		var completedEmoji string
 		if todo.Completed {
@@ -324,27 +517,48 @@ func (todoList *TodoList) viewTable() {
 			completedEmoji = "❌"
 		}
 
-		// Add row with ID first, then other fields (excluding InternalID)
-		t.AddRow(
-			fmt.Sprintf("%d", displayID), // ID column
-			todo.Task,                    // Task column
-			completedEmoji,               // Completed column
-			createdAtStr,                 // CreatedAt column
-			updatedAtStr,                 // UpdatedAt column
-			tml.Sprintf("<green>%s</green>", completedAtStr), // CompletedAt column
-		)
+		task := todo.Task
+		if markdown {
+			task = markdownCell("%s", task)
+		}
+
+		// Add row with ID first, then other fields (InternalID only with --show-uid)
+		row := []string{fmt.Sprintf("%d", displayID)} // ID column
+		if tableShowUID {
+			row = append(row, todo.InternalID) // UID column
+		}
+		t.AddRow(append(row,
+			task,                          // Task column
+			priorityStr,                   // Priority column
+			dueAtStr,                      // DueAt column
+			strings.Join(todo.Tags, ", "), // Tags column
+			completedEmoji,                // Completed column
+			createdAtStr,                  // CreatedAt column
+			updatedAtStr,                  // UpdatedAt column
+			colorize("<green>%s</green>", completedAtStr), // CompletedAt column
+		)...)
 	}
 
 	t.Render()
 }
 
 func (todoList *TodoList) filterIncomplete() {
+	// Keep only incomplete tasks
+	todoList.filter(func(todo Todo) bool {
+		return !todo.Completed
+	})
+}
+
+// filter keeps only the items for which keep returns true
+func (todoList *TodoList) filter(keep func(Todo) bool) {
+	// Remember the original positions so IDs still match the stored order
+	todoList.assignPositions()
+
 	t := *todoList
 	filtered := make(TodoList, 0)
 
-	// Keep only incomplete tasks
 	for _, todo := range t {
-		if !todo.Completed {
+		if keep(todo) {
 			filtered = append(filtered, todo)
 		}
 	}
@@ -352,33 +566,228 @@ func (todoList *TodoList) filterIncomplete() {
 	*todoList = filtered
 }
 
-// GetStoragePath returns the appropriate storage path based on the global flag
-func GetStoragePath(isGlobal bool) (string, error) {
-	if !isGlobal {
-		return ".todos.json", nil
+// partitionTodos splits a list into the items that match and the items that remain
+func partitionTodos(todoList TodoList, match func(Todo) bool) ([]Todo, TodoList) {
+	var matched []Todo
+	var remaining TodoList
+
+	for _, item := range todoList {
+		if match(item) {
+			matched = append(matched, item)
+		} else {
+			remaining = append(remaining, item)
+		}
+	}
+
+	return matched, remaining
+}
+
+// appendMovedItems appends items moved from another list (archived or restored),
+// preserving their IDs, timestamps and completion status
+func appendMovedItems(todoList *TodoList, items []Todo) {
+	for _, item := range items {
+		item.position = 0
+		*todoList = append(*todoList, item)
+	}
+}
+
+// appendTodos adds items moved from another list to the end of a stored list. Backends
+// that keep rows only write the new items; others load and save the whole list.
+func appendTodos(storage *Storage[TodoList], items []Todo) error {
+	rows, isRowStore := storage.backend.(backend.RowStore)
+	if !isRowStore {
+		todoList, err := storage.Load()
+		if err != nil {
+			return err
+		}
+		appendMovedItems(&todoList, items)
+		return storage.Save(todoList)
 	}
 
-	// Get user's home directory
-	homeDir, err := os.UserHomeDir()
+	var moved TodoList
+	appendMovedItems(&moved, items)
+	fileData, err := json.Marshal(moved)
 	if err != nil {
-		return "", fmt.Errorf("unable to get user home directory: %w", err)
+		return fmt.Errorf("error marshaling data to JSON: %w", err)
+	}
+	if err := rows.Append(fileData); err != nil {
+		return err
 	}
+	storage.appended = fileData
+	return nil
+}
 
-	// Create ~/.todo directory if it doesn't exist
-	todoDir := filepath.Join(homeDir, ".todo")
-	if err := os.MkdirAll(todoDir, 0755); err != nil {
-		return "", fmt.Errorf("unable to create todo directory: %w", err)
+// queryTodoList loads the items of a list that may match q. Backends that keep rows
+// only read the matching items, keeping their positions so IDs match the whole list;
+// others load every item. Callers still apply their own filters.
+func queryTodoList(storagePath string, q backend.Query) (*TodoList, error) {
+	storage := NewStorage[TodoList](storagePath)
+	rows, isRowStore := storage.backend.(backend.RowStore)
+	if !isRowStore {
+		todoList, _, err := initializeTodoListWithPath(storagePath)
+		return todoList, err
 	}
 
+	matches, err := rows.Query(q)
+	if err != nil {
+		return nil, fmt.Errorf("error loading todos: %w", err)
+	}
+
+	todoList := make(TodoList, 0, len(matches))
+	for _, row := range matches {
+		var todo Todo
+		if err := json.Unmarshal(row.Item, &todo); err != nil {
+			return nil, fmt.Errorf("error loading todos: item %d: %w", row.Position, err)
+		}
+		todo.position = row.Position
+		todoList = append(todoList, todo)
+	}
+	return &todoList, nil
+}
+
+// confirmItems lists the items and asks the user to confirm the action on stdin
+func confirmItems(action, description string, items []Todo) (bool, error) {
+	fmt.Printf("Found %d %s item(s) to %s:\n", len(items), description, action)
+	for i, item := range items {
+		fmt.Printf("  %d. %s\n", i+1, item.Task)
+	}
+
+	fmt.Printf("\nAre you sure you want to %s these %d %s item(s)? (y/N): ", action, len(items), description)
+
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("error reading confirmation: %w", err)
+	}
+
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes", nil
+}
+
+// localStorageFile is the todo file looked up from the current directory upward
+const localStorageFile = ".todos.json"
+
+// StorageOptions selects the todo list a command works with
+type StorageOptions struct {
+	Global      bool   // use the global list in ~/.todo
+	ListName    string // use a list registered with 'todo lists add'
+	UseActive   bool   // fall back to the list selected with 'todo use'
+	File        string // use this todo file directly (--file / TODO_FILE)
+	ArchiveFile string // use this archive file instead of the derived one (--archive-file)
+}
+
+// GetStorageOptions reads the list selection from the global flags
+func GetStorageOptions(c *cli.Command) StorageOptions {
+	return StorageOptions{
+		Global:      c.Bool("global"),
+		ListName:    c.String("list-name"),
+		UseActive:   true,
+		File:        c.String("file"),
+		ArchiveFile: c.String("archive-file"),
+	}
+}
+
+// GetStoragePath returns the appropriate storage path for the selected list
+func GetStoragePath(opts StorageOptions) (string, error) {
+	opts, list, err := resolveStorageOptions(opts)
+	if err != nil {
+		return "", err
+	}
+	if list != nil {
+		return list.Path, nil
+	}
+	if opts.File != "" {
+		return opts.File, nil
+	}
+
+	if !opts.Global {
+		storagePath, _, err := findLocalStoragePath()
+		return storagePath, err
+	}
+
+	todoDir, err := getTodoDir()
+	if err != nil {
+		return "", err
+	}
 	return filepath.Join(todoDir, "todos.json"), nil
 }
 
-// GetEffectiveStoragePath returns the appropriate storage path based on both global and archive flags
-func GetEffectiveStoragePath(isGlobal, isArchive bool) (string, error) {
+// findLocalStoragePath searches the current directory and its parents for an existing
+// .todos.json, stopping at the filesystem root or the top of a git repository.
+// When none is found it returns .todos.json in the current directory.
+func findLocalStoragePath() (string, bool, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", false, fmt.Errorf("unable to get current directory: %w", err)
+	}
+
+	for dir := cwd; ; {
+		candidate := filepath.Join(dir, localStorageFile)
+		if backend.Exists(storageBackend, candidate) {
+			if dir == cwd {
+				return localStorageFile, true, nil
+			}
+			return candidate, true, nil
+		}
+
+		// Don't look past the repository the user is working in
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			break
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	return localStorageFile, false, nil
+}
+
+// RequireTodoFile fails when the selected list is local and no .todos.json exists yet,
+// so commands never create one implicitly; new local lists are created with 'todo init'
+func RequireTodoFile(opts StorageOptions) error {
+	resolved, list, err := resolveStorageOptions(opts)
+	if err != nil {
+		return err
+	}
+	if list != nil || resolved.File != "" || resolved.Global {
+		return nil
+	}
+
+	if _, found, err := findLocalStoragePath(); err != nil || found {
+		return err
+	}
+	return fmt.Errorf("no %s found in this directory or its parents; run 'todo init' to create one (or use --global)", localStorageFile)
+}
+
+// DebugStoragePaths prints the todo and archive files the command flags resolve to
+func DebugStoragePaths(c *cli.Command) {
+	opts := GetStorageOptions(c)
+
+	storagePath, err := GetStoragePath(opts)
+	if err != nil {
+		fmt.Printf("DEBUG: Unable to resolve todo file: %v\n", err)
+		return
+	}
+	archivePath, err := GetArchivePath(opts)
+	if err != nil {
+		fmt.Printf("DEBUG: Unable to resolve archive file: %v\n", err)
+		return
+	}
+
+	fmt.Printf("DEBUG: Backend: %s\n", storageBackend)
+	fmt.Printf("DEBUG: Todo file: %s\n", storagePath)
+	fmt.Printf("DEBUG: Archive file: %s\n", archivePath)
+}
+
+// GetEffectiveStoragePath returns the appropriate storage path based on both the list selection and archive flag
+func GetEffectiveStoragePath(opts StorageOptions, isArchive bool) (string, error) {
 	if isArchive {
-		return GetArchivePath(isGlobal)
+		return GetArchivePath(opts)
 	}
-	return GetStoragePath(isGlobal)
+	return GetStoragePath(opts)
 }
 
 // IsCommandAllowedWithArchive checks if a command is allowed when using the --archive flag
@@ -386,6 +795,9 @@ func IsCommandAllowedWithArchive(commandName string) bool {
 	allowedCommands := map[string]bool{
 		"list":   true,
 		"delete": true,
+		"toggle": true,
+		"export": true,
+		"import": true,
 	}
 	return allowedCommands[commandName]
 }
@@ -393,17 +805,65 @@ func IsCommandAllowedWithArchive(commandName string) bool {
 // ValidateArchiveFlagUsage validates that --archive flag is only used with supported commands
 func ValidateArchiveFlagUsage(c *cli.Command, commandName string) error {
 	if c.Bool("archive") && !IsCommandAllowedWithArchive(commandName) {
-		return fmt.Errorf("--archive flag is only supported with 'list' and 'delete' commands, not '%s'", commandName)
+		return fmt.Errorf("--archive flag is only supported with 'list', 'delete', 'toggle', 'export' and 'import' commands, not '%s'", commandName)
 	}
 	return nil
 }
 
-// GetArchivePath returns the appropriate archive storage path based on the global flag
-func GetArchivePath(isGlobal bool) (string, error) {
-	if !isGlobal {
-		return ".todos.archive.json", nil
+// GetArchivePath returns the appropriate archive storage path for the selected list
+func GetArchivePath(opts StorageOptions) (string, error) {
+	opts, list, err := resolveStorageOptions(opts)
+	if err != nil {
+		return "", err
+	}
+	if opts.ArchiveFile != "" {
+		return opts.ArchiveFile, nil
+	}
+	if list != nil {
+		if list.ArchivePath != "" {
+			return list.ArchivePath, nil
+		}
+		return deriveArchivePath(list.Path), nil
+	}
+	if opts.File != "" {
+		return deriveArchivePath(opts.File), nil
+	}
+
+	if !opts.Global {
+		// A project or user config may move the local archive
+		cfg, err := loadFileConfig()
+		if err != nil {
+			return "", err
+		}
+		if cfg.ArchivePath != "" {
+			return cfg.ArchivePath, nil
+		}
+
+		storagePath, _, err := findLocalStoragePath()
+		if err != nil {
+			return "", err
+		}
+		return deriveArchivePath(storagePath), nil
 	}
 
+	todoDir, err := getTodoDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(todoDir, "todos.archive.json"), nil
+}
+
+// deriveArchivePath returns the archive file that sits next to a todo file (foo.json -> foo.archive.json)
+func deriveArchivePath(storagePath string) string {
+	ext := filepath.Ext(storagePath)
+	if ext == "" {
+		return storagePath + ".archive.json"
+	}
+	return strings.TrimSuffix(storagePath, ext) + ".archive" + ext
+}
+
+// getTodoDir returns the ~/.todo directory, creating it if it doesn't exist
+func getTodoDir() (string, error) {
 	// Get user's home directory
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -416,7 +876,7 @@ func GetArchivePath(isGlobal bool) (string, error) {
 		return "", fmt.Errorf("unable to create todo directory: %w", err)
 	}
 
-	return filepath.Join(todoDir, "todos.archive.json"), nil
+	return todoDir, nil
 }
 
 // CheckAndExecuteListFlag checks if the --list flag is set and executes list command if so
@@ -425,14 +885,14 @@ func CheckAndExecuteListFlag(c *cli.Command) bool {
 	return c.Bool("list")
 }
 
-// ExecuteListCommand executes the list command with table format
+// ExecuteListCommand executes the list command with the configured format
 func ExecuteListCommand(c *cli.Command) error {
 	if c.Bool("debug") {
 		fmt.Println("DEBUG: Executing list command after main action")
 	}
 
-	// Get the appropriate storage path based on global and archive flags
-	storagePath, err := GetEffectiveStoragePath(c.Bool("global"), c.Bool("archive"))
+	// Get the appropriate storage path based on list selection and archive flag
+	storagePath, err := GetEffectiveStoragePath(GetStorageOptions(c), c.Bool("archive"))
 	if err != nil {
 		return fmt.Errorf("error getting storage path: %w", err)
 	}
@@ -443,9 +903,14 @@ func ExecuteListCommand(c *cli.Command) error {
 		return fmt.Errorf("failed to initialize todo list: %w", err)
 	}
 
-	// Display todos with table format (default)
+	cfg := CurrentConfig()
+
+	// Display todos with the configured format and sort order (table by default)
+	if err := todoList.SortBy(cfg.Sort); err != nil {
+		return err
+	}
 	fmt.Println() // Add a blank line before list output
-	todoList.View("table")
+	todoList.View(cfg.Format)
 	return nil
 }
 
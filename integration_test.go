@@ -1,12 +1,16 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 // TestCLIIntegration tests the CLI application end-to-end
@@ -31,6 +35,9 @@ func TestCLIIntegration(t *testing.T) {
 	defer os.Chdir(oldWd)
 	os.Chdir(tempDir)
 
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+
 	tests := []struct {
 		name           string
 		args           []string
@@ -67,7 +74,7 @@ func TestCLIIntegration(t *testing.T) {
 		{
 			name:           "delete without args",
 			args:           []string{"delete"},
-			expectedOutput: "exactly one ID is required",
+			expectedOutput: "at least one ID is required",
 			expectError:    true,
 			expectedCode:   1,
 		},
@@ -88,7 +95,7 @@ func TestCLIIntegration(t *testing.T) {
 		{
 			name:           "toggle without args",
 			args:           []string{"toggle"},
-			expectedOutput: "exactly one ID is required",
+			expectedOutput: "at least one ID is required",
 			expectError:    true,
 			expectedCode:   1,
 		},
@@ -119,6 +126,7 @@ func TestCLIIntegration(t *testing.T) {
 			oldWd, _ := os.Getwd()
 			defer os.Chdir(oldWd)
 			os.Chdir(testTempDir)
+			runTodo(t, buildPath, "init")
 
 			cmd := exec.Command(buildPath, tt.args...)
 			output, err := cmd.CombinedOutput()
@@ -168,6 +176,9 @@ func TestCLIWorkflow(t *testing.T) {
 	defer os.Chdir(oldWd)
 	os.Chdir(tempDir)
 
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+
 	// Test workflow: add -> list -> edit -> toggle -> delete
 
 	// 1. Add a task
@@ -351,6 +362,9 @@ func TestCLIGlobalStorage(t *testing.T) {
 	defer os.Chdir(oldWd)
 	os.Chdir(tempDir)
 
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+
 	// Test adding todo to global storage
 	t.Run("add_global_todo", func(t *testing.T) {
 		cmd := exec.Command(buildPath, "--global", "add", "Global test task")
@@ -516,6 +530,9 @@ func TestCLIArchive(t *testing.T) {
 	defer os.Chdir(oldWd)
 	os.Chdir(tempDir)
 
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+
 	// Test archive without items
 	t.Run("archive_invalid_id", func(t *testing.T) {
 		cmd := exec.Command(buildPath, "archive", "1")
@@ -587,7 +604,7 @@ func TestCLIArchive(t *testing.T) {
 			t.Errorf("Expected archive to fail without arguments, but it succeeded")
 		}
 
-		expectedMsg := "exactly one ID is required"
+		expectedMsg := "at least one ID is required"
 		if !strings.Contains(string(output), expectedMsg) {
 			t.Errorf("Expected %q in error output, got: %s", expectedMsg, output)
 		}
@@ -705,7 +722,7 @@ func TestCLIListFlag(t *testing.T) {
 	os.Chdir(tempDir)
 
 	// Clean up any existing todos
-	os.Remove(".todos.json")
+	os.WriteFile(".todos.json", []byte("[]"), 0644)
 
 	t.Run("list_flag_with_add", func(t *testing.T) {
 		cmd := exec.Command(buildPath, "add", "Test item for list flag", "--list")
@@ -842,7 +859,7 @@ func TestCLICleanup(t *testing.T) {
 	os.Chdir(tempDir)
 
 	// Clean up any existing todos
-	os.Remove(".todos.json")
+	os.WriteFile(".todos.json", []byte("[]"), 0644)
 
 	t.Run("cleanup_no_completed_items", func(t *testing.T) {
 		// Add some incomplete items
@@ -971,7 +988,7 @@ func TestCLICleanup(t *testing.T) {
 
 	t.Run("cleanup_with_delete_flag", func(t *testing.T) {
 		// Clean up any existing todos first
-		os.Remove(".todos.json")
+		os.WriteFile(".todos.json", []byte("[]"), 0644)
 		os.Remove(".todos.archive.json")
 
 		// Add and complete some items for delete testing
@@ -1101,6 +1118,9 @@ func TestCLIGlobalCleanup(t *testing.T) {
 	defer os.Chdir(oldWd)
 	os.Chdir(tempDir)
 
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+
 	t.Run("setup_global_items", func(t *testing.T) {
 		// Add some global items
 		cmd := exec.Command(buildPath, "--global", "add", "Global completed item")
@@ -1178,7 +1198,7 @@ func TestCLIArchiveFlag(t *testing.T) {
 	os.Chdir(tempDir)
 
 	// Clean up any existing files
-	os.Remove(".todos.json")
+	os.WriteFile(".todos.json", []byte("[]"), 0644)
 	os.Remove(".todos.archive.json")
 
 	t.Run("archive_flag_with_list_command", func(t *testing.T) {
@@ -1241,18 +1261,7 @@ func TestCLIArchiveFlag(t *testing.T) {
 			t.Errorf("Expected error when using --archive with add command")
 		}
 
-		if !strings.Contains(string(output), "--archive flag is only supported with 'list' and 'delete' commands") {
-			t.Errorf("Expected validation error message, got: %s", output)
-		}
-
-		// Test that toggle command is blocked
-		cmd = exec.Command(buildPath, "--archive", "toggle", "1")
-		output, err = cmd.CombinedOutput()
-		if err == nil {
-			t.Errorf("Expected error when using --archive with toggle command")
-		}
-
-		if !strings.Contains(string(output), "--archive flag is only supported with 'list' and 'delete' commands") {
+		if !strings.Contains(string(output), "--archive flag is only supported with 'list', 'delete', 'toggle', 'export' and 'import' commands") {
 			t.Errorf("Expected validation error message, got: %s", output)
 		}
 
@@ -1263,7 +1272,7 @@ func TestCLIArchiveFlag(t *testing.T) {
 			t.Errorf("Expected error when using --archive with edit command")
 		}
 
-		if !strings.Contains(string(output), "--archive flag is only supported with 'list' and 'delete' commands") {
+		if !strings.Contains(string(output), "--archive flag is only supported with 'list', 'delete', 'toggle', 'export' and 'import' commands") {
 			t.Errorf("Expected validation error message, got: %s", output)
 		}
 
@@ -1274,7 +1283,7 @@ func TestCLIArchiveFlag(t *testing.T) {
 			t.Errorf("Expected error when using --archive with archive command")
 		}
 
-		if !strings.Contains(string(output), "--archive flag is only supported with 'list' and 'delete' commands") {
+		if !strings.Contains(string(output), "--archive flag is only supported with 'list', 'delete', 'toggle', 'export' and 'import' commands") {
 			t.Errorf("Expected validation error message, got: %s", output)
 		}
 
@@ -1285,7 +1294,7 @@ func TestCLIArchiveFlag(t *testing.T) {
 			t.Errorf("Expected error when using --archive with cleanup command")
 		}
 
-		if !strings.Contains(string(output), "--archive flag is only supported with 'list' and 'delete' commands") {
+		if !strings.Contains(string(output), "--archive flag is only supported with 'list', 'delete', 'toggle', 'export' and 'import' commands") {
 			t.Errorf("Expected validation error message, got: %s", output)
 		}
 	})
@@ -1396,8 +1405,8 @@ func TestCLIArchiveFlag(t *testing.T) {
 			t.Errorf("Expected error when deleting from empty archive")
 		}
 
-		if !strings.Contains(string(output), "failed to delete task: invalid index: 0") {
-			t.Errorf("Expected invalid index error message, got: %s", output)
+		if !strings.Contains(string(output), "failed to delete task: invalid ID: 1 (valid range: 1-0)") {
+			t.Errorf("Expected invalid ID error message, got: %s", output)
 		}
 	})
 }
@@ -1422,9 +1431,12 @@ func TestCLIFilterFlag(t *testing.T) {
 	defer os.Chdir(oldWd)
 	os.Chdir(tempDir)
 
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+
 	t.Run("filter_mixed_completion_status", func(t *testing.T) {
 		// Clean up any existing todos file
-		os.Remove(".todos.json")
+		os.WriteFile(".todos.json", []byte("[]"), 0644)
 
 		// Add multiple tasks
 		tasks := []string{"Task 1", "Task 2", "Task 3", "Task 4"}
@@ -1490,7 +1502,7 @@ func TestCLIFilterFlag(t *testing.T) {
 
 	t.Run("filter_all_completed", func(t *testing.T) {
 		// Clean up any existing todos file
-		os.Remove(".todos.json")
+		os.WriteFile(".todos.json", []byte("[]"), 0644)
 
 		// Add tasks and complete all of them
 		tasks := []string{"Completed Task 1", "Completed Task 2"}
@@ -1526,7 +1538,7 @@ func TestCLIFilterFlag(t *testing.T) {
 
 	t.Run("filter_none_completed", func(t *testing.T) {
 		// Clean up any existing todos file
-		os.Remove(".todos.json")
+		os.WriteFile(".todos.json", []byte("[]"), 0644)
 
 		// Add tasks but don't complete any
 		tasks := []string{"Incomplete Task 1", "Incomplete Task 2"}
@@ -1562,7 +1574,7 @@ func TestCLIFilterFlag(t *testing.T) {
 
 	t.Run("filter_with_different_formats", func(t *testing.T) {
 		// Clean up any existing todos file
-		os.Remove(".todos.json")
+		os.WriteFile(".todos.json", []byte("[]"), 0644)
 
 		// Add mixed tasks
 		cmd := exec.Command(buildPath, "add", "Format Test Complete")
@@ -1672,3 +1684,1874 @@ func TestCLIFilterFlag(t *testing.T) {
 		}
 	})
 }
+
+// runTodo runs the built CLI with the given arguments and fails the test on error
+func runTodo(t *testing.T, buildPath string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command(buildPath, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Failed to run %v: %v\nOutput: %s", args, err, output)
+	}
+
+	return string(output)
+}
+
+// TestCLIPriority tests setting priorities and sorting by priority
+func TestCLIPriority(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+
+	runTodo(t, buildPath, "add", "Low task", "--priority", "low")
+	runTodo(t, buildPath, "add", "Plain task")
+	runTodo(t, buildPath, "add", "--priority", "high", "High task")
+	runTodo(t, buildPath, "edit", "2", "--priority", "medium")
+
+	t.Run("sort_by_priority_keeps_ids", func(t *testing.T) {
+		output := runTodo(t, buildPath, "list", "--sort", "priority", "--format", "json")
+
+		high := strings.Index(output, `{"id":3,"task":"High task","priority":"high"`)
+		medium := strings.Index(output, `{"id":2,"task":"Plain task","priority":"medium"`)
+		low := strings.Index(output, `{"id":1,"task":"Low task","priority":"low"`)
+		if high < 0 || medium < 0 || low < 0 {
+			t.Fatalf("Expected all prioritized tasks with stable IDs, got: %s", output)
+		}
+		if !(high < medium && medium < low) {
+			t.Errorf("Expected tasks ordered high, medium, low, got: %s", output)
+		}
+	})
+
+	t.Run("invalid_priority", func(t *testing.T) {
+		cmd := exec.Command(buildPath, "add", "--priority", "urgent", "Bad task")
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Errorf("Expected error for invalid priority")
+		}
+		if !strings.Contains(string(output), "invalid priority: urgent") {
+			t.Errorf("Expected invalid priority message, got: %s", output)
+		}
+	})
+
+	t.Run("invalid_sort_key", func(t *testing.T) {
+		cmd := exec.Command(buildPath, "list", "--sort", "color")
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Errorf("Expected error for invalid sort key")
+		}
+		if !strings.Contains(string(output), "invalid sort key: color") {
+			t.Errorf("Expected invalid sort key message, got: %s", output)
+		}
+	})
+}
+
+// TestCLIDueDates tests due dates and the due date filters of list
+func TestCLIDueDates(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+
+	runTodo(t, buildPath, "add", "Overdue task", "--due", "2020-01-01")
+	runTodo(t, buildPath, "add", "Future task", "--due", "in 3d")
+	runTodo(t, buildPath, "add", "Someday task")
+	runTodo(t, buildPath, "edit", "3", "--due", "2099-12-31")
+
+	t.Run("overdue_filter", func(t *testing.T) {
+		output := runTodo(t, buildPath, "list", "--overdue", "--format", "json")
+		if !strings.Contains(output, `"id":1,"task":"Overdue task"`) {
+			t.Errorf("Expected overdue task in output, got: %s", output)
+		}
+		if strings.Contains(output, "Future task") || strings.Contains(output, "Someday task") {
+			t.Errorf("Expected only overdue tasks, got: %s", output)
+		}
+	})
+
+	t.Run("due_range_filter", func(t *testing.T) {
+		output := runTodo(t, buildPath, "list", "--due-after", "today", "--due-before", "2030-01-01", "--format", "json")
+		if !strings.Contains(output, `"id":2,"task":"Future task"`) {
+			t.Errorf("Expected future task in output, got: %s", output)
+		}
+		if strings.Contains(output, "Overdue task") || strings.Contains(output, "Someday task") {
+			t.Errorf("Expected only tasks in range, got: %s", output)
+		}
+	})
+
+	t.Run("due_date_stored_as_rfc3339", func(t *testing.T) {
+		output := runTodo(t, buildPath, "list", "--format", "json")
+		if !strings.Contains(output, `"due_at":"2099-12-31T23:59:59`) {
+			t.Errorf("Expected RFC3339 due date in output, got: %s", output)
+		}
+	})
+
+	t.Run("invalid_due_date", func(t *testing.T) {
+		cmd := exec.Command(buildPath, "add", "Bad task", "--due", "someday")
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Errorf("Expected error for invalid due date")
+		}
+		if !strings.Contains(string(output), "invalid due date: someday") {
+			t.Errorf("Expected invalid due date message, got: %s", output)
+		}
+	})
+}
+
+// TestCLITags tests tagging tasks, filtering by tag and the tags command
+func TestCLITags(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+
+	output := runTodo(t, buildPath, "add", "Deploy +backend service")
+	if !strings.Contains(output, "Added task: Deploy service") {
+		t.Errorf("Expected +tag tokens to be stripped from the task, got: %s", output)
+	}
+	runTodo(t, buildPath, "add", "Write API docs", "--tag", "backend", "--tag", "docs")
+	runTodo(t, buildPath, "add", "Cut release")
+	runTodo(t, buildPath, "edit", "3", "+release")
+	runTodo(t, buildPath, "toggle", "1")
+
+	t.Run("include_and_exclude_tags", func(t *testing.T) {
+		output := runTodo(t, buildPath, "list", "--tag", "backend", "--tag", "!docs", "--format", "json")
+		if !strings.Contains(output, `"id":1,"task":"Deploy service","tags":["backend"]`) {
+			t.Errorf("Expected backend task in output, got: %s", output)
+		}
+		if strings.Contains(output, "Write API docs") || strings.Contains(output, "Cut release") {
+			t.Errorf("Expected docs and untagged tasks to be filtered, got: %s", output)
+		}
+	})
+
+	t.Run("tags_command_counts", func(t *testing.T) {
+		output := runTodo(t, buildPath, "tags", "--format", "json")
+		expected := `[{"tag":"backend","open":1,"completed":1},{"tag":"docs","open":1,"completed":0},{"tag":"release","open":1,"completed":0}]`
+		if strings.TrimSpace(output) != expected {
+			t.Errorf("Expected %s, got: %s", expected, output)
+		}
+	})
+}
+
+// TestCLIWhere tests --where queries on list, archive, delete and cleanup
+func TestCLIWhere(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+
+	runTodo(t, buildPath, "add", "Deploy +backend service")
+	runTodo(t, buildPath, "add", "Write +docs")
+	runTodo(t, buildPath, "add", "Deploy +frontend")
+	runTodo(t, buildPath, "add", "Old +docs draft")
+	runTodo(t, buildPath, "toggle", "4")
+
+	t.Run("list_where", func(t *testing.T) {
+		output := runTodo(t, buildPath, "list", "--where", `completed=false and task~"deploy" and not tag:frontend`, "--format", "json")
+		if !strings.Contains(output, `"id":1,"task":"Deploy service"`) {
+			t.Errorf("Expected backend deploy task, got: %s", output)
+		}
+		if strings.Count(output, `"task":`) != 1 {
+			t.Errorf("Expected exactly one matching task, got: %s", output)
+		}
+	})
+
+	t.Run("list_where_parse_error", func(t *testing.T) {
+		cmd := exec.Command(buildPath, "list", "--where", "completed=false and")
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Errorf("Expected error for invalid query")
+		}
+		if !strings.Contains(string(output), "invalid --where expression at position 20") {
+			t.Errorf("Expected parse error with position, got: %s", output)
+		}
+	})
+
+	t.Run("archive_where", func(t *testing.T) {
+		output := runTodo(t, buildPath, "archive", "--where", "tag:docs and completed=true", "--force")
+		if !strings.Contains(output, "Successfully archived 1 matching item(s).") {
+			t.Errorf("Expected archive confirmation, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "--archive", "list", "--format", "json")
+		if !strings.Contains(output, "Old draft") {
+			t.Errorf("Expected archived task in archive, got: %s", output)
+		}
+	})
+
+	t.Run("delete_where", func(t *testing.T) {
+		output := runTodo(t, buildPath, "delete", "--where", "tag:frontend", "--force")
+		if !strings.Contains(output, "Successfully deleted 1 matching item(s).") {
+			t.Errorf("Expected delete confirmation, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "list", "--format", "json")
+		if strings.Contains(output, "Deploy frontend") {
+			t.Errorf("Deleted task should not appear in list, got: %s", output)
+		}
+	})
+
+	t.Run("cleanup_where", func(t *testing.T) {
+		output := runTodo(t, buildPath, "cleanup", "--delete", "--force", "--where", "tag:docs")
+		if !strings.Contains(output, "Successfully deleted 1 matching item(s).") {
+			t.Errorf("Expected cleanup confirmation, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "list", "--format", "json")
+		if strings.Contains(output, "Write") || !strings.Contains(output, "Deploy service") {
+			t.Errorf("Expected only the backend task to remain, got: %s", output)
+		}
+	})
+
+	t.Run("where_with_id_rejected", func(t *testing.T) {
+		cmd := exec.Command(buildPath, "delete", "1", "--where", "tag:docs")
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Errorf("Expected error when combining an ID with --where")
+		}
+		if !strings.Contains(string(output), "an ID cannot be combined with --where") {
+			t.Errorf("Expected combination error, got: %s", output)
+		}
+	})
+}
+
+// TestCLIUndoRedo tests undoing and redoing mutating commands
+func TestCLIUndoRedo(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+
+	t.Run("nothing_to_undo", func(t *testing.T) {
+		output := runTodo(t, buildPath, "undo")
+		if !strings.Contains(output, "Nothing to undo.") {
+			t.Errorf("Expected nothing to undo, got: %s", output)
+		}
+	})
+
+	runTodo(t, buildPath, "add", "Keep me")
+	runTodo(t, buildPath, "add", "Delete me")
+
+	t.Run("undo_delete", func(t *testing.T) {
+		runTodo(t, buildPath, "delete", "2")
+
+		output := runTodo(t, buildPath, "undo")
+		if !strings.Contains(output, "Undid: delete 2") {
+			t.Errorf("Expected undo confirmation, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "list", "--format", "json")
+		if !strings.Contains(output, "Delete me") {
+			t.Errorf("Expected deleted task to be restored, got: %s", output)
+		}
+	})
+
+	t.Run("redo_delete", func(t *testing.T) {
+		output := runTodo(t, buildPath, "redo")
+		if !strings.Contains(output, "Redid: delete 2") {
+			t.Errorf("Expected redo confirmation, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "list", "--format", "json")
+		if strings.Contains(output, "Delete me") {
+			t.Errorf("Expected task to be deleted again, got: %s", output)
+		}
+	})
+
+	t.Run("undo_cleanup_restores_both_files", func(t *testing.T) {
+		runTodo(t, buildPath, "toggle", "1")
+		runTodo(t, buildPath, "cleanup", "--force")
+
+		runTodo(t, buildPath, "undo")
+
+		output := runTodo(t, buildPath, "list", "--format", "json")
+		if !strings.Contains(output, "Keep me") {
+			t.Errorf("Expected cleaned up task to be back in the list, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "--archive", "list", "--format", "json")
+		if strings.TrimSpace(output) != "null" {
+			t.Errorf("Expected archive to be empty after undo, got: %s", output)
+		}
+	})
+
+	t.Run("undo_refuses_after_manual_edit", func(t *testing.T) {
+		os.WriteFile(".todos.json", []byte(`[]`), 0644)
+
+		cmd := exec.Command(buildPath, "undo")
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Errorf("Expected undo to fail after a manual edit")
+		}
+		if !strings.Contains(string(output), "has changed since this entry was recorded") {
+			t.Errorf("Expected conflict message, got: %s", output)
+		}
+	})
+}
+
+// TestCLIConcurrentAdds tests that parallel invocations don't lose updates
+func TestCLIConcurrentAdds(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("USERPROFILE", homeDir)
+
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+
+	const workers = 20
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		go func(i int) {
+			cmd := exec.Command(buildPath, "--lock-timeout", "30s", "add", fmt.Sprintf("Parallel task %d", i))
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				err = fmt.Errorf("add %d failed: %v\nOutput: %s", i, err, output)
+			}
+			errs <- err
+		}(i)
+	}
+	for i := 0; i < workers; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+
+	output := runTodo(t, buildPath, "list", "--format", "json")
+	if count := strings.Count(output, `"task":`); count != workers {
+		t.Errorf("Expected %d tasks after parallel adds, got %d: %s", workers, count, output)
+	}
+
+	// The lock is kept in the home directory, not next to the project's list
+	if _, err := os.Stat(".todos.json.lock"); !os.IsNotExist(err) {
+		t.Errorf("Expected no lock file next to the list")
+	}
+	if locks, _ := filepath.Glob(filepath.Join(homeDir, ".todo", "locks", "todos.json-*.lock")); len(locks) != 1 {
+		t.Errorf("Expected one lock file in ~/.todo/locks, got: %v", locks)
+	}
+}
+
+// TestCLINamedLists tests registering, selecting and removing named lists
+func TestCLINamedLists(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("USERPROFILE", homeDir)
+	t.Setenv("TODO_LIST", "")
+
+	workPath := filepath.Join(homeDir, "work", "todos.json")
+
+	t.Run("add_and_use", func(t *testing.T) {
+		output := runTodo(t, buildPath, "lists", "add", "work", "~/work/todos.json")
+		if !strings.Contains(output, workPath) {
+			t.Errorf("Expected lists add to report %s, got: %s", workPath, output)
+		}
+
+		runTodo(t, buildPath, "use", "work")
+		runTodo(t, buildPath, "add", "Work task")
+
+		data, err := os.ReadFile(workPath)
+		if err != nil || !strings.Contains(string(data), "Work task") {
+			t.Errorf("Expected task in %s, got: %s (%v)", workPath, data, err)
+		}
+		if data, _ := os.ReadFile(".todos.json"); strings.Contains(string(data), "Work task") {
+			t.Errorf("Expected the local list to stay untouched, got: %s", data)
+		}
+
+		output = runTodo(t, buildPath, "lists", "ls")
+		if !strings.Contains(output, "work") || !strings.Contains(output, "*") {
+			t.Errorf("Expected lists ls to show the active work list, got: %s", output)
+		}
+	})
+
+	t.Run("list_name_flag_overrides_active", func(t *testing.T) {
+		runTodo(t, buildPath, "--list-name", "local", "add", "Local task")
+
+		output := runTodo(t, buildPath, "list", "--format", "json")
+		if !strings.Contains(output, "Work task") || strings.Contains(output, "Local task") {
+			t.Errorf("Expected the active list to hold only the work task, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "--list-name", "local", "list", "--format", "json")
+		if !strings.Contains(output, "Local task") {
+			t.Errorf("Expected --list-name local to show the local task, got: %s", output)
+		}
+	})
+
+	t.Run("archive_follows_list", func(t *testing.T) {
+		runTodo(t, buildPath, "archive", "1")
+
+		archivePath := filepath.Join(homeDir, "work", "todos.archive.json")
+		data, err := os.ReadFile(archivePath)
+		if err != nil || !strings.Contains(string(data), "Work task") {
+			t.Errorf("Expected archived task in %s, got: %s (%v)", archivePath, data, err)
+		}
+	})
+
+	t.Run("rm_resets_active", func(t *testing.T) {
+		output := runTodo(t, buildPath, "lists", "rm", "work")
+		if !strings.Contains(output, "Active list reset to 'local'") {
+			t.Errorf("Expected active list reset message, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "list", "--format", "json")
+		if !strings.Contains(output, "Local task") {
+			t.Errorf("Expected the local list after removing work, got: %s", output)
+		}
+
+		cmd := exec.Command(buildPath, "--list-name", "work", "list")
+		if output, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(output), "unknown list") {
+			t.Errorf("Expected unknown list error, got: %s (%v)", output, err)
+		}
+	})
+}
+
+// TestCLIFileFlag tests pointing commands at an arbitrary todo file
+func TestCLIFileFlag(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	t.Setenv("TODO_FILE", "")
+	t.Setenv("TODO_ARCHIVE_FILE", "")
+
+	t.Run("file_flag", func(t *testing.T) {
+		runTodo(t, buildPath, "--file", "feature.json", "add", "Feature task")
+		runTodo(t, buildPath, "--file", "feature.json", "archive", "1")
+
+		if _, err := os.Stat(".todos.json"); !os.IsNotExist(err) {
+			t.Errorf("Expected .todos.json not to be created")
+		}
+
+		data, err := os.ReadFile("feature.archive.json")
+		if err != nil || !strings.Contains(string(data), "Feature task") {
+			t.Errorf("Expected task in derived archive file, got: %s (%v)", data, err)
+		}
+	})
+
+	t.Run("env_var_and_archive_file", func(t *testing.T) {
+		t.Setenv("TODO_FILE", "env.json")
+
+		runTodo(t, buildPath, "add", "Env task")
+		runTodo(t, buildPath, "--archive-file", "done.json", "archive", "1")
+
+		data, err := os.ReadFile("done.json")
+		if err != nil || !strings.Contains(string(data), "Env task") {
+			t.Errorf("Expected task in --archive-file, got: %s (%v)", data, err)
+		}
+
+		output := runTodo(t, buildPath, "--archive-file", "done.json", "--archive", "list", "--format", "json")
+		if !strings.Contains(output, "Env task") {
+			t.Errorf("Expected --archive list to read --archive-file, got: %s", output)
+		}
+	})
+}
+
+// TestCLIParentLookup tests finding the todo file from subdirectories
+func TestCLIParentLookup(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+
+	subDir := filepath.Join(tempDir, "src", "pkg")
+	os.MkdirAll(subDir, 0755)
+	os.Chdir(subDir)
+
+	t.Run("requires_init", func(t *testing.T) {
+		cmd := exec.Command(buildPath, "add", "Lost task")
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "todo init") {
+			t.Errorf("Expected add to ask for todo init, got: %s (%v)", output, err)
+		}
+		if _, err := os.Stat(".todos.json"); !os.IsNotExist(err) {
+			t.Errorf("Expected no .todos.json to be created in the subdirectory")
+		}
+	})
+
+	t.Run("uses_parent_file", func(t *testing.T) {
+		os.Chdir(tempDir)
+		runTodo(t, buildPath, "init")
+		os.Chdir(subDir)
+
+		runTodo(t, buildPath, "add", "Project task")
+
+		data, err := os.ReadFile(filepath.Join(tempDir, ".todos.json"))
+		if err != nil || !strings.Contains(string(data), "Project task") {
+			t.Errorf("Expected task in the project todo file, got: %s (%v)", data, err)
+		}
+		if _, err := os.Stat(".todos.json"); !os.IsNotExist(err) {
+			t.Errorf("Expected no .todos.json to be created in the subdirectory")
+		}
+
+		output := runTodo(t, buildPath, "--debug", "list")
+		if !strings.Contains(output, "DEBUG: Todo file: "+filepath.Join(tempDir, ".todos.json")) {
+			t.Errorf("Expected --debug to print the resolved file, got: %s", output)
+		}
+	})
+
+	t.Run("init_twice_fails", func(t *testing.T) {
+		os.Chdir(tempDir)
+		defer os.Chdir(subDir)
+
+		cmd := exec.Command(buildPath, "init")
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "already initialized") {
+			t.Errorf("Expected init to refuse an existing file, got: %s (%v)", output, err)
+		}
+	})
+}
+
+// TestCLIInitConfig tests project config created by init and its precedence
+func TestCLIInitConfig(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("USERPROFILE", homeDir)
+	t.Setenv("TODO_FORMAT", "")
+
+	output := runTodo(t, buildPath, "init")
+	if !strings.Contains(output, "Created project config .todo.toml") {
+		t.Errorf("Expected init to create .todo.toml, got: %s", output)
+	}
+
+	runTodo(t, buildPath, "add", "Configured task")
+
+	t.Run("project_config_sets_default_format", func(t *testing.T) {
+		os.WriteFile(".todo.toml", []byte("format = \"json\"\n"), 0644)
+
+		output := runTodo(t, buildPath, "list")
+		if !strings.HasPrefix(strings.TrimSpace(output), "[{") {
+			t.Errorf("Expected JSON output from project config, got: %s", output)
+		}
+
+		// The user config has lower precedence than the project config
+		os.MkdirAll(filepath.Join(homeDir, ".todo"), 0755)
+		os.WriteFile(filepath.Join(homeDir, ".todo", "config"), []byte("format = \"none\"\n"), 0644)
+
+		output = runTodo(t, buildPath, "list")
+		if !strings.HasPrefix(strings.TrimSpace(output), "[{") {
+			t.Errorf("Expected the project config to win over the user config, got: %s", output)
+		}
+	})
+
+	t.Run("env_and_flag_override_config", func(t *testing.T) {
+		t.Setenv("TODO_FORMAT", "none")
+
+		output := runTodo(t, buildPath, "list")
+		if strings.TrimSpace(output) != "" {
+			t.Errorf("Expected TODO_FORMAT=none to win over the config, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "list", "--format", "pretty")
+		if !strings.Contains(output, "\n  {") {
+			t.Errorf("Expected --format to win over TODO_FORMAT, got: %s", output)
+		}
+	})
+
+	t.Run("invalid_config", func(t *testing.T) {
+		os.WriteFile(".todo.toml", []byte("format = \"xml\"\n"), 0644)
+		defer os.WriteFile(".todo.toml", []byte(""), 0644)
+
+		cmd := exec.Command(buildPath, "list")
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "invalid format setting") {
+			t.Errorf("Expected an invalid config error, got: %s (%v)", output, err)
+		}
+	})
+}
+
+// TestCLIUnarchive tests restoring archived items
+func TestCLIUnarchive(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+
+	runTodo(t, buildPath, "add", "First task")
+	runTodo(t, buildPath, "add", "Second task")
+	runTodo(t, buildPath, "add", "Third task")
+	runTodo(t, buildPath, "toggle", "1")
+
+	before := runTodo(t, buildPath, "list", "--format", "json")
+	storedBefore, _ := os.ReadFile(".todos.json")
+	runTodo(t, buildPath, "archive", "1")
+	runTodo(t, buildPath, "archive", "1")
+
+	t.Run("archive_toggle", func(t *testing.T) {
+		runTodo(t, buildPath, "--archive", "toggle", "2")
+
+		output := runTodo(t, buildPath, "--archive", "list", "--format", "json")
+		if !strings.Contains(output, `"task":"Second task","completed":true`) {
+			t.Errorf("Expected the archived item to be toggled, got: %s", output)
+		}
+		runTodo(t, buildPath, "--archive", "toggle", "2")
+	})
+
+	t.Run("invalid_id_changes_nothing", func(t *testing.T) {
+		cmd := exec.Command(buildPath, "unarchive", "1", "5")
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "invalid ID: 5") {
+			t.Errorf("Expected invalid ID error, got: %s (%v)", output, err)
+		}
+
+		archived := runTodo(t, buildPath, "--archive", "list", "--format", "json")
+		if strings.Count(archived, `"task":`) != 2 {
+			t.Errorf("Expected the archive to be unchanged, got: %s", archived)
+		}
+	})
+
+	t.Run("unarchive_restores_items", func(t *testing.T) {
+		output := runTodo(t, buildPath, "unarchive", "1", "2")
+		if !strings.Contains(output, "Restored todo item: First task") || !strings.Contains(output, "Restored todo item: Second task") {
+			t.Errorf("Expected restore messages, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "--archive", "list", "--format", "json")
+		if strings.TrimSpace(output) != "null" {
+			t.Errorf("Expected an empty archive, got: %s", output)
+		}
+
+		// The restored items keep their timestamps and completion status
+		var original, restored []map[string]interface{}
+		json.Unmarshal([]byte(before), &original)
+		json.Unmarshal([]byte(runTodo(t, buildPath, "list", "--format", "json")), &restored)
+		if len(restored) != 3 {
+			t.Fatalf("Expected 3 items after unarchive, got %d", len(restored))
+		}
+		for _, item := range restored {
+			for _, old := range original {
+				if old["task"] == item["task"] && (old["created_at"] != item["created_at"] || old["completed"] != item["completed"]) {
+					t.Errorf("Expected %v to keep its fields, got %v", old, item)
+				}
+			}
+		}
+	})
+
+	t.Run("internal_ids_preserved", func(t *testing.T) {
+		internalIDs := func(data []byte) map[string]string {
+			var stored struct {
+				Items []map[string]interface{} `json:"items"`
+			}
+			json.Unmarshal(data, &stored)
+			ids := map[string]string{}
+			for _, item := range stored.Items {
+				ids[item["task"].(string)] = item["internal_id"].(string)
+			}
+			return ids
+		}
+
+		storedAfter, _ := os.ReadFile(".todos.json")
+		original, restored := internalIDs(storedBefore), internalIDs(storedAfter)
+		for task, id := range original {
+			if id == "" || restored[task] != id {
+				t.Errorf("Expected %s to keep internal ID %s, got %s", task, id, restored[task])
+			}
+		}
+	})
+}
+
+// TestCLIBulkIDs tests ID lists and ranges in ID-taking commands
+func TestCLIBulkIDs(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+
+	for i := 1; i <= 8; i++ {
+		runTodo(t, buildPath, "add", fmt.Sprintf("Task %d", i))
+	}
+
+	t.Run("toggle_list_and_range", func(t *testing.T) {
+		output := runTodo(t, buildPath, "toggle", "1", "3", "5-7")
+		if !strings.Contains(output, "IDs: 1, 3, 5, 6, 7") {
+			t.Errorf("Expected toggled IDs in output, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "list", "--format", "json")
+		if got := strings.Count(output, `"completed":true`); got != 5 {
+			t.Errorf("Expected 5 completed items, got %d: %s", got, output)
+		}
+	})
+
+	t.Run("invalid_id_changes_nothing", func(t *testing.T) {
+		before, _ := os.ReadFile(".todos.json")
+
+		cmd := exec.Command(buildPath, "toggle", "2", "4-9")
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "invalid ID: 9 (valid range: 1-8)") {
+			t.Errorf("Expected invalid ID error, got: %s (%v)", output, err)
+		}
+
+		after, _ := os.ReadFile(".todos.json")
+		if string(before) != string(after) {
+			t.Errorf("Expected the list to be unchanged after a failed toggle")
+		}
+	})
+
+	t.Run("edit_several_ids", func(t *testing.T) {
+		runTodo(t, buildPath, "edit", "2,4", "--priority", "high")
+
+		output := runTodo(t, buildPath, "list", "--format", "json")
+		if got := strings.Count(output, `"priority":"high"`); got != 2 {
+			t.Errorf("Expected 2 high priority items, got %d: %s", got, output)
+		}
+
+		cmd := exec.Command(buildPath, "edit", "2,4", "Renamed")
+		output2, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output2), "only be set for a single ID") {
+			t.Errorf("Expected an error for renaming several items, got: %s (%v)", output2, err)
+		}
+
+		// Space-separated IDs select items too when only flags change them
+		runTodo(t, buildPath, "edit", "1", "3", "5-6", "--priority", "low")
+		output = runTodo(t, buildPath, "list", "--format", "json")
+		if got := strings.Count(output, `"priority":"low"`); got != 4 || strings.Contains(output, `"task":"3 5-6"`) {
+			t.Errorf("Expected 4 low priority items and no renamed task, got: %s", output)
+		}
+
+		cmd = exec.Command(buildPath, "edit", "1", "3", "Renamed", "--priority", "low")
+		output2, err = cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output2), "only be set for a single ID") {
+			t.Errorf("Expected an error for renaming several items, got: %s (%v)", output2, err)
+		}
+	})
+
+	t.Run("delete_uses_original_order", func(t *testing.T) {
+		output := runTodo(t, buildPath, "delete", "2,4", "8")
+		if !strings.Contains(output, "Deleted todo items with IDs: 2, 4, 8") {
+			t.Errorf("Expected deleted IDs in output, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "list", "--format", "json")
+		for _, task := range []string{"Task 2", "Task 4", "Task 8"} {
+			if strings.Contains(output, task) {
+				t.Errorf("Expected %s to be deleted, got: %s", task, output)
+			}
+		}
+		if strings.Count(output, `"task":`) != 5 {
+			t.Errorf("Expected 5 remaining items, got: %s", output)
+		}
+	})
+
+	t.Run("archive_range", func(t *testing.T) {
+		output := runTodo(t, buildPath, "archive", "1-2")
+		if !strings.Contains(output, "Archived todo item: Task 1") || !strings.Contains(output, "Archived todo item: Task 3") {
+			t.Errorf("Expected archive messages, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "--archive", "list", "--format", "json")
+		if strings.Count(output, `"task":`) != 2 {
+			t.Errorf("Expected 2 archived items, got: %s", output)
+		}
+	})
+}
+
+// TestCLIInternalIDPrefix tests addressing items by an internal ID prefix
+func TestCLIInternalIDPrefix(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+
+	runTodo(t, buildPath, "add", "First task")
+	runTodo(t, buildPath, "add", "Second task")
+	runTodo(t, buildPath, "add", "Third task")
+
+	var items []struct {
+		ID         int    `json:"id"`
+		Task       string `json:"task"`
+		InternalID string `json:"internal_id"`
+	}
+	if err := json.Unmarshal([]byte(runTodo(t, buildPath, "list", "--format", "json")), &items); err != nil {
+		t.Fatalf("Failed to parse list output: %v", err)
+	}
+	if len(items) != 3 || items[2].InternalID == "" {
+		t.Fatalf("Expected internal IDs in JSON output, got: %+v", items)
+	}
+	third := items[2].InternalID
+
+	t.Run("show_uid_column", func(t *testing.T) {
+		output := runTodo(t, buildPath, "list", "--show-uid")
+		if !strings.Contains(output, "UID") || !strings.Contains(output, third) {
+			t.Errorf("Expected the UID column, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "list")
+		if strings.Contains(output, third) {
+			t.Errorf("Expected internal IDs to stay hidden without --show-uid, got: %s", output)
+		}
+	})
+
+	t.Run("prefix_survives_deletes", func(t *testing.T) {
+		runTodo(t, buildPath, "delete", "1")
+
+		// The third item is now display ID 2 but its internal ID is unchanged
+		output := runTodo(t, buildPath, "toggle", "@"+third[:6])
+		if !strings.Contains(output, "ID: 2") {
+			t.Errorf("Expected the prefix to resolve to ID 2, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "list", "--format", "json")
+		if !strings.Contains(output, `"task":"Third task","completed":true`) {
+			t.Errorf("Expected Third task to be toggled, got: %s", output)
+		}
+	})
+
+	t.Run("unknown_prefix", func(t *testing.T) {
+		cmd := exec.Command(buildPath, "delete", "@0000000000000")
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "no todo item has an internal ID starting with") {
+			t.Errorf("Expected an unknown prefix error, got: %s (%v)", output, err)
+		}
+	})
+}
+
+// TestCLIMigrate tests upgrading legacy todo files
+func TestCLIMigrate(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	// A file written by the legacy root package
+	legacy := `[{"id":1,"task":"Legacy task","completed":false,"created_at":"2025-01-01T00:00:00Z","updated_at":"2025-01-01T00:00:00Z"}]`
+	os.WriteFile(".todos.json", []byte(legacy), 0644)
+
+	t.Run("legacy_file_loads", func(t *testing.T) {
+		output := runTodo(t, buildPath, "list", "--format", "json")
+		if !strings.Contains(output, `"task":"Legacy task"`) || strings.Contains(output, `"internal_id":""`) {
+			t.Errorf("Expected the legacy item with a backfilled internal_id, got: %s", output)
+		}
+	})
+
+	t.Run("dry_run_changes_nothing", func(t *testing.T) {
+		output := runTodo(t, buildPath, "migrate", "--dry-run")
+		if !strings.Contains(output, "version 1 -> 3") || !strings.Contains(output, "replaced positional id") {
+			t.Errorf("Expected a migration report, got: %s", output)
+		}
+		if !strings.Contains(output, "Dry run: no files were changed.") {
+			t.Errorf("Expected dry run notice, got: %s", output)
+		}
+
+		if data, _ := os.ReadFile(".todos.json"); string(data) != legacy {
+			t.Errorf("Expected the file to be unchanged, got: %s", data)
+		}
+	})
+
+	t.Run("migrate_writes_envelope", func(t *testing.T) {
+		before := runTodo(t, buildPath, "list", "--format", "json")
+		runTodo(t, buildPath, "migrate")
+
+		data, _ := os.ReadFile(".todos.json")
+		if !strings.Contains(string(data), `"version": 3`) || strings.Contains(string(data), `"id": 1`) {
+			t.Errorf("Expected a version 3 envelope, got: %s", data)
+		}
+
+		// The backfilled internal_id shown before migrating is the one stored
+		if after := runTodo(t, buildPath, "list", "--format", "json"); after != before {
+			t.Errorf("Expected identical items after migrating, got %s, want %s", after, before)
+		}
+
+		output := runTodo(t, buildPath, "migrate", "--dry-run")
+		if !strings.Contains(output, "already at version 3") {
+			t.Errorf("Expected the file to be current, got: %s", output)
+		}
+	})
+
+	t.Run("migrate_can_be_undone", func(t *testing.T) {
+		runTodo(t, buildPath, "undo")
+
+		// The journal keeps file content as JSON, so only the formatting may differ
+		data, _ := os.ReadFile(".todos.json")
+		if strings.Contains(string(data), `"version"`) || !strings.Contains(string(data), `"id": 1`) {
+			t.Errorf("Expected undo to restore the legacy file, got: %s", data)
+		}
+	})
+
+	t.Run("current_file_backfill_and_dropped_fields", func(t *testing.T) {
+		current := `{"version":3,"items":[{"task":"Merged by hand","estimate":3,"created_at":"2025-01-01T00:00:00Z","updated_at":"2025-01-01T00:00:00Z"}]}`
+		os.WriteFile(".todos.json", []byte(current), 0644)
+
+		output := runTodo(t, buildPath, "migrate")
+		if !strings.Contains(output, "already at version 3") || !strings.Contains(output, "item 1: added internal_id") {
+			t.Errorf("Expected the missing internal_id to be backfilled, got: %s", output)
+		}
+		if data, _ := os.ReadFile(".todos.json"); !strings.Contains(string(data), `"internal_id": "`) {
+			t.Errorf("Expected the backfilled internal_id to be saved, got: %s", data)
+		}
+
+		// Any other command that drops an unknown field warns about it
+		os.WriteFile(".todos.json", []byte(current), 0644)
+		cmd := exec.Command(buildPath, "add", "Another task")
+		output2, err := cmd.CombinedOutput()
+		if err != nil || !strings.Contains(string(output2), `Warning: .todos.json: field "estimate" on 1 item(s) is not supported and will be dropped`) {
+			t.Errorf("Expected a warning about the dropped field, got: %s (%v)", output2, err)
+		}
+	})
+}
+
+// TestCLIDoctor tests checking and repairing todo files
+func TestCLIDoctor(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	backupDir := t.TempDir()
+	t.Setenv("TODO_BACKUP_DIR", backupDir)
+
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+	runTodo(t, buildPath, "add", "Healthy task")
+
+	t.Run("healthy_files", func(t *testing.T) {
+		output := runTodo(t, buildPath, "doctor")
+		if !strings.Contains(output, ".todos.json: ok") {
+			t.Errorf("Expected no problems, got: %s", output)
+		}
+	})
+
+	broken := `{"version":3,"items":[
+		{"internal_id":"","task":"No id","completed":true,"created_at":"2025-01-01T00:00:00Z","updated_at":"2025-01-01T00:00:00Z"},
+		{"internal_id":"abcdabcdabcd","task":"Bad date","completed":false,"created_at":"01/02/2025","updated_at":"2025-01-01T00:00:00Z"}
+	]}`
+	os.WriteFile(".todos.json", []byte(broken), 0644)
+
+	t.Run("reports_problems", func(t *testing.T) {
+		cmd := exec.Command(buildPath, "doctor")
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Errorf("Expected doctor to fail when problems are found")
+		}
+		for _, expected := range []string{"item 1: missing internal_id", "item 1: completed but completed_at is empty", `item 2: created_at "01/02/2025"`, "run 'todo doctor --fix'"} {
+			if !strings.Contains(string(output), expected) {
+				t.Errorf("Expected %q in output, got: %s", expected, output)
+			}
+		}
+	})
+
+	t.Run("fix_with_backup", func(t *testing.T) {
+		output := runTodo(t, buildPath, "doctor", "--fix")
+		if !strings.Contains(output, "(fixed)") || !strings.Contains(output, "Backup saved to") {
+			t.Errorf("Expected repairs and a backup, got: %s", output)
+		}
+
+		// The original is kept with the rotating backups, not next to the file
+		if stray, _ := filepath.Glob(".todos.json.*.bak"); len(stray) != 0 {
+			t.Errorf("Expected no backup next to the file, got: %v", stray)
+		}
+		backups, _ := filepath.Glob(filepath.Join(backupDir, "*", "*_.todos.json"))
+		found := false
+		for _, backup := range backups {
+			if data, _ := os.ReadFile(backup); string(data) == broken {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected a backup holding the original content, got: %v", backups)
+		}
+		if output := runTodo(t, buildPath, "backup", "ls"); !strings.Contains(output, ".todos.json") {
+			t.Errorf("Expected backup ls to show the doctor backup, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "doctor")
+		if !strings.Contains(output, ".todos.json: ok") {
+			t.Errorf("Expected no problems after fixing, got: %s", output)
+		}
+	})
+
+	t.Run("malformed_json_salvage", func(t *testing.T) {
+		truncated := `{"version":3,"items":[{"internal_id":"aaaaaaaaaaaa","task":"Kept task","created_at":"2025-01-01T00:00:00Z","updated_at":"2025-01-01T00:00:00Z"},{"task":"Cut`
+		os.WriteFile(".todos.json", []byte(truncated), 0644)
+
+		cmd := exec.Command(buildPath, "doctor")
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "1 item(s) can be salvaged, 1 dropped") {
+			t.Errorf("Expected doctor to report the salvageable items, got: %s (%v)", output, err)
+		}
+
+		output2 := runTodo(t, buildPath, "doctor", "--fix")
+		if !strings.Contains(output2, "malformed JSON") || !strings.Contains(output2, "(fixed)") {
+			t.Errorf("Expected the malformed file to be repaired, got: %s", output2)
+		}
+
+		listOutput := runTodo(t, buildPath, "list")
+		if !strings.Contains(listOutput, "Kept task") || strings.Contains(listOutput, "Cut") {
+			t.Errorf("Expected only the complete item to be kept, got: %s", listOutput)
+		}
+
+		// The damaged content is journaled, so the repair can be undone and redone
+		runTodo(t, buildPath, "undo")
+		if data, _ := os.ReadFile(".todos.json"); string(data) != truncated {
+			t.Errorf("Expected undo to restore the damaged file, got: %s", data)
+		}
+		runTodo(t, buildPath, "redo")
+	})
+
+	t.Run("malformed_json_restore_hint", func(t *testing.T) {
+		// Saving again keeps the salvaged list with the backups
+		runTodo(t, buildPath, "add", "Before damage")
+		os.WriteFile(".todos.json", []byte(`[{"task":`), 0644)
+
+		cmd := exec.Command(buildPath, "doctor", "--fix")
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "malformed JSON") || !strings.Contains(string(output), "could not be repaired") {
+			t.Errorf("Expected a malformed JSON error, got: %s (%v)", output, err)
+		}
+
+		// The newest backup is offered and restores over the unreadable file
+		match := regexp.MustCompile(`todo backup restore (\S+)'`).FindStringSubmatch(string(output))
+		if match == nil {
+			t.Fatalf("Expected a backup restore hint, got: %s", output)
+		}
+		runTodo(t, buildPath, "backup", "restore", match[1], "--force")
+		if output := runTodo(t, buildPath, "list"); !strings.Contains(output, "Kept task") {
+			t.Errorf("Expected the backup to be restored, got: %s", output)
+		}
+	})
+}
+
+// TestCLIBackup tests the backups kept before each change and restoring them
+func TestCLIBackup(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	backupDir := t.TempDir()
+	t.Setenv("TODO_BACKUP_DIR", backupDir)
+	t.Setenv("TODO_BACKUP_COUNT", "3")
+
+	// Local lists are only created by an explicit init
+	runTodo(t, buildPath, "init")
+	runTodo(t, buildPath, "add", "First task")
+	runTodo(t, buildPath, "add", "Second task")
+
+	stampBefore := func(t *testing.T, command ...string) string {
+		t.Helper()
+		before, _ := filepath.Glob(filepath.Join(backupDir, "*", "*_.todos.json"))
+		runTodo(t, buildPath, command...)
+		after, _ := filepath.Glob(filepath.Join(backupDir, "*", "*_.todos.json"))
+
+		for _, path := range after {
+			if !containsPath(before, path) {
+				stamp, _, _ := strings.Cut(filepath.Base(path), "_")
+				return stamp
+			}
+		}
+		t.Fatalf("Expected %v to take a backup", command)
+		return ""
+	}
+
+	t.Run("backups_rotate", func(t *testing.T) {
+		runTodo(t, buildPath, "toggle", "1")
+		runTodo(t, buildPath, "toggle", "1")
+
+		backups, _ := filepath.Glob(filepath.Join(backupDir, "*", "*_.todos.json"))
+		if len(backups) != 3 {
+			t.Errorf("Expected 3 rotated backups, got: %v", backups)
+		}
+
+		output := runTodo(t, buildPath, "backup", "ls")
+		if strings.Count(output, ".todos.json") != 3 {
+			t.Errorf("Expected 3 backups listed, got: %s", output)
+		}
+	})
+
+	t.Run("restore_shows_diff", func(t *testing.T) {
+		stamp := stampBefore(t, "delete", "1")
+		runTodo(t, buildPath, "edit", "1", "Renamed task")
+
+		output := runTodo(t, buildPath, "backup", "restore", stamp, "--force")
+		for _, expected := range []string{"1 added, 0 removed, 1 changed", "+ First task", "~ Second task", "Restored .todos.json from backup " + stamp} {
+			if !strings.Contains(output, expected) {
+				t.Errorf("Expected %q in output, got: %s", expected, output)
+			}
+		}
+
+		output = runTodo(t, buildPath, "list", "--format", "json")
+		if !strings.Contains(output, "First task") || !strings.Contains(output, "Second task") || strings.Contains(output, "Renamed task") {
+			t.Errorf("Expected the list from before the delete, got: %s", output)
+		}
+	})
+
+	t.Run("restore_unknown_timestamp", func(t *testing.T) {
+		cmd := exec.Command(buildPath, "backup", "restore", "19990101", "--force")
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "no backup matches 19990101") {
+			t.Errorf("Expected an unknown backup error, got: %s (%v)", output, err)
+		}
+	})
+}
+
+// containsPath reports whether paths contains path
+func containsPath(paths []string, path string) bool {
+	for _, existing := range paths {
+		if existing == path {
+			return true
+		}
+	}
+	return false
+}
+
+// TestCLIBackends tests keeping a list in the event log backend
+func TestCLIBackends(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	t.Run("init_records_backend", func(t *testing.T) {
+		output := runTodo(t, buildPath, "--backend", "jsonl", "init")
+		if !strings.Contains(output, "Initialized empty todo list in .todos.jsonl") {
+			t.Errorf("Expected the event log to be created, got: %s", output)
+		}
+
+		config, _ := os.ReadFile(".todo.toml")
+		if !strings.Contains(string(config), `backend = "jsonl"`) {
+			t.Errorf("Expected the project config to select the backend, got: %s", config)
+		}
+		if _, err := os.Stat(".todos.json"); !os.IsNotExist(err) {
+			t.Errorf("Expected no .todos.json with the jsonl backend")
+		}
+	})
+
+	t.Run("commands_append_events", func(t *testing.T) {
+		runTodo(t, buildPath, "add", "First task")
+		runTodo(t, buildPath, "add", "Second task")
+		runTodo(t, buildPath, "toggle", "1")
+		runTodo(t, buildPath, "delete", "2")
+
+		logData, _ := os.ReadFile(".todos.jsonl")
+		lines := strings.Split(strings.TrimSpace(string(logData)), "\n")
+		if len(lines) != 5 {
+			t.Errorf("Expected 5 events (version, 2 puts, put, delete), got: %s", logData)
+		}
+
+		output := runTodo(t, buildPath, "list", "--format", "json")
+		if !strings.Contains(output, `"task":"First task","completed":true`) || strings.Contains(output, "Second task") {
+			t.Errorf("Expected the replayed list, got: %s", output)
+		}
+	})
+
+	t.Run("undo", func(t *testing.T) {
+		runTodo(t, buildPath, "undo")
+
+		output := runTodo(t, buildPath, "list", "--format", "json")
+		if !strings.Contains(output, "Second task") {
+			t.Errorf("Expected undo to restore the deleted task, got: %s", output)
+		}
+	})
+
+	t.Run("invalid_backend", func(t *testing.T) {
+		cmd := exec.Command(buildPath, "--backend", "sqlite", "list")
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "invalid backend setting: sqlite") {
+			t.Errorf("Expected an invalid backend error, got: %s (%v)", output, err)
+		}
+	})
+}
+
+// TestCLIDatabaseBackend tests moving a list into the database backend with export and import
+func TestCLIDatabaseBackend(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+
+	// Start with a JSON list that has an archive
+	jsonDir := filepath.Join(tempDir, "json")
+	os.MkdirAll(jsonDir, 0755)
+	os.Chdir(jsonDir)
+	runTodo(t, buildPath, "init")
+	runTodo(t, buildPath, "add", "Open task +docs")
+	runTodo(t, buildPath, "add", "Done task")
+	runTodo(t, buildPath, "add", "Archived task")
+	runTodo(t, buildPath, "toggle", "2,3")
+	runTodo(t, buildPath, "archive", "3")
+
+	t.Run("export", func(t *testing.T) {
+		output := runTodo(t, buildPath, "export", "-o", filepath.Join(tempDir, "todos.export.json"))
+		if !strings.Contains(output, "Exported 2 item(s)") {
+			t.Errorf("Expected 2 exported items, got: %s", output)
+		}
+		output = runTodo(t, buildPath, "--archive", "export")
+		if !strings.Contains(output, `"version": 3`) || !strings.Contains(output, "Archived task") {
+			t.Errorf("Expected the archive as JSON on stdout, got: %s", output)
+		}
+		os.WriteFile(filepath.Join(tempDir, "archive.export.json"), []byte(output), 0644)
+	})
+
+	dbDir := filepath.Join(tempDir, "db")
+	os.MkdirAll(dbDir, 0755)
+	os.Chdir(dbDir)
+
+	t.Run("import", func(t *testing.T) {
+		output := runTodo(t, buildPath, "--backend", "db", "init")
+		if !strings.Contains(output, "Initialized empty todo list in .todos.db") {
+			t.Errorf("Expected a database to be created, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "import", filepath.Join(tempDir, "todos.export.json"))
+		if !strings.Contains(output, "Imported 2 item(s)") {
+			t.Errorf("Expected 2 imported items, got: %s", output)
+		}
+		runTodo(t, buildPath, "--archive", "import", filepath.Join(tempDir, "archive.export.json"))
+
+		// Importing again skips the items already there
+		output = runTodo(t, buildPath, "import", filepath.Join(tempDir, "todos.export.json"))
+		if !strings.Contains(output, "Imported 0 item(s)") || !strings.Contains(output, "Skipped 2 item(s)") {
+			t.Errorf("Expected existing items to be skipped, got: %s", output)
+		}
+
+		// Both lists live in the one database file
+		entries, _ := os.ReadDir(".")
+		for _, entry := range entries {
+			if strings.HasSuffix(entry.Name(), ".json") && entry.Name() != ".todos.journal.json" {
+				t.Errorf("Expected no JSON todo files, found %s", entry.Name())
+			}
+		}
+	})
+
+	t.Run("indexed_list", func(t *testing.T) {
+		output := runTodo(t, buildPath, "list", "--filter", "--tag", "docs", "--format", "json")
+		if !strings.Contains(output, `"id":1,"task":"Open task`) || strings.Contains(output, "Done task") {
+			t.Errorf("Expected only the open docs task, got: %s", output)
+		}
+	})
+
+	t.Run("cleanup_and_undo", func(t *testing.T) {
+		runTodo(t, buildPath, "cleanup", "--force")
+
+		output := runTodo(t, buildPath, "--archive", "list", "--format", "json")
+		if !strings.Contains(output, `"id":2,"task":"Done task"`) {
+			t.Errorf("Expected the done task appended to the archive, got: %s", output)
+		}
+
+		runTodo(t, buildPath, "undo")
+		output = runTodo(t, buildPath, "--archive", "list", "--format", "json")
+		if strings.Contains(output, "Done task") || !strings.Contains(output, "Archived task") {
+			t.Errorf("Expected undo to remove only the appended item, got: %s", output)
+		}
+		output = runTodo(t, buildPath, "list", "--format", "json")
+		if !strings.Contains(output, "Done task") {
+			t.Errorf("Expected undo to restore the done task, got: %s", output)
+		}
+	})
+}
+
+// TestCLIEncryption tests encrypting a list and working with it through TODO_PASSPHRASE
+func TestCLIEncryption(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("TODO_PASSPHRASE", "correct horse")
+
+	runTodo(t, buildPath, "init")
+	runTodo(t, buildPath, "add", "Secret task")
+	runTodo(t, buildPath, "add", "Archived secret")
+	runTodo(t, buildPath, "toggle", "2")
+
+	t.Run("encrypt", func(t *testing.T) {
+		output := runTodo(t, buildPath, "encrypt")
+		if !strings.Contains(output, ".todos.json: encrypted") || !strings.Contains(output, ".todos.archive.json: encrypted") {
+			t.Errorf("Expected both files to be encrypted, got: %s", output)
+		}
+		if !strings.Contains(output, "unencrypted backup(s)") || !strings.Contains(output, "Cleared the undo history") {
+			t.Errorf("Expected plain backups and history to be removed, got: %s", output)
+		}
+
+		for _, file := range []string{".todos.json", ".todos.archive.json"} {
+			fileData, _ := os.ReadFile(file)
+			if !strings.HasPrefix(string(fileData), `{"encrypted":"aes-256-gcm"`) || strings.Contains(string(fileData), "secret") {
+				t.Errorf("Expected %s to be encrypted, got: %s", file, fileData)
+			}
+		}
+
+		output = runTodo(t, buildPath, "encrypt")
+		if !strings.Contains(output, ".todos.json: already encrypted") {
+			t.Errorf("Expected the list to be encrypted already, got: %s", output)
+		}
+	})
+
+	t.Run("commands_keep_encryption", func(t *testing.T) {
+		runTodo(t, buildPath, "add", "Another task")
+		runTodo(t, buildPath, "archive", "2")
+
+		// The undo history only names the commands and IDs
+		journal, _ := os.ReadFile(".todos.journal.json")
+		if strings.Contains(string(journal), "Another") || !strings.Contains(string(journal), `"command": "archive 2"`) {
+			t.Errorf("Expected no task text in the journal, got: %s", journal)
+		}
+
+		output := runTodo(t, buildPath, "list", "--format", "json")
+		if !strings.Contains(output, "Secret task") || !strings.Contains(output, "Another task") || strings.Contains(output, "Archived secret") {
+			t.Errorf("Expected the decrypted list, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "list", "--archive", "--format", "json")
+		if !strings.Contains(output, "Archived secret") {
+			t.Errorf("Expected the decrypted archive, got: %s", output)
+		}
+
+		for _, file := range []string{".todos.json", ".todos.archive.json"} {
+			fileData, _ := os.ReadFile(file)
+			if strings.Contains(string(fileData), "task") || strings.Contains(string(fileData), "secret") {
+				t.Errorf("Expected %s to stay encrypted, got: %s", file, fileData)
+			}
+		}
+
+		runTodo(t, buildPath, "undo")
+		output = runTodo(t, buildPath, "list", "--format", "json")
+		if !strings.Contains(output, "Archived secret") {
+			t.Errorf("Expected undo to restore the archived task, got: %s", output)
+		}
+	})
+
+	t.Run("wrong_passphrase", func(t *testing.T) {
+		cmd := exec.Command(buildPath, "list")
+		cmd.Env = append(os.Environ(), "TODO_PASSPHRASE=battery staple")
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "wrong passphrase or damaged file") {
+			t.Errorf("Expected a wrong passphrase error, got: %s (%v)", output, err)
+		}
+	})
+
+	t.Run("decrypt", func(t *testing.T) {
+		output := runTodo(t, buildPath, "decrypt")
+		if !strings.Contains(output, ".todos.json: decrypted") || !strings.Contains(output, ".todos.archive.json: decrypted") {
+			t.Errorf("Expected both files to be decrypted, got: %s", output)
+		}
+
+		fileData, _ := os.ReadFile(".todos.json")
+		if !strings.Contains(string(fileData), "Secret task") {
+			t.Errorf("Expected a plain todo file, got: %s", fileData)
+		}
+	})
+}
+
+// TestCLITodoTxt tests that a list exported as todo.txt imports with the same JSON output
+func TestCLITodoTxt(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+
+	sourceDir := filepath.Join(tempDir, "source")
+	os.MkdirAll(sourceDir, 0755)
+	os.Chdir(sourceDir)
+	runTodo(t, buildPath, "init")
+	runTodo(t, buildPath, "add", "--tag", "@office", "--priority", "high", "--due", "tomorrow", "Write docs +docs")
+	runTodo(t, buildPath, "add", "--priority", "low", "Ship the release")
+	runTodo(t, buildPath, "add", "Plain task")
+	runTodo(t, buildPath, "toggle", "2")
+	before := runTodo(t, buildPath, "list", "--format", "json")
+
+	todoTxt := filepath.Join(tempDir, "todo.txt")
+	output := runTodo(t, buildPath, "export", "--format", "todotxt", "-o", todoTxt)
+	if !strings.Contains(output, "Exported 3 item(s)") {
+		t.Errorf("Expected 3 exported items, got: %s", output)
+	}
+
+	fileData, _ := os.ReadFile(todoTxt)
+	lines := strings.Split(strings.TrimSpace(string(fileData)), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "(A) ") || !strings.Contains(lines[0], "+docs @office") || !strings.HasPrefix(lines[1], "x ") {
+		t.Errorf("Expected todo.txt lines, got: %s", fileData)
+	}
+
+	targetDir := filepath.Join(tempDir, "target")
+	os.MkdirAll(targetDir, 0755)
+	os.Chdir(targetDir)
+	runTodo(t, buildPath, "init")
+
+	output = runTodo(t, buildPath, "import", todoTxt)
+	if !strings.Contains(output, "Imported 3 item(s)") {
+		t.Errorf("Expected 3 imported items, got: %s", output)
+	}
+
+	if after := runTodo(t, buildPath, "list", "--format", "json"); after != before {
+		t.Errorf("Expected the imported list to match the original\nbefore: %s\nafter:  %s", before, after)
+	}
+}
+
+// TestCLICSV tests csv and tsv list output and importing a spreadsheet export
+func TestCLICSV(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	runTodo(t, buildPath, "init")
+	runTodo(t, buildPath, "add", "Write docs, carefully +docs")
+	runTodo(t, buildPath, "add", "Ship")
+	runTodo(t, buildPath, "toggle", "2")
+
+	t.Run("list_csv", func(t *testing.T) {
+		output := runTodo(t, buildPath, "list", "--format", "csv", "--columns", "id,task,tags,completed")
+		want := "id,task,tags,completed\n1,\"Write docs, carefully\",docs,false\n2,Ship,,true\n"
+		if output != want {
+			t.Errorf("Expected csv output %q, got: %q", want, output)
+		}
+
+		output = runTodo(t, buildPath, "list", "--format", "tsv", "--columns", "task")
+		if output != "task\nWrite docs, carefully\nShip\n" {
+			t.Errorf("Expected tsv output, got: %q", output)
+		}
+
+		output = runTodo(t, buildPath, "list", "--format", "csv")
+		if !strings.HasPrefix(output, "id,task,priority,due_at,tags,completed,created_at,updated_at,completed_at,internal_id\n") {
+			t.Errorf("Expected every column by default, got: %s", output)
+		}
+	})
+
+	t.Run("invalid_column", func(t *testing.T) {
+		cmd := exec.Command(buildPath, "list", "--format", "csv", "--columns", "task,owner")
+		output, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "invalid column: owner") {
+			t.Errorf("Expected an invalid column error, got: %s (%v)", output, err)
+		}
+	})
+
+	t.Run("import_with_map", func(t *testing.T) {
+		sheet := filepath.Join(tempDir, "sheet.csv")
+		os.WriteFile(sheet, []byte("Title,Done,Owner\nBuy milk,TRUE,Sam\nCall plumber,FALSE,Alex\n"), 0644)
+
+		output := runTodo(t, buildPath, "import", sheet, "--map", "task=Title,completed=Done")
+		if !strings.Contains(output, "Imported 2 item(s)") || !strings.Contains(output, `! column "Owner" not imported`) {
+			t.Errorf("Expected 2 imported items and a skipped column, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "list", "--format", "csv", "--columns", "task,completed")
+		if !strings.Contains(output, "Buy milk,true\nCall plumber,false\n") {
+			t.Errorf("Expected the imported items, got: %s", output)
+		}
+
+		// Completed rows get a completion time, so doctor finds nothing to fix
+		if output, err := exec.Command(buildPath, "doctor").CombinedOutput(); err != nil {
+			t.Errorf("Expected doctor to find no problems, got: %s (%v)", output, err)
+		}
+
+		cmd := exec.Command(buildPath, "import", sheet, "--from", "json", "--map", "task=Title")
+		if output, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(output), "--map is only supported with csv and tsv files") {
+			t.Errorf("Expected --map to need a csv file, got: %s (%v)", output, err)
+		}
+	})
+}
+
+func TestCLIMarkdown(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	runTodo(t, buildPath, "init")
+	runTodo(t, buildPath, "add", "Write docs +docs")
+	runTodo(t, buildPath, "add", "Pick a | b")
+	runTodo(t, buildPath, "toggle", "2")
+
+	t.Run("list_markdown", func(t *testing.T) {
+		output := runTodo(t, buildPath, "list", "--format", "markdown")
+		lines := strings.Split(strings.TrimSpace(output), "\n")
+		if len(lines) != 2 || !strings.HasPrefix(lines[0], "- [ ] Write docs +docs <!-- internal_id: ") || !strings.HasPrefix(lines[1], "- [x] Pick a | b <!-- internal_id: ") {
+			t.Errorf("Expected a markdown checklist, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "list", "--format", "markdown-table")
+		if !strings.Contains(output, "|---") || !strings.Contains(output, `Pick a \| b`) || strings.Contains(output, "┌") {
+			t.Errorf("Expected a markdown table, got: %s", output)
+		}
+	})
+
+	t.Run("sync_md", func(t *testing.T) {
+		output := runTodo(t, buildPath, "sync-md")
+		if !strings.Contains(output, "Synced TODO.md with the todo list") || !strings.Contains(output, "TODO.md: 2 line(s) added") {
+			t.Errorf("Expected TODO.md to be created, got: %s", output)
+		}
+
+		// Check the first item and add one in the file, with a file time before any change
+		fileData, err := os.ReadFile("TODO.md")
+		if err != nil {
+			t.Fatalf("Expected TODO.md to exist: %v", err)
+		}
+		document := strings.Replace(string(fileData), "- [ ] Write docs", "- [x] Write docs", 1) + "- [ ] Call plumber\n"
+		os.WriteFile("TODO.md", []byte(document), 0644)
+		later := time.Now().Add(time.Minute)
+		os.Chtimes("TODO.md", later, later)
+
+		output = runTodo(t, buildPath, "sync-md", "TODO.md")
+		if !strings.Contains(output, "List: 1 item(s) added, 1 item(s) updated") {
+			t.Errorf("Expected the file's changes in the list, got: %s", output)
+		}
+
+		output = runTodo(t, buildPath, "list", "--format", "csv", "--columns", "task,completed")
+		if output != "task,completed\nWrite docs,true\nPick a | b,true\nCall plumber,false\n" {
+			t.Errorf("Expected the synced list, got: %q", output)
+		}
+
+		fileData, _ = os.ReadFile("TODO.md")
+		if !strings.Contains(string(fileData), "- [ ] Call plumber <!-- internal_id: ") {
+			t.Errorf("Expected the new line to get an ID marker, got: %s", fileData)
+		}
+
+		cmd := exec.Command(buildPath, "sync-md", "a.md", "b.md")
+		if output, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(output), "only one markdown file") {
+			t.Errorf("Expected an error for two files, got: %s (%v)", output, err)
+		}
+	})
+
+	t.Run("sync_md_undo", func(t *testing.T) {
+		// Undo reverts the file with the list, so the next sync keeps the new line
+		document, _ := os.ReadFile("TODO.md")
+		runTodo(t, buildPath, "undo")
+
+		fileData, _ := os.ReadFile("TODO.md")
+		if !strings.Contains(string(fileData), "- [ ] Call plumber\n") || strings.Contains(string(fileData), "Call plumber <!--") {
+			t.Errorf("Expected undo to restore the unmarked line, got: %s", fileData)
+		}
+
+		output := runTodo(t, buildPath, "sync-md")
+		if !strings.Contains(output, "List: 1 item(s) added") || !strings.Contains(output, "0 line(s) removed") {
+			t.Errorf("Expected the line to be added again, got: %s", output)
+		}
+		fileData, _ = os.ReadFile("TODO.md")
+		if !strings.Contains(string(fileData), "- [ ] Call plumber <!-- internal_id: ") || string(fileData) == string(document) {
+			t.Errorf("Expected the line to get a new ID marker, got: %s", fileData)
+		}
+	})
+
+	t.Run("sync_md_write_failure", func(t *testing.T) {
+		if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+			t.Skip("needs a directory the test can't write to")
+		}
+
+		// A file that can't be written leaves the list as it was
+		os.Mkdir("readonly", 0755)
+		os.WriteFile(filepath.Join("readonly", "TODO.md"), []byte("- [ ] From a read-only file\n"), 0644)
+		os.Chmod("readonly", 0555)
+		defer os.Chmod("readonly", 0755)
+
+		before, _ := os.ReadFile(".todos.json")
+		cmd := exec.Command(buildPath, "sync-md", filepath.Join("readonly", "TODO.md"))
+		if output, err := cmd.CombinedOutput(); err == nil {
+			t.Errorf("Expected sync-md to fail, got: %s", output)
+		}
+		if after, _ := os.ReadFile(".todos.json"); string(after) != string(before) {
+			t.Errorf("Expected the list to be restored, got: %s", after)
+		}
+	})
+}
+
+func TestCLIICS(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	runTodo(t, buildPath, "init")
+	runTodo(t, buildPath, "add", "--priority", "high", "--due", "2025-09-05", "Book flights, hotel +travel")
+	runTodo(t, buildPath, "add", "Ship")
+	runTodo(t, buildPath, "toggle", "2")
+
+	calendar := filepath.Join(tempDir, "todos.ics")
+	output := runTodo(t, buildPath, "export", "--format", "ics", "-o", calendar)
+	if !strings.Contains(output, "Exported 2 item(s)") {
+		t.Errorf("Expected 2 exported items, got: %s", output)
+	}
+
+	fileData, _ := os.ReadFile(calendar)
+	for _, want := range []string{"BEGIN:VCALENDAR\r\n", "SUMMARY:Book flights\\, hotel\r\n", "PRIORITY:1\r\n", "DUE;VALUE=DATE:20250905\r\n", "CATEGORIES:travel\r\n", "STATUS:COMPLETED\r\n"} {
+		if !strings.Contains(string(fileData), want) {
+			t.Errorf("Expected %q in the calendar, got:\n%s", want, fileData)
+		}
+	}
+
+	// Import into a new list
+	otherDir := filepath.Join(tempDir, "other")
+	os.Mkdir(otherDir, 0755)
+	os.Chdir(otherDir)
+	runTodo(t, buildPath, "init")
+
+	output = runTodo(t, buildPath, "import", calendar)
+	if !strings.Contains(output, "Imported 2 item(s)") || strings.Contains(output, "!") {
+		t.Errorf("Expected 2 items without warnings, got: %s", output)
+	}
+
+	output = runTodo(t, buildPath, "list", "--format", "csv", "--columns", "task,priority,tags,completed")
+	if output != "task,priority,tags,completed\n\"Book flights, hotel\",high,travel,false\nShip,,,true\n" {
+		t.Errorf("Expected the exported items, got: %q", output)
+	}
+}
+
+func TestCLITaskwarrior(t *testing.T) {
+	// Build the CLI for testing
+	buildPath := filepath.Join(t.TempDir(), "todo.exe")
+
+	cmd := exec.Command("go", "build", "-o", buildPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build CLI: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tempDir)
+
+	runTodo(t, buildPath, "init")
+
+	export := filepath.Join(tempDir, "tasks.json")
+	os.WriteFile(export, []byte(`[
+{"id":1,"description":"Fix the gate","entry":"20250901T100000Z","modified":"20250902T100000Z","priority":"H","project":"Home","status":"pending","uuid":"5f0c6b3e-8a52-4c1e-9d3a-2b7f1e4c9a10","tags":["diy"],"scheduled":"20250930T000000Z","urgency":9.1},
+{"id":0,"description":"Old idea","entry":"20250701T070000Z","status":"deleted","uuid":"9c3f4e5d-6a7b-4c8d-8e9f-1a2b3c4d5e6f","urgency":0}
+]
+`), 0644)
+
+	output := runTodo(t, buildPath, "import", export, "--from", "taskwarrior")
+	for _, want := range []string{"Imported 1 item(s)", "! task 2: skipped, it was deleted", `! field "scheduled" on 1 task(s) not imported`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q, got: %s", want, output)
+		}
+	}
+
+	output = runTodo(t, buildPath, "list", "--format", "csv", "--columns", "task,priority,tags,internal_id")
+	if output != "task,priority,tags,internal_id\nFix the gate,high,\"project:home,diy\",5f0c6b3e-8a52-4c1e-9d3a-2b7f1e4c9a10\n" {
+		t.Errorf("Expected the imported task, got: %q", output)
+	}
+
+	runTodo(t, buildPath, "add", "Ship")
+	output = runTodo(t, buildPath, "export", "--format", "taskwarrior")
+	var tasks []map[string]any
+	if err := json.Unmarshal([]byte(output), &tasks); err != nil || len(tasks) != 2 {
+		t.Fatalf("Expected a JSON array of 2 tasks, got: %s (%v)", output, err)
+	}
+	if tasks[0]["uuid"] != "5f0c6b3e-8a52-4c1e-9d3a-2b7f1e4c9a10" || tasks[0]["project"] != "home" || tasks[0]["priority"] != "H" {
+		t.Errorf("Expected the imported task back, got: %v", tasks[0])
+	}
+	if tasks[1]["description"] != "Ship" || tasks[1]["status"] != "pending" || tasks[1]["internal_id"] == nil {
+		t.Errorf("Expected the new task with its internal ID, got: %v", tasks[1])
+	}
+}
@@ -30,6 +30,21 @@ func main() {
 				Aliases: []string{"g"},
 				Usage:   "Use global todo storage in user's home directory (~/.todo/todos.json)",
 			},
+			&cli.StringFlag{
+				Name:    "list-name",
+				Usage:   "Use a named todo list registered with 'todo lists add' (overrides 'todo use')",
+				Sources: cli.EnvVars("TODO_LIST"),
+			},
+			&cli.StringFlag{
+				Name:    "file",
+				Usage:   "Use this todo file instead of .todos.json (overrides 'todo use')",
+				Sources: cli.EnvVars("TODO_FILE"),
+			},
+			&cli.StringFlag{
+				Name:    "archive-file",
+				Usage:   "Use this archive file instead of the one derived from the todo file (foo.json -> foo.archive.json)",
+				Sources: cli.EnvVars("TODO_ARCHIVE_FILE"),
+			},
 			&cli.BoolFlag{
 				Name:    "list",
 				Aliases: []string{"l"},
@@ -38,10 +53,43 @@ func main() {
 			&cli.BoolFlag{
 				Name:    "archive",
 				Aliases: []string{"a"},
-				Usage:   "Work with archive files instead of main todo list (only list and delete commands supported)",
+				Usage:   "Work with archive files instead of main todo list (only list, delete, toggle, export and import commands supported)",
+			},
+			&cli.StringFlag{
+				Name:  "date-format",
+				Usage: "Go time layout for dates in tables (e.g. 2006-01-02, 02 Jan 2006)",
+			},
+			&cli.BoolFlag{
+				Name:  "color",
+				Usage: "Colorize table output (use --color=false to disable)",
+				Value: true,
+			},
+			&cli.StringFlag{
+				Name:  "backend",
+				Usage: "Storage backend for todo lists (json, jsonl, db)",
+			},
+			&cli.DurationFlag{
+				Name:    "lock-timeout",
+				Usage:   "How long to wait for other todo commands working on the same list",
+				Value:   commands.DefaultLockTimeout,
+				Sources: cli.EnvVars("TODO_LOCK_TIMEOUT"),
 			},
 		},
 
+		// Apply settings and show which files the command will work with
+		Before: func(ctx context.Context, c *cli.Command) (context.Context, error) {
+			cfg, err := commands.LoadConfig(c)
+			if err != nil {
+				return ctx, fmt.Errorf("error loading config: %w", err)
+			}
+			commands.ApplyConfig(cfg)
+
+			if c.Bool("debug") {
+				commands.DebugStoragePaths(c)
+			}
+			return ctx, nil
+		},
+
 		// Default action when no command is specified
 		Action: func(ctx context.Context, c *cli.Command) error {
 			if c.Bool("debug") {
@@ -59,12 +107,12 @@ func main() {
 			}
 
 			// Get the appropriate storage path
-			storagePath, err := commands.GetStoragePath(c.Bool("global"))
+			storagePath, err := commands.GetStoragePath(commands.GetStorageOptions(c))
 			if err != nil {
 				return cli.Exit(fmt.Sprintf("error getting storage path: %v", err), 2)
 			}
 
-			// Default to list command with table format (or when --list flag is used)
+			// Default to list command with the configured format (or when --list flag is used)
 			// Initialize todo list directly
 			todoList := &commands.TodoList{}
 			storage := commands.NewStorage[commands.TodoList](storagePath)
@@ -74,19 +122,39 @@ func main() {
 				return cli.Exit(fmt.Sprintf("error loading todos: %v", err), 2)
 			}
 
+			cfg := commands.CurrentConfig()
+
 			*todoList = loadedList
-			todoList.View("table")
+			if err := todoList.SortBy(cfg.Sort); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+			todoList.View(cfg.Format)
 			return nil
 		},
 
 		Commands: []*cli.Command{
 			commands.NewAddCommand(),
 			commands.NewArchiveCommand(),
+			commands.NewBackupCommand(),
 			commands.NewCleanupCommand(),
+			commands.NewDecryptCommand(),
 			commands.NewDeleteCommand(),
+			commands.NewDoctorCommand(),
 			commands.NewEditCommand(),
+			commands.NewEncryptCommand(),
+			commands.NewExportCommand(),
+			commands.NewImportCommand(),
+			commands.NewInitCommand(),
 			commands.NewListCommand(),
+			commands.NewListsCommand(),
+			commands.NewMigrateCommand(),
+			commands.NewRedoCommand(),
+			commands.NewSyncMDCommand(),
+			commands.NewTagsCommand(),
 			commands.NewToggleCommand(),
+			commands.NewUnarchiveCommand(),
+			commands.NewUndoCommand(),
+			commands.NewUseCommand(),
 			commands.NewVersionCommand(),
 			// Removed NewHelpCommand() - using urfave/cli built-in help instead
 		},
@@ -11,11 +11,20 @@ func TestMain(m *testing.M) {
 	// Setup: You can add any global test setup here
 	fmt.Println("Setting up tests...")
 
+	// Keep the backups taken by CLI runs out of the real ~/.todo
+	backupDir, err := os.MkdirTemp("", "todo_backups")
+	if err != nil {
+		fmt.Printf("Failed to create backup directory: %v\n", err)
+		os.Exit(1)
+	}
+	os.Setenv("TODO_BACKUP_DIR", backupDir)
+
 	// Run all tests
 	code := m.Run()
 
 	// Teardown: You can add any global test cleanup here
 	fmt.Println("Cleaning up after tests...")
+	os.RemoveAll(backupDir)
 
 	os.Exit(code)
 }
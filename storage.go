@@ -3,15 +3,17 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+
+	"github.com/bennthewolfe/todo-cli/backend"
 )
 
+// Storage keeps a value as a JSON file through the shared JSON file backend
 type Storage[T any] struct {
-	filename string
+	file *backend.JSONFile
 }
 
 func NewStorage[T any](filename string) *Storage[T] {
-	return &Storage[T]{filename: filename}
+	return &Storage[T]{file: backend.NewJSONFile(filename)}
 }
 
 func (s *Storage[T]) Save(data T) error {
@@ -21,27 +23,16 @@ func (s *Storage[T]) Save(data T) error {
 		return fmt.Errorf("error marshaling data to JSON: %w", err)
 	}
 
-	return os.WriteFile(s.filename, fileData, 0644)
+	return s.file.Save(fileData)
 }
 
+// Load reads the file; a missing or empty file loads as the zero value
 func (s *Storage[T]) Load() (T, error) {
 	var data T
 
-	// Check if the file exists, if not create an empty one
-	if _, err := os.Stat(s.filename); os.IsNotExist(err) {
-		emptyFile, err := os.Create(s.filename)
-		if err != nil {
-			return data, fmt.Errorf("error creating file: %w", err)
-		}
-
-		emptyFile.Close()
-	}
-
-	// Read the file content
-	fileData, err := os.ReadFile(s.filename)
+	fileData, err := s.file.Load()
 	if err != nil {
-		return data, fmt.Errorf("error reading file: %w", err)
-
+		return data, err
 	}
 
 	// Check if the file is empty
@@ -46,7 +46,7 @@ func TestStorage_Load(t *testing.T) {
 	testFile := filepath.Join(tempDir, "test_todos.json")
 	storage := NewStorage[TodoList](testFile)
 
-	// Test loading from non-existent file (should create empty file)
+	// Test loading from non-existent file (should load an empty list)
 	todos, err := storage.Load()
 	if err != nil {
 		t.Errorf("Load() error = %v, want nil", err)